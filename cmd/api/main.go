@@ -1,75 +1,82 @@
 package main
 
 import (
-	"log"
+	"log/slog"
+	"os"
 	
 	"context"
 
 	"crowdreview/config"
+	"crowdreview/internal/database"
 	"crowdreview/internal/handlers"
-	"crowdreview/internal/models"
+	"crowdreview/internal/mail"
 	"crowdreview/internal/repository"
 	"crowdreview/internal/services"
 	"crowdreview/internal/validation"
+	"crowdreview/pkg/logging"
 
 	"github.com/redis/go-redis/v9"
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
 )
 
 func main() {
 	cfg := config.LoadConfig()
+	logging.Setup(cfg.LogLevel)
 
-	db, err := connectDB(cfg.DatabaseURL)
+	db, err := database.Connect(cfg.DatabaseURL)
 	if err != nil {
-		log.Fatalf("failed to connect database: %v", err)
+		fatal("failed to connect database", err)
 	}
 
 	rdb, err := connectRedis(cfg.RedisURL)
 	if err != nil {
-		log.Printf("warning: redis unavailable (%v), rate limiting disabled", err)
+		slog.Warn("redis unavailable, rate limiting disabled", logging.Err(err))
 	}
 
 	repos := repository.NewRepositories(db)
-	engine := validation.NewFraudEngine()
+	engine := validation.NewFraudEngine(cfg.ReputationTiers)
 	worker := validation.NewFraudWorker(engine, repos.Validation)
-	worker.Start()
 
-	svc := services.NewServices(cfg, repos, rdb, worker)
+	mailer, err := mail.New(cfg)
+	if err != nil {
+		fatal("failed to configure mailer", err)
+	}
+
+	svc := services.NewServices(cfg, repos, rdb, worker, mailer)
+	worker.Start()
+	if err := svc.Keys.Sync(context.Background()); err != nil {
+		fatal("failed to load signing keys", err)
+	}
+	svc.Keys.Start(context.Background())
+	if cfg.SeedAchievements {
+		report, err := svc.Achievements.Seed(context.Background(), false)
+		if err != nil {
+			fatal("failed to seed achievements", err)
+		}
+		if len(report.Created) > 0 {
+			slog.Info("seeded achievements", slog.Any("created", report.Created))
+		}
+	}
+	svc.Privacy.Start(context.Background())
+	svc.Points.Start(context.Background())
+	svc.Notifications.Start(context.Background())
+	svc.Webhooks.Start(context.Background())
+	svc.Outbox.Start(context.Background())
+	svc.Activity.Start(context.Background())
 	router := handlers.SetupRouter(handlers.RouterDeps{
 		Config:   cfg,
 		Services: svc,
 		Redis:    rdb,
 	})
 
-	log.Printf("CrowdReview API listening on :%s", cfg.AppPort)
+	slog.Info("CrowdReview API listening", slog.String("port", cfg.AppPort))
 	if err := router.Run(":" + cfg.AppPort); err != nil {
-		log.Fatal(err)
+		fatal("server stopped", err)
 	}
 }
 
-func connectDB(url string) (*gorm.DB, error) {
-	db, err := gorm.Open(postgres.Open(url), &gorm.Config{})
-	if err != nil {
-		return nil, err
-	}
-	if err := db.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp";`).Error; err != nil {
-		log.Printf("could not ensure uuid extension: %v", err)
-	}
-	// Auto-migrate models
-	if err := db.AutoMigrate(
-		&models.User{},
-		&models.AdminUser{},
-		&models.Company{},
-		&models.Review{},
-		&models.ReviewValidationResult{},
-		&models.FraudSignal{},
-		&models.Achievement{},
-		&models.UserAchievement{},
-	); err != nil {
-		return nil, err
-	}
-	return db, nil
+func fatal(msg string, err error) {
+	slog.Error(msg, logging.Err(err))
+	os.Exit(1)
 }
 
 func connectRedis(url string) (*redis.Client, error) {
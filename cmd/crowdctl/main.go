@@ -0,0 +1,302 @@
+// Command crowdctl runs maintenance tasks against the CrowdReview database.
+//
+// Usage:
+//
+//	crowdctl <command> [flags]
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"crowdreview/config"
+	"crowdreview/internal/database"
+	"crowdreview/internal/mail"
+	"crowdreview/internal/repository"
+	"crowdreview/internal/services"
+	"crowdreview/pkg/logging"
+)
+
+// app carries the wiring shared by every command.
+type app struct {
+	cfg      config.Config
+	repos    repository.Repositories
+	services services.Services
+}
+
+type command struct {
+	summary string
+	run     func(ctx context.Context, a *app, args []string) error
+}
+
+var commands = map[string]command{
+	"rebuild-aggregates": {
+		summary: "recompute company rating aggregates from the reviews table",
+		run:     rebuildAggregates,
+	},
+	"map-taxonomy": {
+		summary: "link free-text company industries and locations to the taxonomy",
+		run:     mapTaxonomy,
+	},
+	"rotate-keys": {
+		summary: "publish a new JWT signing key (-force to rotate before it is due)",
+		run:     rotateKeys,
+	},
+	"import-companies": {
+		summary: "upsert companies by domain from a CSV or NDJSON file",
+		run:     importCompanies,
+	},
+	"export-companies": {
+		summary: "write all companies with rating aggregates as CSV or NDJSON",
+		run:     exportCompanies,
+	},
+	"recompute-scores": {
+		summary: "reset every gamification score to the sum of its points ledger",
+		run:     recomputeScores,
+	},
+	"decay-points": {
+		summary: "decay achievement points older than POINTS_DECAY_AFTER_DAYS",
+		run:     decayPoints,
+	},
+	"send-notifications": {
+		summary: "send pending notifications (the email digest) now",
+		run:     sendNotifications,
+	},
+	"seed-achievements": {
+		summary: "add the default achievement catalog (-overwrite to reset existing entries)",
+		run:     seedAchievements,
+	},
+	"scrub-pii": {
+		summary: "clear IP, geolocation and metadata of reviews past PII_RETENTION_DAYS",
+		run:     scrubPII,
+	},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+
+	cfg := config.LoadConfig()
+	logging.Setup(cfg.LogLevel)
+	db, err := database.Connect(cfg.DatabaseURL)
+	if err != nil {
+		fatal("failed to connect database", err)
+	}
+	mailer, err := mail.New(cfg)
+	if err != nil {
+		fatal("failed to configure mailer", err)
+	}
+	repos := repository.NewRepositories(db)
+	a := &app{
+		cfg:      cfg,
+		repos:    repos,
+		services: services.NewServices(cfg, repos, nil, nil, mailer),
+	}
+
+	if err := cmd.run(context.Background(), a, os.Args[2:]); err != nil {
+		fatal(os.Args[1]+" failed", err)
+	}
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, logging.Err(err))
+	os.Exit(1)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: crowdctl <command> [flags]\n\ncommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-20s %s\n", name, commands[name].summary)
+	}
+}
+
+func rebuildAggregates(ctx context.Context, a *app, args []string) error {
+	if err := a.services.Company.RebuildRatings(ctx); err != nil {
+		return err
+	}
+	slog.Info("company rating aggregates rebuilt")
+	return nil
+}
+
+func mapTaxonomy(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("map-taxonomy", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "report what would change without writing")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	report, err := a.services.Directory.MapLegacy(ctx, *dryRun)
+	if err != nil {
+		return err
+	}
+	slog.Info("taxonomy mapped",
+		slog.Int("companies", report.Companies),
+		slog.Any("industries_created", report.IndustriesCreated),
+		slog.Any("regions_created", report.RegionsCreated),
+		slog.Int("criteria_updated", report.CriteriaUpdated),
+		slog.Bool("dry_run", *dryRun))
+	return nil
+}
+
+func scrubPII(ctx context.Context, a *app, args []string) error {
+	n, err := a.services.Privacy.ScrubExpired(ctx)
+	if err != nil {
+		return err
+	}
+	slog.Info("scrubbed review PII", slog.Int64("reviews", n), slog.Duration("older_than", a.cfg.PIIRetention))
+	return nil
+}
+
+func recomputeScores(ctx context.Context, a *app, args []string) error {
+	n, err := a.services.Points.Recompute(ctx, nil)
+	if err != nil {
+		return err
+	}
+	slog.Info("corrected gamification scores", slog.Int64("users", n))
+	return nil
+}
+
+func decayPoints(ctx context.Context, a *app, args []string) error {
+	n, err := a.services.Points.Decay(ctx)
+	if err != nil {
+		return err
+	}
+	slog.Info("decayed aged points", slog.Int("users", n), slog.Int("percent", a.cfg.PointsDecayPercent), slog.Duration("after", a.cfg.PointsDecayAfter))
+	return nil
+}
+
+func sendNotifications(ctx context.Context, a *app, args []string) error {
+	n, err := a.services.Notifications.Deliver(ctx)
+	if err != nil {
+		return err
+	}
+	slog.Info("delivered notifications", slog.Int("notifications", n))
+	return nil
+}
+
+func seedAchievements(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("seed-achievements", flag.ExitOnError)
+	overwrite := fs.Bool("overwrite", false, "reset achievements that already exist to the defaults")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	report, err := a.services.Achievements.Seed(ctx, *overwrite)
+	if err != nil {
+		return err
+	}
+	slog.Info("seeded achievements",
+		slog.Any("created", report.Created),
+		slog.Any("updated", report.Updated),
+		slog.Any("skipped", report.Skipped),
+		slog.Any("retired", report.Retired))
+	return nil
+}
+
+func rotateKeys(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("rotate-keys", flag.ExitOnError)
+	force := fs.Bool("force", false, "rotate even if the current key is not due")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	key, err := a.services.Keys.Rotate(ctx, *force)
+	if err != nil {
+		return err
+	}
+	if key == nil {
+		slog.Info("signing key rotation not due")
+		return nil
+	}
+	slog.Info("signing key created", slog.String("kid", key.Kid), slog.String("algorithm", key.Algorithm), slog.Time("activates_at", key.ActivatesAt))
+	return nil
+}
+
+func importCompanies(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("import-companies", flag.ExitOnError)
+	file := fs.String("file", "", "input file (required)")
+	format := fs.String("format", "", "csv or ndjson (default: from file extension)")
+	dryRun := fs.Bool("dry-run", false, "validate rows without writing")
+	allowDuplicates := fs.Bool("allow-duplicates", false, "create companies even when similar ones exist")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("-file is required")
+	}
+	if *format == "" {
+		*format = formatFromPath(*file)
+	}
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	report, err := a.services.Transfer.Import(ctx, f, services.ImportOptions{
+		Format:          *format,
+		DryRun:          *dryRun,
+		AllowDuplicates: *allowDuplicates,
+	})
+	if err != nil {
+		return err
+	}
+	for _, e := range report.Errors {
+		slog.Warn("import row failed", slog.Int("line", e.Line), slog.String("domain", e.Domain), slog.String("error", e.Error))
+	}
+	slog.Info("companies imported",
+		slog.Int("rows", report.Rows),
+		slog.Int("created", report.Created),
+		slog.Int("updated", report.Updated),
+		slog.Int("failed", report.Failed),
+		slog.Bool("dry_run", report.DryRun))
+	return nil
+}
+
+func exportCompanies(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("export-companies", flag.ExitOnError)
+	out := fs.String("out", "", "output file (default: stdout)")
+	format := fs.String("format", "", "csv or ndjson (default: from file extension, else csv)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format == "" {
+		*format = formatFromPath(*out)
+	}
+	if *out == "" {
+		return a.services.Transfer.Export(ctx, os.Stdout, *format)
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := a.services.Transfer.Export(ctx, f, *format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func formatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return services.FormatNDJSON
+	default:
+		return services.FormatCSV
+	}
+}
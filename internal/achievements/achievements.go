@@ -0,0 +1,122 @@
+// Package achievements evaluates the declarative award criteria stored in
+// Achievement.Meta["criteria"] against facts about a user.
+package achievements
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Domain events that trigger achievement evaluation.
+const (
+	EventReviewApproved = "review_approved"
+	// EventHelpfulVote is reserved for review votes; nothing emits it yet.
+	EventHelpfulVote = "helpful_vote_received"
+)
+
+// Places a review can be the first approved one in.
+const (
+	FirstInCompany  = "company"
+	FirstInIndustry = "industry"
+)
+
+// MetaKey is the Achievement.Meta key holding the criteria.
+const MetaKey = "criteria"
+
+// ErrNoCriteria marks achievements without criteria; they are only
+// granted by hand.
+var ErrNoCriteria = errors.New("achievement has no criteria")
+
+// Event is something a user did or received.
+type Event struct {
+	Type     string
+	UserID   uuid.UUID
+	ReviewID uuid.UUID // review events
+	Count    int64     // helpful votes: the author's running total
+}
+
+// Criteria decide when an achievement is earned. Every set condition must
+// hold, e.g. {"event": "review_approved", "count": 10} or
+// {"event": "review_approved", "first_in": "industry"}.
+type Criteria struct {
+	Event string `json:"event"`
+	// Count is the minimum number of approved reviews (within Industry, if
+	// set) or, for helpful votes, of votes received.
+	Count              int64  `json:"count,omitempty"`
+	Industry           string `json:"industry,omitempty"` // industry slug
+	DistinctIndustries int    `json:"distinct_industries,omitempty"`
+	FirstIn            string `json:"first_in,omitempty"` // company or industry
+}
+
+// Facts describe the user when the event happened.
+type Facts struct {
+	ApprovedReviews int64
+	IndustryReviews map[string]int64 // approved reviews per industry slug
+	HelpfulVotes    int64
+	FirstInCompany  bool // the event's review is the company's first approved review
+	FirstInIndustry bool // ... or its industry's
+}
+
+// ParseCriteria reads and validates the criteria of an achievement.
+func ParseCriteria(meta map[string]interface{}) (Criteria, error) {
+	raw, ok := meta[MetaKey]
+	if !ok || raw == nil {
+		return Criteria{}, ErrNoCriteria
+	}
+	buf, err := json.Marshal(raw)
+	if err != nil {
+		return Criteria{}, err
+	}
+	var c Criteria
+	if err := json.Unmarshal(buf, &c); err != nil {
+		return Criteria{}, fmt.Errorf("invalid criteria: %w", err)
+	}
+	return c, c.Validate()
+}
+
+// Validate checks that the criteria can ever be met.
+func (c Criteria) Validate() error {
+	switch c.Event {
+	case EventReviewApproved:
+	case EventHelpfulVote:
+		if c.Industry != "" || c.DistinctIndustries > 0 || c.FirstIn != "" {
+			return errors.New("helpful vote criteria only support count")
+		}
+	default:
+		return fmt.Errorf("unknown event %q", c.Event)
+	}
+	if c.Count < 0 || c.DistinctIndustries < 0 {
+		return errors.New("thresholds must not be negative")
+	}
+	if c.FirstIn != "" && c.FirstIn != FirstInCompany && c.FirstIn != FirstInIndustry {
+		return fmt.Errorf("first_in must be %q or %q", FirstInCompany, FirstInIndustry)
+	}
+	return nil
+}
+
+// Met reports whether facts satisfy every condition.
+func (c Criteria) Met(f Facts) bool {
+	if c.Event == EventHelpfulVote {
+		return f.HelpfulVotes >= c.Count
+	}
+	count := f.ApprovedReviews
+	if c.Industry != "" {
+		count = f.IndustryReviews[c.Industry]
+	}
+	if count < c.Count || count == 0 {
+		return false
+	}
+	if len(f.IndustryReviews) < c.DistinctIndustries {
+		return false
+	}
+	switch c.FirstIn {
+	case FirstInCompany:
+		return f.FirstInCompany
+	case FirstInIndustry:
+		return f.FirstInIndustry
+	}
+	return true
+}
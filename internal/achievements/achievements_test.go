@@ -0,0 +1,59 @@
+package achievements
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCriteria(t *testing.T) {
+	c, err := ParseCriteria(map[string]interface{}{MetaKey: map[string]interface{}{"event": "review_approved", "count": 10}})
+	require.NoError(t, err)
+	require.Equal(t, Criteria{Event: EventReviewApproved, Count: 10}, c)
+
+	_, err = ParseCriteria(map[string]interface{}{"icon": "star"})
+	require.ErrorIs(t, err, ErrNoCriteria)
+	_, err = ParseCriteria(map[string]interface{}{MetaKey: map[string]interface{}{"event": "logged_in"}})
+	require.Error(t, err)
+	_, err = ParseCriteria(map[string]interface{}{MetaKey: map[string]interface{}{"event": "review_approved", "first_in": "city"}})
+	require.Error(t, err)
+}
+
+func TestCriteriaMet(t *testing.T) {
+	facts := Facts{
+		ApprovedReviews: 3,
+		IndustryReviews: map[string]int64{"tecnologia": 2, "varejo": 1},
+		FirstInIndustry: true,
+	}
+	cases := []struct {
+		criteria Criteria
+		met      bool
+	}{
+		{Criteria{Event: EventReviewApproved}, true},
+		{Criteria{Event: EventReviewApproved, Count: 3}, true},
+		{Criteria{Event: EventReviewApproved, Count: 4}, false},
+		{Criteria{Event: EventReviewApproved, Industry: "tecnologia", Count: 2}, true},
+		{Criteria{Event: EventReviewApproved, Industry: "saude"}, false},
+		{Criteria{Event: EventReviewApproved, DistinctIndustries: 2}, true},
+		{Criteria{Event: EventReviewApproved, DistinctIndustries: 3}, false},
+		{Criteria{Event: EventReviewApproved, FirstIn: FirstInIndustry}, true},
+		{Criteria{Event: EventReviewApproved, FirstIn: FirstInCompany}, false},
+		{Criteria{Event: EventHelpfulVote, Count: 1}, false},
+	}
+	for _, tc := range cases {
+		require.Equal(t, tc.met, tc.criteria.Met(facts), "%+v", tc.criteria)
+	}
+}
+
+func TestDefaultCatalog(t *testing.T) {
+	entries, err := DefaultCatalog()
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+	names := map[string]bool{}
+	for _, e := range entries {
+		require.NotEmpty(t, e.Name)
+		require.False(t, names[e.Name], "duplicate %q", e.Name)
+		names[e.Name] = true
+		require.Positive(t, e.Points, e.Name)
+	}
+}
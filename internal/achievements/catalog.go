@@ -0,0 +1,46 @@
+package achievements
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+)
+
+//go:embed catalog.json
+var catalogJSON []byte
+
+// CatalogEntry is an achievement definition of the default catalog. A nil
+// Criteria makes it manual-only.
+type CatalogEntry struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Icon        string    `json:"icon"`
+	Points      int       `json:"points"`
+	Criteria    *Criteria `json:"criteria"`
+}
+
+// DefaultCatalog returns the achievements seeded into new installations.
+func DefaultCatalog() ([]CatalogEntry, error) {
+	var entries []CatalogEntry
+	if err := json.Unmarshal(catalogJSON, &entries); err != nil {
+		return nil, fmt.Errorf("default achievement catalog: %w", err)
+	}
+	for _, e := range entries {
+		if e.Criteria == nil {
+			continue
+		}
+		if err := e.Criteria.Validate(); err != nil {
+			return nil, fmt.Errorf("default achievement %q: %w", e.Name, err)
+		}
+	}
+	return entries, nil
+}
+
+// SetCriteria stores c in meta, or removes the criteria when c is nil.
+func SetCriteria(meta map[string]interface{}, c *Criteria) {
+	if c == nil {
+		delete(meta, MetaKey)
+		return
+	}
+	meta[MetaKey] = *c
+}
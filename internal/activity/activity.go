@@ -0,0 +1,133 @@
+// Package activity fans live moderation events out to the admin event
+// stream, across API replicas through Redis pub/sub.
+package activity
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"crowdreview/internal/models"
+	"crowdreview/pkg/logging"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Event types.
+const (
+	EventReviewFlagged  = "review.flagged"
+	EventReviewClaimed  = "review.claimed"
+	EventReviewReleased = "review.released"
+	EventIncident       = "incident" // account or IP lockouts
+)
+
+// Channel is the Redis pub/sub channel events travel on.
+const Channel = "crowdreview:activity"
+
+// subscriberBuffer is how many events a slow subscriber may lag behind
+// before it misses some.
+const subscriberBuffer = 64
+
+// Event is one piece of live activity.
+type Event struct {
+	ID   uuid.UUID              `json:"id"`
+	Type string                 `json:"type"`
+	At   time.Time              `json:"at"`
+	Data map[string]interface{} `json:"data"`
+}
+
+// Permission is the admin permission needed to receive events of the type.
+func Permission(eventType string) string {
+	if eventType == EventIncident {
+		return models.PermUsersManage
+	}
+	return models.PermReviewsModerate
+}
+
+// Bus publishes events to every replica and hands them to the local
+// subscribers. Without Redis events stay on the replica that published
+// them. Delivery is best effort: there is no history to replay.
+type Bus struct {
+	redis *redis.Client
+	mu    sync.Mutex
+	subs  map[chan Event]struct{}
+}
+
+func NewBus(rdb *redis.Client) *Bus {
+	return &Bus{redis: rdb, subs: map[chan Event]struct{}{}}
+}
+
+// Publish sends the event, filling in its ID and time when unset.
+func (b *Bus) Publish(ctx context.Context, event Event) error {
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	if event.At.IsZero() {
+		event.At = time.Now().UTC()
+	}
+	if b.redis == nil {
+		b.broadcast(event)
+		return nil
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return b.redis.Publish(ctx, Channel, payload).Err()
+}
+
+// Subscribe returns the events published from now on and a function that
+// stops the subscription. Events a subscriber is too slow for are dropped
+// rather than holding up the others.
+func (b *Bus) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+func (b *Bus) broadcast(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Start relays the events of every replica from Redis to the local
+// subscribers until ctx is done.
+func (b *Bus) Start(ctx context.Context) {
+	if b.redis == nil {
+		return
+	}
+	sub := b.redis.Subscribe(ctx, Channel)
+	go func() {
+		<-ctx.Done()
+		sub.Close()
+	}()
+	go func() {
+		for msg := range sub.Channel() {
+			var event Event
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				slog.WarnContext(ctx, "bad activity event", slog.String("channel", Channel), logging.Err(err))
+				continue
+			}
+			b.broadcast(event)
+		}
+	}()
+}
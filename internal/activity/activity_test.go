@@ -0,0 +1,48 @@
+package activity
+
+import (
+	"context"
+	"testing"
+
+	"crowdreview/internal/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalBusFansOut(t *testing.T) {
+	ctx := context.Background()
+	bus := NewBus(nil)
+	first, stopFirst := bus.Subscribe()
+	second, stopSecond := bus.Subscribe()
+	defer stopSecond()
+
+	require.NoError(t, bus.Publish(ctx, Event{Type: EventReviewFlagged}))
+	a, b := <-first, <-second
+	require.Equal(t, a.ID, b.ID)
+	require.NotZero(t, a.ID)
+	require.False(t, a.At.IsZero())
+
+	stopFirst()
+	stopFirst()
+	_, open := <-first
+	require.False(t, open)
+	require.NoError(t, bus.Publish(ctx, Event{Type: EventReviewClaimed}))
+	require.Equal(t, EventReviewClaimed, (<-second).Type)
+}
+
+func TestSlowSubscribersMissEvents(t *testing.T) {
+	ctx := context.Background()
+	bus := NewBus(nil)
+	events, stop := bus.Subscribe()
+	defer stop()
+
+	for i := 0; i < subscriberBuffer+10; i++ {
+		require.NoError(t, bus.Publish(ctx, Event{Type: EventReviewFlagged}))
+	}
+	require.Len(t, events, subscriberBuffer)
+}
+
+func TestPermission(t *testing.T) {
+	require.Equal(t, models.PermReviewsModerate, Permission(EventReviewReleased))
+	require.Equal(t, models.PermUsersManage, Permission(EventIncident))
+}
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"log/slog"
+
+	"crowdreview/internal/models"
+	"crowdreview/pkg/logging"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Connect opens the PostgreSQL connection and migrates the schema.
+func Connect(url string) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.Open(url), &gorm.Config{Logger: queryLogger{}})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp";`).Error; err != nil {
+		slog.Warn("could not ensure uuid extension", logging.Err(err))
+	}
+	if err := Migrate(db); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// Migrate auto-migrates every model and installs the SQL-level extras.
+func Migrate(db *gorm.DB) error {
+	if err := db.AutoMigrate(
+		&models.User{},
+		&models.AdminUser{},
+		&models.RefreshToken{},
+		&models.SigningKey{},
+		&models.UserToken{},
+		&models.SecurityEvent{},
+		&models.TOTPCredential{},
+		&models.RecoveryCode{},
+		&models.Identity{},
+		&models.OIDCState{},
+		&models.Industry{},
+		&models.Region{},
+		&models.Company{},
+		&models.CompanyAlias{},
+		&models.Review{},
+		&models.ReviewValidationResult{},
+		&models.FraudSignal{},
+		&models.Achievement{},
+		&models.UserAchievement{},
+		&models.PointsEntry{},
+		&models.Notification{},
+		&models.NotificationDelivery{},
+		&models.WebhookSubscription{},
+		&models.WebhookDelivery{},
+		&models.OutboxEvent{},
+		&models.CompanyRatingStats{},
+		&models.CompanyRatingDay{},
+		&models.RatingCriterion{},
+		&models.ReviewScore{},
+		&models.CompanyCriterionStats{},
+	); err != nil {
+		return err
+	}
+	if err := migratePointsLedger(db); err != nil {
+		return err
+	}
+	// Names of deleted achievements may be reused.
+	if err := db.Exec(`DROP INDEX IF EXISTS idx_achievements_name`).Error; err != nil {
+		return err
+	}
+	return migrateSearch(db)
+}
+
+// migratePointsLedger gives users whose score predates the ledger an
+// opening balance, so every score equals the sum of its ledger.
+func migratePointsLedger(db *gorm.DB) error {
+	return db.Exec(`
+		INSERT INTO points_ledger (id, created_at, updated_at, user_id, delta, reason)
+		SELECT gen_random_uuid(), now(), now(), u.id, u.gamification_score, ?
+		FROM users u
+		WHERE u.gamification_score <> 0
+		  AND NOT EXISTS (SELECT 1 FROM points_ledger p WHERE p.user_id = u.id)`,
+		models.PointsOpeningBalance).Error
+}
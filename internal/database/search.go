@@ -0,0 +1,57 @@
+package database
+
+import "gorm.io/gorm"
+
+// searchDDL maintains the tsvector columns behind full-text search. Each
+// document is indexed with both the Portuguese and English configurations so
+// stemming works for either language; triggers keep the vectors in sync on
+// every write. All statements are idempotent.
+var searchDDL = []string{
+	`ALTER TABLE companies ADD COLUMN IF NOT EXISTS search_vector tsvector`,
+	`CREATE INDEX IF NOT EXISTS idx_companies_search_vector ON companies USING GIN (search_vector)`,
+	`CREATE OR REPLACE FUNCTION companies_search_vector_update() RETURNS trigger AS $$
+	BEGIN
+		NEW.search_vector :=
+			setweight(to_tsvector('portuguese', coalesce(NEW.name, '')), 'A') ||
+			setweight(to_tsvector('english', coalesce(NEW.name, '')), 'A') ||
+			setweight(to_tsvector('portuguese', coalesce(NEW.industry, '') || ' ' || coalesce(NEW.location, '')), 'B') ||
+			setweight(to_tsvector('english', coalesce(NEW.industry, '') || ' ' || coalesce(NEW.location, '')), 'B') ||
+			setweight(to_tsvector('portuguese', coalesce(NEW.description, '')), 'C') ||
+			setweight(to_tsvector('english', coalesce(NEW.description, '')), 'C');
+		RETURN NEW;
+	END
+	$$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS companies_search_vector_trg ON companies`,
+	`CREATE TRIGGER companies_search_vector_trg
+		BEFORE INSERT OR UPDATE OF name, industry, location, description ON companies
+		FOR EACH ROW EXECUTE FUNCTION companies_search_vector_update()`,
+	`UPDATE companies SET name = name WHERE search_vector IS NULL`,
+
+	`ALTER TABLE reviews ADD COLUMN IF NOT EXISTS search_vector tsvector`,
+	`CREATE INDEX IF NOT EXISTS idx_reviews_search_vector ON reviews USING GIN (search_vector)`,
+	`CREATE OR REPLACE FUNCTION reviews_search_vector_update() RETURNS trigger AS $$
+	BEGIN
+		NEW.search_vector :=
+			setweight(to_tsvector('portuguese', coalesce(NEW.title, '')), 'A') ||
+			setweight(to_tsvector('english', coalesce(NEW.title, '')), 'A') ||
+			setweight(to_tsvector('portuguese', coalesce(NEW.content, '')), 'B') ||
+			setweight(to_tsvector('english', coalesce(NEW.content, '')), 'B');
+		RETURN NEW;
+	END
+	$$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS reviews_search_vector_trg ON reviews`,
+	`CREATE TRIGGER reviews_search_vector_trg
+		BEFORE INSERT OR UPDATE OF title, content ON reviews
+		FOR EACH ROW EXECUTE FUNCTION reviews_search_vector_update()`,
+	`UPDATE reviews SET title = title WHERE search_vector IS NULL`,
+}
+
+// migrateSearch installs the full-text search columns, indexes and triggers.
+func migrateSearch(db *gorm.DB) error {
+	for _, stmt := range searchDDL {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
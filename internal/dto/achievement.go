@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"crowdreview/internal/achievements"
+	"crowdreview/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Achievement is a catalog entry. Criteria is nil for manual-only
+// achievements.
+type Achievement struct {
+	ID          uuid.UUID              `json:"id"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Icon        string                 `json:"icon"`
+	Points      int                    `json:"points"`
+	Criteria    *achievements.Criteria `json:"criteria"`
+}
+
+// NewAchievement maps an achievement model.
+func NewAchievement(a models.Achievement) Achievement {
+	out := Achievement{ID: a.ID, Name: a.Name, Description: a.Description, Icon: a.Icon, Points: a.Points}
+	if c, err := achievements.ParseCriteria(a.Meta); err == nil {
+		out.Criteria = &c
+	}
+	return out
+}
+
+// NewAchievements maps the catalog.
+func NewAchievements(list []models.Achievement) []Achievement {
+	out := make([]Achievement, 0, len(list))
+	for _, a := range list {
+		out = append(out, NewAchievement(a))
+	}
+	return out
+}
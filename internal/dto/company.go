@@ -0,0 +1,89 @@
+package dto
+
+import (
+	"time"
+
+	"crowdreview/internal/models"
+	"crowdreview/internal/ratings"
+	"crowdreview/internal/services"
+
+	"github.com/google/uuid"
+)
+
+// Company is the public representation of a company.
+type Company struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Domain      string     `json:"domain"`
+	IndustryID  *uuid.UUID `json:"industry_id"`
+	Industry    string     `json:"industry"`
+	RegionID    *uuid.UUID `json:"region_id"`
+	Location    string     `json:"location"`
+	Description string     `json:"description"`
+	Website     string     `json:"website"`
+	CreatedAt   time.Time  `json:"created_at"`
+	Aliases     []Alias    `json:"aliases,omitempty"`
+
+	Ratings *ratings.Snapshot `json:"ratings,omitempty"`
+}
+
+// NewCompany maps a company model to its API representation.
+func NewCompany(c models.Company) Company {
+	return Company{
+		ID:          c.ID,
+		Name:        c.Name,
+		Domain:      c.Domain,
+		IndustryID:  c.IndustryID,
+		Industry:    c.Industry,
+		RegionID:    c.RegionID,
+		Location:    c.Location,
+		Description: c.Description,
+		Website:     c.Website,
+		CreatedAt:   c.CreatedAt,
+		Aliases:     NewAliases(c.Aliases),
+	}
+}
+
+// NewCompanies maps a slice of company models.
+func NewCompanies(companies []models.Company) []Company {
+	out := make([]Company, 0, len(companies))
+	for _, c := range companies {
+		out = append(out, NewCompany(c))
+	}
+	return out
+}
+
+// Alias is an alternative company name.
+type Alias struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+// NewAliases maps company aliases; nil when there are none.
+func NewAliases(aliases []models.CompanyAlias) []Alias {
+	if len(aliases) == 0 {
+		return nil
+	}
+	out := make([]Alias, 0, len(aliases))
+	for _, a := range aliases {
+		out = append(out, Alias{ID: a.ID, Name: a.Name})
+	}
+	return out
+}
+
+// DuplicateMatch is an existing company resembling a new one.
+type DuplicateMatch struct {
+	CompanyID  uuid.UUID `json:"company_id"`
+	Name       string    `json:"name"`
+	MatchedOn  string    `json:"matched_on"`
+	Similarity float64   `json:"similarity"`
+}
+
+// NewDuplicateMatches maps duplicate suggestions.
+func NewDuplicateMatches(matches []services.DuplicateMatch) []DuplicateMatch {
+	out := make([]DuplicateMatch, 0, len(matches))
+	for _, m := range matches {
+		out = append(out, DuplicateMatch(m))
+	}
+	return out
+}
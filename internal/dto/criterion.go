@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"crowdreview/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Criterion is the API representation of a rating criterion.
+type Criterion struct {
+	ID       uuid.UUID `json:"id"`
+	Industry string    `json:"industry"`
+	Key      string    `json:"key"`
+	Label    string    `json:"label"`
+	Position int       `json:"position"`
+}
+
+// NewCriterion maps a rating criterion model.
+func NewCriterion(c models.RatingCriterion) Criterion {
+	return Criterion{ID: c.ID, Industry: c.Industry, Key: c.Key, Label: c.Label, Position: c.Position}
+}
+
+// NewCriteria maps a slice of rating criteria.
+func NewCriteria(criteria []models.RatingCriterion) []Criterion {
+	out := make([]Criterion, 0, len(criteria))
+	for _, c := range criteria {
+		out = append(out, NewCriterion(c))
+	}
+	return out
+}
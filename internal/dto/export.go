@@ -0,0 +1,100 @@
+package dto
+
+import (
+	"time"
+
+	"crowdreview/internal/services"
+)
+
+// DataExport is the archive returned by GET /me/export.
+type DataExport struct {
+	ExportedAt     time.Time           `json:"exported_at"`
+	Account        Account             `json:"account"`
+	Identities     []ExportedIdentity  `json:"linked_identities"`
+	TwoFactor      *ExportedTwoFactor  `json:"two_factor"`
+	Reviews        []ExportedReview    `json:"reviews"`
+	Achievements   []EarnedAchievement `json:"achievements"`
+	Points         []PointsEntry       `json:"points"`
+	Notifications  []Notification      `json:"notifications"`
+	SecurityEvents []SecurityEvent     `json:"security_events"`
+}
+
+// ExportedIdentity is a linked social login.
+type ExportedIdentity struct {
+	Provider string    `json:"provider"`
+	Subject  string    `json:"subject"`
+	Email    string    `json:"email,omitempty"`
+	LinkedAt time.Time `json:"linked_at"`
+}
+
+// ExportedTwoFactor describes the authenticator enrollment, without secrets.
+type ExportedTwoFactor struct {
+	EnrolledAt  time.Time  `json:"enrolled_at"`
+	ConfirmedAt *time.Time `json:"confirmed_at"`
+}
+
+// ExportedReview is the owner's full view of a review, including the
+// moderation data that is never public.
+type ExportedReview struct {
+	Review
+	CompanyName string                 `json:"company_name"`
+	IPAddress   string                 `json:"ip_address,omitempty"`
+	GeoLocation string                 `json:"geo_location,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Moderation  *ExportedModeration    `json:"moderation,omitempty"`
+}
+
+// ExportedModeration is the fraud engine outcome for a review.
+type ExportedModeration struct {
+	Score     float64                `json:"score"`
+	Outcome   string                 `json:"outcome"`
+	Checks    map[string]interface{} `json:"checks,omitempty"`
+	Signals   []ExportedFraudSignal  `json:"signals,omitempty"`
+	CheckedAt time.Time              `json:"checked_at"`
+}
+
+// ExportedFraudSignal is a failed fraud rule.
+type ExportedFraudSignal struct {
+	Type     string                 `json:"type"`
+	Severity string                 `json:"severity"`
+	Details  map[string]interface{} `json:"details,omitempty"`
+}
+
+// NewDataExport maps everything stored about a user.
+func NewDataExport(e services.UserExport, now time.Time) DataExport {
+	out := DataExport{
+		ExportedAt:     now,
+		Account:        NewAccount(e.User),
+		Identities:     make([]ExportedIdentity, 0, len(e.Identities)),
+		Reviews:        make([]ExportedReview, 0, len(e.Reviews)),
+		Achievements:   NewEarnedAchievements(e.Achievements),
+		Points:         NewPointsEntries(e.Points),
+		Notifications:  NewNotifications(e.Notifications),
+		SecurityEvents: NewSecurityEvents(e.SecurityEvents),
+	}
+	for _, i := range e.Identities {
+		out.Identities = append(out.Identities, ExportedIdentity{Provider: i.Provider, Subject: i.Subject, Email: i.Email, LinkedAt: i.CreatedAt})
+	}
+	if e.TwoFactor != nil {
+		out.TwoFactor = &ExportedTwoFactor{EnrolledAt: e.TwoFactor.CreatedAt, ConfirmedAt: e.TwoFactor.ConfirmedAt}
+	}
+	for _, r := range e.Reviews {
+		review := ExportedReview{
+			Review:      NewReview(r),
+			CompanyName: r.Company.Name,
+			IPAddress:   r.IPAddress,
+			GeoLocation: r.GeoLocation,
+			Metadata:    r.Metadata,
+		}
+		review.Author = &PublicUser{ID: e.User.ID, Username: e.User.Username}
+		if v := r.ValidationResult; v != nil {
+			moderation := &ExportedModeration{Score: v.Score, Outcome: v.Outcome, Checks: v.Checks, CheckedAt: v.CreatedAt}
+			for _, s := range v.Signals {
+				moderation.Signals = append(moderation.Signals, ExportedFraudSignal{Type: s.Type, Severity: s.Severity, Details: s.Details})
+			}
+			review.Moderation = moderation
+		}
+		out.Reviews = append(out.Reviews, review)
+	}
+	return out
+}
@@ -0,0 +1,40 @@
+package dto
+
+import "crowdreview/internal/services"
+
+// LeaderboardEntry is a ranked reviewer.
+type LeaderboardEntry struct {
+	Rank  int64      `json:"rank"`
+	User  PublicUser `json:"user"`
+	Score int        `json:"score"`
+	Tier  string     `json:"tier"`
+}
+
+// Leaderboard is a page of a leaderboard.
+type Leaderboard struct {
+	Scope    string             `json:"scope"`
+	Industry string             `json:"industry,omitempty"`
+	Month    string             `json:"month,omitempty"`
+	Total    int64              `json:"total"`
+	Items    []LeaderboardEntry `json:"items"`
+}
+
+// NewLeaderboard maps a leaderboard page.
+func NewLeaderboard(p services.LeaderboardPage) Leaderboard {
+	out := Leaderboard{Scope: p.Scope, Total: p.Total, Items: make([]LeaderboardEntry, 0, len(p.Entries))}
+	switch p.Scope {
+	case services.LeaderboardIndustry:
+		out.Industry = p.Key
+	case services.LeaderboardMonth:
+		out.Month = p.Key
+	}
+	for _, e := range p.Entries {
+		out.Items = append(out.Items, LeaderboardEntry{
+			Rank:  e.Rank,
+			User:  NewPublicUser(e.User),
+			Score: e.Score,
+			Tier:  e.Tier.Name,
+		})
+	}
+	return out
+}
@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"time"
+
+	"crowdreview/internal/models"
+	"crowdreview/internal/services"
+
+	"github.com/google/uuid"
+)
+
+// Notification is an entry of the caller's inbox.
+type Notification struct {
+	ID        uuid.UUID              `json:"id"`
+	Type      string                 `json:"type"`
+	Title     string                 `json:"title"`
+	Body      string                 `json:"body,omitempty"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	Read      bool                   `json:"read"`
+	ReadAt    *time.Time             `json:"read_at,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// NewNotifications maps notifications.
+func NewNotifications(list []models.Notification) []Notification {
+	out := make([]Notification, 0, len(list))
+	for _, n := range list {
+		out = append(out, Notification{
+			ID:        n.ID,
+			Type:      n.Type,
+			Title:     n.Title,
+			Body:      n.Body,
+			Data:      n.Data,
+			Read:      n.ReadAt != nil,
+			ReadAt:    n.ReadAt,
+			CreatedAt: n.CreatedAt,
+		})
+	}
+	return out
+}
+
+// Inbox is a page of the caller's notifications.
+type Inbox struct {
+	Total  int64          `json:"total"`
+	Unread int64          `json:"unread"`
+	Items  []Notification `json:"items"`
+}
+
+// NewInbox maps an inbox page.
+func NewInbox(i services.Inbox) Inbox {
+	return Inbox{Total: i.Total, Unread: i.Unread, Items: NewNotifications(i.Notifications)}
+}
@@ -0,0 +1,58 @@
+package dto
+
+import (
+	"time"
+
+	"crowdreview/internal/models"
+	"crowdreview/internal/services"
+
+	"github.com/google/uuid"
+)
+
+// PointsEntry is one change to a user's score.
+type PointsEntry struct {
+	ID            uuid.UUID  `json:"id"`
+	Delta         int        `json:"delta"`
+	Reason        string     `json:"reason"`
+	ReviewID      *uuid.UUID `json:"review_id,omitempty"`
+	AchievementID *uuid.UUID `json:"achievement_id,omitempty"`
+	DecayOf       *uuid.UUID `json:"decay_of,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// NewPointsEntries maps ledger entries.
+func NewPointsEntries(entries []models.PointsEntry) []PointsEntry {
+	out := make([]PointsEntry, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, PointsEntry{
+			ID:            e.ID,
+			Delta:         e.Delta,
+			Reason:        e.Reason,
+			ReviewID:      e.ReviewID,
+			AchievementID: e.AchievementID,
+			DecayOf:       e.DecayOf,
+			CreatedAt:     e.CreatedAt,
+		})
+	}
+	return out
+}
+
+// PointsHistory is a page of a user's points ledger shown to admins.
+type PointsHistory struct {
+	UserID  uuid.UUID     `json:"user_id"`
+	Score   int           `json:"score"`
+	Balance int           `json:"balance"`
+	Total   int64         `json:"total"`
+	Items   []PointsEntry `json:"items"`
+}
+
+// NewPointsHistory maps a ledger page.
+func NewPointsHistory(h services.PointsHistory) PointsHistory {
+	return PointsHistory{
+		UserID:  h.UserID,
+		Score:   h.Score,
+		Balance: h.Balance,
+		Total:   h.Total,
+		Items:   NewPointsEntries(h.Entries),
+	}
+}
@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"time"
+
+	"crowdreview/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Review is the API representation of a review. Moderation details such as
+// the reviewer's IP address are never part of it.
+type Review struct {
+	ID        uuid.UUID      `json:"id"`
+	CompanyID uuid.UUID      `json:"company_id"`
+	Rating    int            `json:"rating"`
+	Title     string         `json:"title"`
+	Content   string         `json:"content"`
+	Status    string         `json:"status"`
+	Scores    map[string]int `json:"scores,omitempty"`
+	Anonymous bool           `json:"anonymous"`
+	Author    *PublicUser    `json:"author"` // null for anonymous reviews shown to the public
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// NewReview maps a review model to its API representation.
+func NewReview(r models.Review) Review {
+	var author *PublicUser
+	if r.UserID != uuid.Nil {
+		author = &PublicUser{ID: r.UserID}
+		if r.User.ID == r.UserID {
+			public := NewPublicUser(r.User)
+			author = &public
+		}
+	}
+	var scores map[string]int
+	for _, s := range r.Scores {
+		if s.Criterion.Key == "" {
+			continue
+		}
+		if scores == nil {
+			scores = make(map[string]int, len(r.Scores))
+		}
+		scores[s.Criterion.Key] = s.Score
+	}
+	return Review{
+		ID:        r.ID,
+		CompanyID: r.CompanyID,
+		Rating:    r.Rating,
+		Title:     r.Title,
+		Content:   r.Content,
+		Status:    r.Status,
+		Scores:    scores,
+		Anonymous: r.Anonymous,
+		Author:    author,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+// NewReviews maps a slice of review models.
+func NewReviews(reviews []models.Review) []Review {
+	out := make([]Review, 0, len(reviews))
+	for _, r := range reviews {
+		out = append(out, NewReview(r))
+	}
+	return out
+}
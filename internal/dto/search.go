@@ -0,0 +1,84 @@
+package dto
+
+import (
+	"time"
+
+	"crowdreview/internal/repository"
+	"crowdreview/internal/services"
+
+	"github.com/google/uuid"
+)
+
+// SearchResults is the API representation of a search response.
+type SearchResults struct {
+	Companies SearchPage[CompanyHit] `json:"companies"`
+	Reviews   SearchPage[ReviewHit]  `json:"reviews"`
+	Facets    SearchFacets           `json:"facets"`
+}
+
+// SearchPage is one page of ranked hits.
+type SearchPage[T any] struct {
+	Total int64 `json:"total"`
+	Hits  []T   `json:"hits"`
+}
+
+// SearchFacets lists filter values with their company counts.
+type SearchFacets struct {
+	Industries []Facet `json:"industries"`
+	Locations  []Facet `json:"locations"`
+}
+
+// Facet is a filter value and its number of matches.
+type Facet struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// CompanyHit is a ranked company match.
+type CompanyHit struct {
+	ID       uuid.UUID `json:"id"`
+	Name     string    `json:"name"`
+	Industry string    `json:"industry"`
+	Location string    `json:"location"`
+	Rank     float64   `json:"rank"`
+	Snippet  string    `json:"snippet"`
+}
+
+// ReviewHit is a ranked review match.
+type ReviewHit struct {
+	ID          uuid.UUID `json:"id"`
+	CompanyID   uuid.UUID `json:"company_id"`
+	CompanyName string    `json:"company_name"`
+	Title       string    `json:"title"`
+	Rating      int       `json:"rating"`
+	Rank        float64   `json:"rank"`
+	Snippet     string    `json:"snippet"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// NewSearchResults maps service search results.
+func NewSearchResults(r services.SearchResults) SearchResults {
+	out := SearchResults{
+		Companies: SearchPage[CompanyHit]{Total: r.CompaniesTotal, Hits: make([]CompanyHit, 0, len(r.Companies))},
+		Reviews:   SearchPage[ReviewHit]{Total: r.ReviewsTotal, Hits: make([]ReviewHit, 0, len(r.Reviews))},
+		Facets: SearchFacets{
+			Industries: newFacets(r.Industries),
+			Locations:  newFacets(r.Locations),
+		},
+	}
+	for _, h := range r.Companies {
+		out.Companies.Hits = append(out.Companies.Hits, CompanyHit(h))
+	}
+	for _, h := range r.Reviews {
+		out.Reviews.Hits = append(out.Reviews.Hits, ReviewHit(h))
+	}
+	return out
+}
+
+func newFacets(facets []repository.Facet) []Facet {
+	out := make([]Facet, 0, len(facets))
+	for _, f := range facets {
+		out = append(out, Facet(f))
+	}
+	return out
+}
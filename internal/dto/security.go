@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"time"
+
+	"crowdreview/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// SecurityEvent is an authentication audit record shown to admins.
+type SecurityEvent struct {
+	ID        uuid.UUID              `json:"id"`
+	Type      string                 `json:"type"`
+	UserID    *uuid.UUID             `json:"user_id,omitempty"`
+	Email     string                 `json:"email,omitempty"`
+	IPAddress string                 `json:"ip_address,omitempty"`
+	Meta      map[string]interface{} `json:"meta,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+func NewSecurityEvents(events []models.SecurityEvent) []SecurityEvent {
+	out := make([]SecurityEvent, 0, len(events))
+	for _, e := range events {
+		out = append(out, SecurityEvent{
+			ID:        e.ID,
+			Type:      e.Type,
+			UserID:    e.UserID,
+			Email:     e.Email,
+			IPAddress: e.IPAddress,
+			Meta:      e.Meta,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+	return out
+}
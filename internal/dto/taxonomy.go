@@ -0,0 +1,68 @@
+package dto
+
+import (
+	"crowdreview/internal/models"
+	"crowdreview/internal/services"
+
+	"github.com/google/uuid"
+)
+
+// TaxonomyNode is a directory tree node with its company count, which
+// includes companies in descendant nodes.
+type TaxonomyNode struct {
+	ID        uuid.UUID      `json:"id"`
+	Kind      string         `json:"kind,omitempty"`
+	Slug      string         `json:"slug"`
+	Name      string         `json:"name"`
+	Companies int64          `json:"companies"`
+	Children  []TaxonomyNode `json:"children,omitempty"`
+}
+
+// NewTaxonomyTree maps service tree nodes recursively.
+func NewTaxonomyTree(nodes []*services.TaxonomyNode) []TaxonomyNode {
+	out := make([]TaxonomyNode, 0, len(nodes))
+	for _, n := range nodes {
+		out = append(out, TaxonomyNode{
+			ID:        n.ID,
+			Kind:      n.Kind,
+			Slug:      n.Slug,
+			Name:      n.Name,
+			Companies: n.Companies,
+			Children:  NewTaxonomyTree(n.Children),
+		})
+	}
+	return out
+}
+
+// Industry is the API representation of a taxonomy industry.
+type Industry struct {
+	ID       uuid.UUID  `json:"id"`
+	ParentID *uuid.UUID `json:"parent_id"`
+	Slug     string     `json:"slug"`
+	Name     string     `json:"name"`
+}
+
+// NewIndustry maps an industry model.
+func NewIndustry(i models.Industry) Industry {
+	return Industry{ID: i.ID, ParentID: i.ParentID, Slug: i.Slug, Name: i.Name}
+}
+
+// Region is the API representation of a region.
+type Region struct {
+	ID       uuid.UUID  `json:"id"`
+	ParentID *uuid.UUID `json:"parent_id"`
+	Kind     string     `json:"kind"`
+	Slug     string     `json:"slug"`
+	Name     string     `json:"name"`
+}
+
+// NewRegion maps a region model.
+func NewRegion(r models.Region) Region {
+	return Region{ID: r.ID, ParentID: r.ParentID, Kind: r.Kind, Slug: r.Slug, Name: r.Name}
+}
+
+// Page wraps a paginated listing.
+type Page[T any] struct {
+	Total int64 `json:"total"`
+	Items []T   `json:"items"`
+}
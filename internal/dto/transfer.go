@@ -0,0 +1,35 @@
+package dto
+
+import "crowdreview/internal/services"
+
+// ImportRowError is one rejected row of a bulk import.
+type ImportRowError struct {
+	Line   int    `json:"line"`
+	Domain string `json:"domain,omitempty"`
+	Error  string `json:"error"`
+}
+
+// ImportReport summarizes a bulk company import.
+type ImportReport struct {
+	DryRun  bool             `json:"dry_run"`
+	Rows    int              `json:"rows"`
+	Created int              `json:"created"`
+	Updated int              `json:"updated"`
+	Failed  int              `json:"failed"`
+	Errors  []ImportRowError `json:"errors"`
+}
+
+func NewImportReport(r services.ImportReport) ImportReport {
+	errs := make([]ImportRowError, 0, len(r.Errors))
+	for _, e := range r.Errors {
+		errs = append(errs, ImportRowError{Line: e.Line, Domain: e.Domain, Error: e.Error})
+	}
+	return ImportReport{
+		DryRun:  r.DryRun,
+		Rows:    r.Rows,
+		Created: r.Created,
+		Updated: r.Updated,
+		Failed:  r.Failed,
+		Errors:  errs,
+	}
+}
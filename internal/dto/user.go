@@ -0,0 +1,114 @@
+package dto
+
+import (
+	"time"
+
+	"crowdreview/internal/models"
+	"crowdreview/internal/services"
+
+	"github.com/google/uuid"
+)
+
+// PublicUser is what anyone may see about a reviewer.
+type PublicUser struct {
+	ID          uuid.UUID `json:"id"`
+	Username    string    `json:"username,omitempty"`
+	DisplayName string    `json:"display_name,omitempty"`
+	AvatarURL   string    `json:"avatar_url,omitempty"`
+}
+
+// NewPublicUser maps a user to its public representation.
+func NewPublicUser(u models.User) PublicUser {
+	profile := u.Profile()
+	return PublicUser{ID: u.ID, Username: u.Username, DisplayName: profile.DisplayName, AvatarURL: profile.AvatarURL}
+}
+
+// Account is the representation of a user returned to that same user.
+type Account struct {
+	ID                uuid.UUID `json:"id"`
+	Email             string    `json:"email"`
+	Username          string    `json:"username"`
+	Role              string    `json:"role"`
+	EmailVerified     bool      `json:"email_verified"`
+	GamificationScore int       `json:"gamification_score"`
+	Profile           Profile   `json:"profile"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// NewAccount maps a user to the owner's view of their account.
+func NewAccount(u models.User) Account {
+	return Account{
+		ID:                u.ID,
+		Email:             u.Email,
+		Username:          u.Username,
+		Role:              u.Role,
+		EmailVerified:     u.EmailVerifiedAt != nil,
+		GamificationScore: u.GamificationScore,
+		Profile:           NewProfile(u.Profile()),
+		CreatedAt:         u.CreatedAt,
+	}
+}
+
+// Profile holds the editable profile and privacy settings.
+type Profile struct {
+	DisplayName      string `json:"display_name"`
+	AvatarURL        string `json:"avatar_url"`
+	Bio              string `json:"bio"`
+	Locale           string `json:"locale"`
+	AnonymousReviews bool   `json:"anonymous_reviews"`
+}
+
+// NewProfile maps a user's profile settings.
+func NewProfile(p models.Profile) Profile {
+	return Profile(p)
+}
+
+// ReviewerPage is the public page of a reviewer.
+type ReviewerPage struct {
+	PublicUser
+	Bio               string              `json:"bio,omitempty"`
+	GamificationScore int                 `json:"gamification_score"`
+	Tier              string              `json:"tier"`
+	MemberSince       time.Time           `json:"member_since"`
+	Reviews           []Review            `json:"reviews"`
+	Achievements      []EarnedAchievement `json:"achievements"`
+}
+
+// EarnedAchievement is an achievement unlocked by a user.
+type EarnedAchievement struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Icon        string    `json:"icon"`
+	Points      int       `json:"points"`
+	EarnedAt    time.Time `json:"earned_at"`
+}
+
+// NewReviewerPage maps a public profile.
+func NewReviewerPage(p services.PublicProfile) ReviewerPage {
+	return ReviewerPage{
+		PublicUser:        NewPublicUser(p.User),
+		Bio:               p.User.Profile().Bio,
+		GamificationScore: p.User.GamificationScore,
+		Tier:              p.Tier.Name,
+		MemberSince:       p.User.CreatedAt,
+		Reviews:           NewReviews(p.Reviews),
+		Achievements:      NewEarnedAchievements(p.Achievements),
+	}
+}
+
+// NewEarnedAchievements maps a user's unlocked achievements.
+func NewEarnedAchievements(earned []models.UserAchievement) []EarnedAchievement {
+	out := make([]EarnedAchievement, 0, len(earned))
+	for _, ua := range earned {
+		out = append(out, EarnedAchievement{
+			ID:          ua.AchievementID,
+			Name:        ua.Achievement.Name,
+			Description: ua.Achievement.Description,
+			Icon:        ua.Achievement.Icon,
+			Points:      ua.Achievement.Points,
+			EarnedAt:    time.Unix(ua.EarnedAt, 0).UTC(),
+		})
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"crowdreview/internal/models"
+	"crowdreview/internal/services"
+
+	"github.com/google/uuid"
+)
+
+// Webhook is a webhook subscription. Secret is only filled when it was
+// just generated.
+type Webhook struct {
+	ID        uuid.UUID  `json:"id"`
+	CompanyID *uuid.UUID `json:"company_id"`
+	URL       string     `json:"url"`
+	Events    []string   `json:"events"`
+	Active    bool       `json:"active"`
+	Secret    string     `json:"secret,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// NewWebhook maps a subscription, revealing its secret only if asked.
+func NewWebhook(s models.WebhookSubscription, withSecret bool) Webhook {
+	out := Webhook{
+		ID:        s.ID,
+		CompanyID: s.CompanyID,
+		URL:       s.URL,
+		Events:    append([]string{}, s.Events...),
+		Active:    s.Active,
+		CreatedAt: s.CreatedAt,
+	}
+	if withSecret {
+		out.Secret = s.Secret
+	}
+	return out
+}
+
+// NewWebhooks maps subscriptions without their secrets.
+func NewWebhooks(list []models.WebhookSubscription) []Webhook {
+	out := make([]Webhook, 0, len(list))
+	for _, s := range list {
+		out = append(out, NewWebhook(s, false))
+	}
+	return out
+}
+
+// WebhookDelivery is one entry of a subscription's delivery log.
+type WebhookDelivery struct {
+	ID            uuid.UUID       `json:"id"`
+	EventID       uuid.UUID       `json:"event_id"`
+	Event         string          `json:"event"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	StatusCode    int             `json:"status_code,omitempty"`
+	LastError     string          `json:"last_error,omitempty"`
+	NextAttemptAt *time.Time      `json:"next_attempt_at,omitempty"`
+	DeliveredAt   *time.Time      `json:"delivered_at,omitempty"`
+	RedeliveryOf  *uuid.UUID      `json:"redelivery_of,omitempty"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// NewWebhookDelivery maps a delivery.
+func NewWebhookDelivery(d models.WebhookDelivery) WebhookDelivery {
+	return WebhookDelivery{
+		ID:            d.ID,
+		EventID:       d.EventID,
+		Event:         d.Event,
+		Status:        d.Status,
+		Attempts:      d.Attempts,
+		StatusCode:    d.StatusCode,
+		LastError:     d.LastError,
+		NextAttemptAt: d.NextAttemptAt,
+		DeliveredAt:   d.DeliveredAt,
+		RedeliveryOf:  d.RedeliveryOf,
+		Payload:       json.RawMessage(d.Payload),
+		CreatedAt:     d.CreatedAt,
+	}
+}
+
+// WebhookDeliveries is a page of a delivery log.
+type WebhookDeliveries struct {
+	Total int64             `json:"total"`
+	Items []WebhookDelivery `json:"items"`
+}
+
+// NewWebhookDeliveries maps a delivery log page.
+func NewWebhookDeliveries(p services.WebhookDeliveries) WebhookDeliveries {
+	out := WebhookDeliveries{Total: p.Total, Items: make([]WebhookDelivery, 0, len(p.Deliveries))}
+	for _, d := range p.Deliveries {
+		out.Items = append(out.Items, NewWebhookDelivery(d))
+	}
+	return out
+}
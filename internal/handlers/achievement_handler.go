@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"crowdreview/internal/achievements"
+	"crowdreview/internal/dto"
+	"crowdreview/internal/services"
+	"crowdreview/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AchievementHandler exposes the achievement catalog and its administration.
+type AchievementHandler struct {
+	service services.AchievementService
+}
+
+func NewAchievementHandler(service services.AchievementService) *AchievementHandler {
+	return &AchievementHandler{service: service}
+}
+
+type achievementRequest struct {
+	Name        *string                `json:"name"`
+	Description *string                `json:"description"`
+	Icon        *string                `json:"icon"`
+	Points      *int                   `json:"points"`
+	Criteria    *achievements.Criteria `json:"criteria"`
+}
+
+// Catalog lists every active achievement.
+func (h *AchievementHandler) Catalog(c *gin.Context) {
+	list, err := h.service.Catalog(c.Request.Context())
+	if err != nil {
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, dto.NewAchievements(list))
+}
+
+func (h *AchievementHandler) Create(c *gin.Context) {
+	var req achievementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	achievement, err := h.service.Create(c.Request.Context(), services.AchievementInput(req))
+	if err != nil {
+		writeAchievementError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusCreated, dto.NewAchievement(*achievement))
+}
+
+// Update changes only the fields present in the body.
+func (h *AchievementHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req achievementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	achievement, err := h.service.Update(c.Request.Context(), id, services.AchievementInput(req))
+	if err != nil {
+		writeAchievementError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, dto.NewAchievement(*achievement))
+}
+
+func (h *AchievementHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		writeAchievementError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, gin.H{"status": "deleted"})
+}
+
+// Seed adds the default catalog; ?overwrite=true also resets existing entries.
+func (h *AchievementHandler) Seed(c *gin.Context) {
+	report, err := h.service.Seed(c.Request.Context(), c.Query("overwrite") == "true")
+	if err != nil {
+		writeAchievementError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, gin.H{
+		"created": report.Created,
+		"updated": report.Updated,
+		"skipped": report.Skipped,
+		"retired": report.Retired,
+	})
+}
+
+func writeAchievementError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrAchievementNotFound):
+		utils.JSONError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrInvalidAchievement):
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+	default:
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"crowdreview/internal/services"
+	"crowdreview/pkg/utils"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// activityHeartbeat keeps idle streams from being closed by proxies.
+const activityHeartbeat = 25 * time.Second
+
+// ActivityHandler streams live moderation activity over Server-Sent Events.
+type ActivityHandler struct {
+	service services.ActivityService
+}
+
+func NewActivityHandler(service services.ActivityService) *ActivityHandler {
+	return &ActivityHandler{service: service}
+}
+
+// Stream sends each event the viewer may see as an SSE event named after
+// its type, with the event as JSON data, plus a ping every 25 seconds. The
+// stream ends when the service closes it because the viewer lost access.
+func (h *ActivityHandler) Stream(c *gin.Context) {
+	token, _ := c.MustGet("claims").(*utils.TokenClaims)
+	events, stop, err := h.service.Subscribe(c.Request.Context(), viewerFromContext(c), token)
+	if err != nil {
+		writeActivityError(c, err)
+		return
+	}
+	defer stop()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Render(-1, sse.Event{Event: "ready", Data: gin.H{"heartbeat_seconds": int(activityHeartbeat.Seconds())}})
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(activityHeartbeat)
+	defer heartbeat.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.Render(-1, sse.Event{Id: event.ID.String(), Event: event.Type, Data: event})
+		case now := <-heartbeat.C:
+			c.Render(-1, sse.Event{Event: "ping", Data: gin.H{"at": now.UTC()}})
+		}
+		return true
+	})
+}
+
+func writeActivityError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrActivityForbidden):
+		utils.JSONError(c, http.StatusForbidden, err.Error())
+	default:
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+	}
+}
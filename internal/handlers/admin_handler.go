@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
+	"crowdreview/internal/dto"
 	"crowdreview/internal/services"
 	"crowdreview/pkg/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // AdminHandler exposes admin-only endpoints.
@@ -53,3 +56,129 @@ func (h *AdminHandler) Respond(c *gin.Context) {
 	}
 	utils.JSONSuccess(c, http.StatusOK, gin.H{"status": "updated"})
 }
+
+// ClaimReview reserves a review for the calling moderator.
+func (h *AdminHandler) ClaimReview(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "invalid review id")
+		return
+	}
+	review, err := h.service.ClaimReview(c.Request.Context(), viewerFromContext(c).UserID, id)
+	if err != nil {
+		writeClaimError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, review)
+}
+
+// ReleaseReview gives up the calling moderator's claim.
+func (h *AdminHandler) ReleaseReview(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "invalid review id")
+		return
+	}
+	if err := h.service.ReleaseReview(c.Request.Context(), viewerFromContext(c).UserID, id); err != nil {
+		writeClaimError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, gin.H{"status": "released"})
+}
+
+func writeClaimError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrReviewNotFound):
+		utils.JSONError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrReviewClaimed), errors.Is(err, services.ErrReviewNotClaimable):
+		utils.JSONError(c, http.StatusConflict, err.Error())
+	default:
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+	}
+}
+
+type securityEventsRequest struct {
+	Type   string `form:"type"`
+	UserID string `form:"user_id"`
+	IP     string `form:"ip"`
+	Limit  int    `form:"limit"`
+	Offset int    `form:"offset"`
+}
+
+// SecurityEvents lists failed logins, lockouts and new-IP logins.
+func (h *AdminHandler) SecurityEvents(c *gin.Context) {
+	var req securityEventsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	input := services.SecurityEventsInput{Type: req.Type, IPAddress: req.IP, Limit: req.Limit, Offset: req.Offset}
+	if req.UserID != "" {
+		id, err := uuid.Parse(req.UserID)
+		if err != nil {
+			utils.JSONError(c, http.StatusBadRequest, "invalid user id")
+			return
+		}
+		input.UserID = &id
+	}
+	events, total, err := h.service.SecurityEvents(c.Request.Context(), input)
+	if err != nil {
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, dto.Page[dto.SecurityEvent]{Total: total, Items: dto.NewSecurityEvents(events)})
+}
+
+// UserAccess shows a user's role, permission grants and effective permissions.
+func (h *AdminHandler) UserAccess(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "invalid user id")
+		return
+	}
+	access, err := h.service.UserAccess(c.Request.Context(), id)
+	if err != nil {
+		writeAccessError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, access)
+}
+
+type userAccessRequest struct {
+	Role   string          `json:"role" binding:"required"`
+	Grants map[string]bool `json:"grants"`
+}
+
+// SetUserAccess replaces a user's role and grants.
+func (h *AdminHandler) SetUserAccess(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "invalid user id")
+		return
+	}
+	var req userAccessRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	input := services.UserAccessInput{Role: req.Role, Grants: req.Grants}
+	access, err := h.service.SetUserAccess(c.Request.Context(), viewerFromContext(c).UserID, id, input)
+	if err != nil {
+		writeAccessError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, access)
+}
+
+func writeAccessError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		utils.JSONError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrUnknownRole), errors.Is(err, services.ErrUnknownPermission):
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrSelfAccessChange):
+		utils.JSONError(c, http.StatusForbidden, err.Error())
+	default:
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+	}
+}
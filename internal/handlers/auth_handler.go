@@ -1,9 +1,13 @@
 package handlers
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	"crowdreview/config"
+	"crowdreview/internal/dto"
 	"crowdreview/internal/services"
 	"crowdreview/pkg/utils"
 
@@ -38,7 +42,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 	utils.JSONSuccess(c, http.StatusCreated, gin.H{
-		"user":          user,
+		"user":          dto.NewAccount(*user),
 		"access_token":  access,
 		"refresh_token": refresh,
 	})
@@ -55,18 +59,78 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		utils.JSONError(c, http.StatusBadRequest, err.Error())
 		return
 	}
-	user, access, refresh, err := h.auth.Login(c.Request.Context(), req.Email, req.Password)
+	user, access, refresh, err := h.auth.Login(c.Request.Context(), req.Email, req.Password, c.ClientIP())
+	if writeTwoFactorChallenge(c, err) {
+		return
+	}
+	if writeLoginBlocked(c, err) {
+		return
+	}
+	if err != nil {
+		utils.JSONError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, gin.H{
+		"user":          dto.NewAccount(*user),
+		"access_token":  access,
+		"refresh_token": refresh,
+	})
+}
+
+type twoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// LoginTwoFactor is the second step of a login for accounts with 2FA; code
+// may be a TOTP code or a recovery code.
+func (h *AuthHandler) LoginTwoFactor(c *gin.Context) {
+	var req twoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	user, access, refresh, err := h.auth.CompleteLogin(c.Request.Context(), req.ChallengeToken, req.Code, c.ClientIP())
+	if writeLoginBlocked(c, err) {
+		return
+	}
 	if err != nil {
 		utils.JSONError(c, http.StatusUnauthorized, err.Error())
 		return
 	}
 	utils.JSONSuccess(c, http.StatusOK, gin.H{
-		"user":          user,
+		"user":          dto.NewAccount(*user),
 		"access_token":  access,
 		"refresh_token": refresh,
 	})
 }
 
+// writeTwoFactorChallenge answers a first-factor login that still needs a
+// second factor with the challenge for /auth/login/2fa.
+func writeTwoFactorChallenge(c *gin.Context, err error) bool {
+	var challenge *services.TwoFactorRequiredError
+	if !errors.As(err, &challenge) {
+		return false
+	}
+	utils.JSONSuccess(c, http.StatusOK, gin.H{
+		"two_factor_required": true,
+		"challenge_token":     challenge.ChallengeToken,
+		"expires_in":          int(challenge.ExpiresIn.Seconds()),
+	})
+	return true
+}
+
+// writeLoginBlocked answers 429 with Retry-After for throttled logins.
+func writeLoginBlocked(c *gin.Context, err error) bool {
+	var blocked *services.LoginBlockedError
+	if !errors.As(err, &blocked) {
+		return false
+	}
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(blocked.RetryAfter.Seconds()))))
+	utils.JSONError(c, http.StatusTooManyRequests, err.Error())
+	return true
+}
+
 type refreshRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
@@ -77,13 +141,12 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 		utils.JSONError(c, http.StatusBadRequest, err.Error())
 		return
 	}
-	userID, err := h.auth.ValidateRefreshToken(req.RefreshToken)
-	if err != nil {
-		utils.JSONError(c, http.StatusUnauthorized, "invalid refresh token")
-		return
-	}
-	access, refresh, err := h.auth.Refresh(c.Request.Context(), userID)
+	access, refresh, err := h.auth.Refresh(c.Request.Context(), req.RefreshToken)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) || errors.Is(err, services.ErrRefreshTokenReused) {
+			utils.JSONError(c, http.StatusUnauthorized, err.Error())
+			return
+		}
 		utils.JSONError(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -92,3 +155,190 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 		"refresh_token": refresh,
 	})
 }
+
+type logoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Logout revokes the calling access token and, when given, the refresh token.
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req logoutRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.JSONError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	claims := c.MustGet("claims").(*utils.TokenClaims)
+	if err := h.auth.Logout(c.Request.Context(), claims, req.RefreshToken); err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) {
+			utils.JSONError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// LogoutAll ends every session of the caller on every device.
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	claims := c.MustGet("claims").(*utils.TokenClaims)
+	ctx := c.Request.Context()
+	if err := h.auth.LogoutAll(ctx, viewerFromContext(c).UserID); err != nil {
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	// The user-wide marker has second precision; revoke this token explicitly too.
+	if err := h.auth.Logout(ctx, claims, ""); err != nil {
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+type tokenRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req tokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.auth.VerifyEmail(c.Request.Context(), req.Token); err != nil {
+		if errors.Is(err, services.ErrInvalidEmailToken) {
+			utils.JSONError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ResendVerification mails a fresh verification link to the caller.
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	if err := h.auth.SendVerification(c.Request.Context(), viewerFromContext(c).UserID); err != nil {
+		if errors.Is(err, services.ErrEmailAlreadyVerified) {
+			utils.JSONError(c, http.StatusConflict, err.Error())
+			return
+		}
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+type forgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ForgotPassword always answers 202 so callers cannot probe for accounts.
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req forgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.auth.RequestPasswordReset(c.Request.Context(), req.Email); err != nil {
+		_ = c.Error(err)
+	}
+	c.Status(http.StatusAccepted)
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req resetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.auth.ResetPassword(c.Request.Context(), req.Token, req.Password); err != nil {
+		if errors.Is(err, services.ErrInvalidEmailToken) {
+			utils.JSONError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// EnrollTOTP returns a new secret and its otpauth:// URI for the caller's
+// authenticator app. 2FA stays off until ConfirmTOTP.
+func (h *AuthHandler) EnrollTOTP(c *gin.Context) {
+	enrollment, err := h.auth.EnrollTOTP(c.Request.Context(), viewerFromContext(c).UserID)
+	if err != nil {
+		writeTwoFactorError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, gin.H{
+		"secret":           enrollment.Secret,
+		"provisioning_uri": enrollment.URI,
+	})
+}
+
+type twoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// ConfirmTOTP enables 2FA and returns the recovery codes once.
+func (h *AuthHandler) ConfirmTOTP(c *gin.Context) {
+	var req twoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	codes, err := h.auth.ConfirmTOTP(c.Request.Context(), viewerFromContext(c).UserID, req.Code)
+	if err != nil {
+		writeTwoFactorError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+func (h *AuthHandler) DisableTOTP(c *gin.Context) {
+	var req twoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.auth.DisableTOTP(c.Request.Context(), viewerFromContext(c).UserID, req.Code); err != nil {
+		writeTwoFactorError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req twoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	codes, err := h.auth.RegenerateRecoveryCodes(c.Request.Context(), viewerFromContext(c).UserID, req.Code)
+	if err != nil {
+		writeTwoFactorError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+func writeTwoFactorError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidTwoFactorCode):
+		utils.JSONError(c, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, services.ErrTwoFactorEnabled), errors.Is(err, services.ErrTwoFactorNotEnabled):
+		utils.JSONError(c, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrTwoFactorMandatory):
+		utils.JSONError(c, http.StatusForbidden, err.Error())
+	default:
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+	}
+}
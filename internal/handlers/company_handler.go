@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
+	"crowdreview/internal/dto"
 	"crowdreview/internal/models"
 	"crowdreview/internal/services"
 	"crowdreview/pkg/utils"
@@ -20,13 +22,43 @@ func NewCompanyHandler(service services.CompanyService) *CompanyHandler {
 	return &CompanyHandler{service: service}
 }
 
+// companyRequest is the body of company create/update calls. Industry and
+// location may be given as taxonomy IDs or as free text matching an existing
+// industry slug/name and "City, State, Country" region path.
+type companyRequest struct {
+	Name        string     `json:"name"`
+	Domain      string     `json:"domain"`
+	IndustryID  *uuid.UUID `json:"industry_id"`
+	Industry    string     `json:"industry"`
+	RegionID    *uuid.UUID `json:"region_id"`
+	Location    string     `json:"location"`
+	Description string     `json:"description"`
+	Website     string     `json:"website"`
+
+	// AllowDuplicates confirms creation despite similar existing companies.
+	AllowDuplicates bool `json:"allow_duplicates"`
+}
+
+func (r companyRequest) model() models.Company {
+	return models.Company{
+		Name:        r.Name,
+		Domain:      r.Domain,
+		IndustryID:  r.IndustryID,
+		Industry:    r.Industry,
+		RegionID:    r.RegionID,
+		Location:    r.Location,
+		Description: r.Description,
+		Website:     r.Website,
+	}
+}
+
 func (h *CompanyHandler) List(c *gin.Context) {
 	companies, err := h.service.List(c.Request.Context())
 	if err != nil {
 		utils.JSONError(c, http.StatusInternalServerError, err.Error())
 		return
 	}
-	utils.JSONSuccess(c, http.StatusOK, companies)
+	utils.JSONSuccess(c, http.StatusOK, dto.NewCompanies(companies))
 }
 
 func (h *CompanyHandler) Get(c *gin.Context) {
@@ -36,25 +68,42 @@ func (h *CompanyHandler) Get(c *gin.Context) {
 		return
 	}
 	company, err := h.service.Get(c.Request.Context(), id)
+	var merged *services.CompanyMergedError
+	if errors.As(err, &merged) {
+		c.Redirect(http.StatusMovedPermanently, "/companies/"+merged.Into.String())
+		return
+	}
 	if err != nil {
 		utils.JSONError(c, http.StatusNotFound, err.Error())
 		return
 	}
-	utils.JSONSuccess(c, http.StatusOK, company)
+	snapshot, err := h.service.Ratings(c.Request.Context(), id)
+	if err != nil {
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	out := dto.NewCompany(*company)
+	out.Ratings = &snapshot
+	utils.JSONSuccess(c, http.StatusOK, out)
 }
 
 func (h *CompanyHandler) Create(c *gin.Context) {
-	var input models.Company
-	if err := c.ShouldBindJSON(&input); err != nil {
+	var req companyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.JSONError(c, http.StatusBadRequest, err.Error())
 		return
 	}
-	company, err := h.service.Create(c.Request.Context(), input)
+	company, err := h.service.Create(c.Request.Context(), req.model(), services.CreateCompanyOptions{AllowDuplicates: req.AllowDuplicates})
+	var duplicate *services.DuplicateCompanyError
+	if errors.As(err, &duplicate) {
+		utils.JSONErrorData(c, http.StatusConflict, duplicate.Error(), gin.H{"duplicates": dto.NewDuplicateMatches(duplicate.Matches)})
+		return
+	}
 	if err != nil {
 		utils.JSONError(c, http.StatusBadRequest, err.Error())
 		return
 	}
-	utils.JSONSuccess(c, http.StatusCreated, company)
+	utils.JSONSuccess(c, http.StatusCreated, dto.NewCompany(*company))
 }
 
 func (h *CompanyHandler) Update(c *gin.Context) {
@@ -63,15 +112,137 @@ func (h *CompanyHandler) Update(c *gin.Context) {
 		utils.JSONError(c, http.StatusBadRequest, "invalid id")
 		return
 	}
-	var input models.Company
-	if err := c.ShouldBindJSON(&input); err != nil {
+	var req companyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	company, err := h.service.Update(c.Request.Context(), id, req.model())
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, dto.NewCompany(*company))
+}
+
+type duplicatesRequest struct {
+	Name   string `form:"name"`
+	Domain string `form:"domain"`
+}
+
+// Duplicates suggests existing companies resembling a name and/or domain.
+func (h *CompanyHandler) Duplicates(c *gin.Context) {
+	var req duplicatesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Name == "" && req.Domain == "" {
+		utils.JSONError(c, http.StatusBadRequest, "name or domain is required")
+		return
+	}
+	matches, err := h.service.FindDuplicates(c.Request.Context(), req.Name, req.Domain)
+	if err != nil {
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, dto.NewDuplicateMatches(matches))
+}
+
+type mergeRequest struct {
+	Into string `json:"into" binding:"required"`
+}
+
+// Merge folds the company in the path into the company given as "into".
+func (h *CompanyHandler) Merge(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req mergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	into, err := uuid.Parse(req.Into)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "invalid target id")
+		return
+	}
+	if err := h.service.Merge(c.Request.Context(), id, into); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, gin.H{"status": "merged", "into": into})
+}
+
+type aliasRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+func (h *CompanyHandler) AddAlias(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req aliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.JSONError(c, http.StatusBadRequest, err.Error())
 		return
 	}
-	company, err := h.service.Update(c.Request.Context(), id, input)
+	alias, err := h.service.AddAlias(c.Request.Context(), id, req.Name)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	utils.JSONSuccess(c, http.StatusCreated, dto.Alias{ID: alias.ID, Name: alias.Name})
+}
+
+func (h *CompanyHandler) DeleteAlias(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+	aliasID, err := uuid.Parse(c.Param("aliasId"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "invalid alias id")
+		return
+	}
+	if err := h.service.DeleteAlias(c.Request.Context(), id, aliasID); err != nil {
+		utils.JSONError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, gin.H{"status": "deleted"})
+}
+
+type ownerRequest struct {
+	UserID *uuid.UUID `json:"user_id"`
+}
+
+// SetOwner assigns the user who manages the company's webhooks; a null
+// user_id removes the owner.
+func (h *CompanyHandler) SetOwner(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req ownerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.JSONError(c, http.StatusBadRequest, err.Error())
 		return
 	}
-	utils.JSONSuccess(c, http.StatusOK, company)
+	if err := h.service.SetOwner(c.Request.Context(), id, req.UserID); err != nil {
+		switch {
+		case errors.Is(err, services.ErrCompanyNotFound), errors.Is(err, services.ErrUserNotFound):
+			utils.JSONError(c, http.StatusNotFound, err.Error())
+		default:
+			utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, gin.H{"company_id": id, "owner_id": req.UserID})
 }
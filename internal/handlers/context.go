@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"crowdreview/internal/services"
+	"crowdreview/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// viewerFromContext builds the services.Viewer set by the auth middleware.
+// Requests without a token yield an anonymous viewer.
+func viewerFromContext(c *gin.Context) services.Viewer {
+	var viewer services.Viewer
+	if id, ok := c.Get("userID"); ok {
+		viewer.UserID, _ = id.(uuid.UUID)
+	}
+	if role, ok := c.Get("role"); ok {
+		viewer.Role, _ = role.(string)
+	}
+	if v, ok := c.Get("claims"); ok {
+		if claims, ok := v.(*utils.TokenClaims); ok {
+			// Tokens carry the effective permissions; an empty list must not
+			// fall back to the role's defaults.
+			viewer.Permissions = append([]string{}, claims.Permissions...)
+			viewer.MFA = claims.MFA
+		}
+	}
+	return viewer
+}
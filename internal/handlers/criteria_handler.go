@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"net/http"
+
+	"crowdreview/internal/dto"
+	"crowdreview/internal/models"
+	"crowdreview/internal/services"
+	"crowdreview/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CriteriaHandler manages rating criteria endpoints.
+type CriteriaHandler struct {
+	service services.CriteriaService
+}
+
+func NewCriteriaHandler(service services.CriteriaService) *CriteriaHandler {
+	return &CriteriaHandler{service: service}
+}
+
+type criterionRequest struct {
+	Industry string `json:"industry"`
+	Key      string `json:"key"`
+	Label    string `json:"label"`
+	Position int    `json:"position"`
+}
+
+func (r criterionRequest) model() models.RatingCriterion {
+	return models.RatingCriterion{Industry: r.Industry, Key: r.Key, Label: r.Label, Position: r.Position}
+}
+
+func (h *CriteriaHandler) List(c *gin.Context) {
+	criteria, err := h.service.List(c.Request.Context())
+	if err != nil {
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, dto.NewCriteria(criteria))
+}
+
+// ForCompany lists the criteria a reviewer of the company may score.
+func (h *CriteriaHandler) ForCompany(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+	criteria, err := h.service.ForCompany(c.Request.Context(), id)
+	if err != nil {
+		utils.JSONError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, dto.NewCriteria(criteria))
+}
+
+func (h *CriteriaHandler) Create(c *gin.Context) {
+	var req criterionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	criterion, err := h.service.Create(c.Request.Context(), req.model())
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	utils.JSONSuccess(c, http.StatusCreated, dto.NewCriterion(*criterion))
+}
+
+func (h *CriteriaHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req criterionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	criterion, err := h.service.Update(c.Request.Context(), id, req.model())
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, dto.NewCriterion(*criterion))
+}
+
+func (h *CriteriaHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, gin.H{"status": "deleted"})
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"crowdreview/internal/dto"
+	"crowdreview/internal/models"
+	"crowdreview/internal/services"
+	"crowdreview/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// DirectoryHandler exposes the company directory and taxonomy administration.
+type DirectoryHandler struct {
+	service services.DirectoryService
+}
+
+func NewDirectoryHandler(service services.DirectoryService) *DirectoryHandler {
+	return &DirectoryHandler{service: service}
+}
+
+func (h *DirectoryHandler) Industries(c *gin.Context) {
+	tree, err := h.service.IndustryTree(c.Request.Context())
+	if err != nil {
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, dto.NewTaxonomyTree(tree))
+}
+
+func (h *DirectoryHandler) Regions(c *gin.Context) {
+	tree, err := h.service.RegionTree(c.Request.Context())
+	if err != nil {
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, dto.NewTaxonomyTree(tree))
+}
+
+type browseRequest struct {
+	Industry string `form:"industry"`
+	Region   string `form:"region"`
+	Limit    int    `form:"limit"`
+	Offset   int    `form:"offset"`
+}
+
+// Companies browses companies by industry slug and/or region ID.
+func (h *DirectoryHandler) Companies(c *gin.Context) {
+	var req browseRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	input := services.BrowseInput{Industry: req.Industry, Limit: req.Limit, Offset: req.Offset}
+	if req.Region != "" {
+		id, err := uuid.Parse(req.Region)
+		if err != nil {
+			utils.JSONError(c, http.StatusBadRequest, "invalid region id")
+			return
+		}
+		input.RegionID = &id
+	}
+	companies, total, err := h.service.Browse(c.Request.Context(), input)
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, dto.Page[dto.Company]{Total: total, Items: dto.NewCompanies(companies)})
+}
+
+type industryRequest struct {
+	ParentID *uuid.UUID `json:"parent_id"`
+	Slug     string     `json:"slug"`
+	Name     string     `json:"name"`
+}
+
+func (h *DirectoryHandler) CreateIndustry(c *gin.Context) {
+	var req industryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	industry, err := h.service.CreateIndustry(c.Request.Context(), models.Industry{ParentID: req.ParentID, Slug: req.Slug, Name: req.Name})
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	utils.JSONSuccess(c, http.StatusCreated, dto.NewIndustry(*industry))
+}
+
+type industryUpdateRequest struct {
+	Name     string          `json:"name"`
+	ParentID json.RawMessage `json:"parent_id"` // absent keeps the parent, null moves to the root
+}
+
+func (h *DirectoryHandler) UpdateIndustry(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req industryUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	input := services.IndustryUpdate{Name: req.Name, Move: len(req.ParentID) > 0}
+	if input.Move {
+		if err := json.Unmarshal(req.ParentID, &input.ParentID); err != nil {
+			utils.JSONError(c, http.StatusBadRequest, "invalid parent_id")
+			return
+		}
+	}
+	industry, err := h.service.UpdateIndustry(c.Request.Context(), id, input)
+	if err != nil {
+		writeDirectoryError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, dto.NewIndustry(*industry))
+}
+
+func (h *DirectoryHandler) DeleteIndustry(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+	if err := h.service.DeleteIndustry(c.Request.Context(), id); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, gin.H{"status": "deleted"})
+}
+
+type regionRequest struct {
+	ParentID *uuid.UUID `json:"parent_id"`
+	Kind     string     `json:"kind"`
+	Name     string     `json:"name"`
+}
+
+func (h *DirectoryHandler) CreateRegion(c *gin.Context) {
+	var req regionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	region, err := h.service.CreateRegion(c.Request.Context(), models.Region{ParentID: req.ParentID, Kind: req.Kind, Name: req.Name})
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	utils.JSONSuccess(c, http.StatusCreated, dto.NewRegion(*region))
+}
+
+func (h *DirectoryHandler) UpdateRegion(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req regionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	region, err := h.service.UpdateRegion(c.Request.Context(), id, models.Region{Name: req.Name})
+	if err != nil {
+		writeDirectoryError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, dto.NewRegion(*region))
+}
+
+func (h *DirectoryHandler) DeleteRegion(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+	if err := h.service.DeleteRegion(c.Request.Context(), id); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, gin.H{"status": "deleted"})
+}
+
+func writeDirectoryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrIndustryNotFound), errors.Is(err, services.ErrRegionNotFound):
+		utils.JSONError(c, http.StatusNotFound, err.Error())
+	default:
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+	}
+}
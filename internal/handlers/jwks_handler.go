@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"crowdreview/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWKSHandler publishes the public signing keys so other services can
+// verify CrowdReview access tokens.
+type JWKSHandler struct {
+	keys services.KeyService
+}
+
+func NewJWKSHandler(keys services.KeyService) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// JWKS serves the RFC 7517 key set as-is, without the usual data envelope,
+// since verifiers expect the standard document.
+func (h *JWKSHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keys.KeySet().JWKS(time.Now()))
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"crowdreview/internal/dto"
+	"crowdreview/internal/services"
+	"crowdreview/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LeaderboardHandler exposes reviewer rankings.
+type LeaderboardHandler struct {
+	service services.LeaderboardService
+}
+
+func NewLeaderboardHandler(service services.LeaderboardService) *LeaderboardHandler {
+	return &LeaderboardHandler{service: service}
+}
+
+type leaderboardRequest struct {
+	Scope    string `form:"scope"`
+	Industry string `form:"industry"`
+	Month    string `form:"month"`
+	Limit    int    `form:"limit"`
+	Offset   int    `form:"offset"`
+}
+
+// List pages through the global, industry or monthly leaderboard.
+func (h *LeaderboardHandler) List(c *gin.Context) {
+	var req leaderboardRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, err := h.service.Page(c.Request.Context(), services.LeaderboardInput(req))
+	switch {
+	case errors.Is(err, services.ErrInvalidLeaderboard):
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, services.ErrLeaderboardUnavailable):
+		utils.JSONError(c, http.StatusServiceUnavailable, err.Error())
+		return
+	case err != nil:
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, dto.NewLeaderboard(page))
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"crowdreview/internal/dto"
+	"crowdreview/internal/models"
+	"crowdreview/internal/services"
+	"crowdreview/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// NotificationHandler exposes the caller's notification inbox and
+// preferences.
+type NotificationHandler struct {
+	service services.NotificationService
+}
+
+func NewNotificationHandler(service services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{service: service}
+}
+
+type inboxRequest struct {
+	Unread bool `form:"unread"`
+	Limit  int  `form:"limit"`
+	Offset int  `form:"offset"`
+}
+
+// List pages through the inbox, newest first.
+func (h *NotificationHandler) List(c *gin.Context) {
+	var req inboxRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	inbox, err := h.service.Inbox(c.Request.Context(), viewerFromContext(c).UserID, req.Unread, req.Limit, req.Offset)
+	if err != nil {
+		writeNotificationError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, dto.NewInbox(inbox))
+}
+
+type markReadRequest struct {
+	Read *bool `json:"read" binding:"required"`
+}
+
+// Update marks a notification read or unread.
+func (h *NotificationHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req markReadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.service.MarkRead(c.Request.Context(), viewerFromContext(c).UserID, id, *req.Read); err != nil {
+		writeNotificationError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, gin.H{"id": id, "read": *req.Read})
+}
+
+func (h *NotificationHandler) ReadAll(c *gin.Context) {
+	marked, err := h.service.MarkAllRead(c.Request.Context(), viewerFromContext(c).UserID)
+	if err != nil {
+		writeNotificationError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, gin.H{"marked": marked})
+}
+
+func (h *NotificationHandler) Preferences(c *gin.Context) {
+	prefs, err := h.service.Preferences(c.Request.Context(), viewerFromContext(c).UserID)
+	if err != nil {
+		writeNotificationError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, prefs)
+}
+
+// UpdatePreferences changes only the types and channels present in the
+// body, e.g. {"review_approved": {"email": true}}.
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	var req models.NotificationPrefs
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	prefs, err := h.service.UpdatePreferences(c.Request.Context(), viewerFromContext(c).UserID, req)
+	if err != nil {
+		writeNotificationError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, prefs)
+}
+
+func writeNotificationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotificationNotFound), errors.Is(err, services.ErrUserNotFound):
+		utils.JSONError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrInvalidPreferences):
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+	default:
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"crowdreview/internal/dto"
+	"crowdreview/internal/services"
+	"crowdreview/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OIDCProviders lists the social login providers the frontend can offer.
+func (h *AuthHandler) OIDCProviders(c *gin.Context) {
+	utils.JSONSuccess(c, http.StatusOK, gin.H{"providers": h.auth.OIDCProviders()})
+}
+
+// OIDCAuthorize returns the provider URL the browser should be sent to.
+func (h *AuthHandler) OIDCAuthorize(c *gin.Context) {
+	authURL, err := h.auth.StartOIDC(c.Request.Context(), c.Param("provider"))
+	if errors.Is(err, services.ErrUnknownProvider) {
+		utils.JSONError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		utils.JSONError(c, http.StatusBadGateway, err.Error())
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, gin.H{"authorization_url": authURL})
+}
+
+type oidcCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+// OIDCCallback finishes a social login with the code and state the provider
+// redirected back with. The response matches /auth/login.
+func (h *AuthHandler) OIDCCallback(c *gin.Context) {
+	var req oidcCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	user, access, refresh, err := h.auth.CompleteOIDC(c.Request.Context(), c.Param("provider"), req.Code, req.State, c.ClientIP())
+	if writeTwoFactorChallenge(c, err) || writeLoginBlocked(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, services.ErrUnknownProvider):
+		utils.JSONError(c, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, services.ErrOIDCEmailConflict), errors.Is(err, services.ErrOIDCAccountUnverified):
+		utils.JSONError(c, http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, services.ErrInvalidOIDCState), errors.Is(err, services.ErrOIDCEmailMissing):
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		utils.JSONError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, gin.H{
+		"user":          dto.NewAccount(*user),
+		"access_token":  access,
+		"refresh_token": refresh,
+	})
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"crowdreview/internal/dto"
+	"crowdreview/internal/services"
+	"crowdreview/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PointsHandler lets admins audit and repair users' points ledgers.
+type PointsHandler struct {
+	service services.PointsService
+}
+
+func NewPointsHandler(service services.PointsService) *PointsHandler {
+	return &PointsHandler{service: service}
+}
+
+type pointsHistoryRequest struct {
+	Limit  int `form:"limit"`
+	Offset int `form:"offset"`
+}
+
+// History pages through a user's ledger, most recent first.
+func (h *PointsHandler) History(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "invalid user id")
+		return
+	}
+	var req pointsHistoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	history, err := h.service.History(c.Request.Context(), id, req.Limit, req.Offset)
+	if err != nil {
+		writePointsError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, dto.NewPointsHistory(history))
+}
+
+// Recompute resets the user's score to the sum of their ledger.
+func (h *PointsHandler) Recompute(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "invalid user id")
+		return
+	}
+	changed, err := h.service.Recompute(c.Request.Context(), &id)
+	if err != nil {
+		writePointsError(c, err)
+		return
+	}
+	history, err := h.service.History(c.Request.Context(), id, 1, 0)
+	if err != nil {
+		writePointsError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, gin.H{"corrected": changed > 0, "score": history.Score})
+}
+
+func writePointsError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		utils.JSONError(c, http.StatusNotFound, err.Error())
+	default:
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+	}
+}
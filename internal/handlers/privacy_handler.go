@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"crowdreview/internal/dto"
+	"crowdreview/internal/services"
+	"crowdreview/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PrivacyHandler serves LGPD data subject requests for the caller.
+type PrivacyHandler struct {
+	service services.PrivacyService
+}
+
+func NewPrivacyHandler(service services.PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{service: service}
+}
+
+// Export downloads everything stored about the caller as a JSON file.
+func (h *PrivacyHandler) Export(c *gin.Context) {
+	export, err := h.service.Export(c.Request.Context(), viewerFromContext(c).UserID)
+	if errors.Is(err, services.ErrUserNotFound) {
+		utils.JSONError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	now := time.Now().UTC()
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="crowdreview-export-%s.json"`, now.Format("20060102")))
+	c.Header("Cache-Control", "no-store")
+	c.IndentedJSON(http.StatusOK, dto.NewDataExport(*export, now))
+}
+
+type deleteAccountRequest struct {
+	Confirm string `json:"confirm" binding:"required"`
+}
+
+// Delete erases the caller's account; confirm must be their username.
+func (h *PrivacyHandler) Delete(c *gin.Context) {
+	var req deleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	err := h.service.DeleteAccount(c.Request.Context(), viewerFromContext(c).UserID, req.Confirm)
+	switch {
+	case errors.Is(err, services.ErrDeletionNotConfirmed):
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, services.ErrUserNotFound):
+		utils.JSONError(c, http.StatusNotFound, err.Error())
+		return
+	case err != nil:
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, gin.H{"status": "deleted"})
+}
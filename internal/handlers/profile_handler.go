@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"crowdreview/internal/dto"
+	"crowdreview/internal/services"
+	"crowdreview/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProfileHandler exposes the caller's profile and public reviewer pages.
+type ProfileHandler struct {
+	service services.ProfileService
+}
+
+func NewProfileHandler(service services.ProfileService) *ProfileHandler {
+	return &ProfileHandler{service: service}
+}
+
+func (h *ProfileHandler) Me(c *gin.Context) {
+	user, err := h.service.Me(c.Request.Context(), viewerFromContext(c).UserID)
+	if err != nil {
+		writeProfileError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, dto.NewAccount(*user))
+}
+
+type updateProfileRequest struct {
+	DisplayName      *string `json:"display_name"`
+	AvatarURL        *string `json:"avatar_url"`
+	Bio              *string `json:"bio"`
+	Locale           *string `json:"locale"`
+	AnonymousReviews *bool   `json:"anonymous_reviews"`
+}
+
+// UpdateMe changes only the fields present in the body.
+func (h *ProfileHandler) UpdateMe(c *gin.Context) {
+	var req updateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	user, err := h.service.UpdateMe(c.Request.Context(), viewerFromContext(c).UserID, services.ProfileInput(req))
+	if err != nil {
+		writeProfileError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, dto.NewAccount(*user))
+}
+
+// Show renders a reviewer's public page.
+func (h *ProfileHandler) Show(c *gin.Context) {
+	profile, err := h.service.PublicProfile(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		writeProfileError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, dto.NewReviewerPage(profile))
+}
+
+func writeProfileError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		utils.JSONError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrInvalidProfile):
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+	default:
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
+	"crowdreview/internal/dto"
 	"crowdreview/internal/services"
 	"crowdreview/pkg/utils"
 
@@ -20,11 +22,13 @@ func NewReviewHandler(service services.ReviewService) *ReviewHandler {
 }
 
 type createReviewRequest struct {
-	CompanyID   string `json:"company_id" binding:"required"`
-	Rating      int    `json:"rating" binding:"required"`
-	Title       string `json:"title"`
-	Content     string `json:"content" binding:"required"`
-	GeoLocation string `json:"geo_location"`
+	CompanyID   string         `json:"company_id" binding:"required"`
+	Rating      int            `json:"rating" binding:"required"`
+	Title       string         `json:"title"`
+	Content     string         `json:"content" binding:"required"`
+	GeoLocation string         `json:"geo_location"`
+	Scores      map[string]int `json:"scores"`
+	Anonymous   *bool          `json:"anonymous"` // defaults to the author's privacy setting
 }
 
 func (h *ReviewHandler) Create(c *gin.Context) {
@@ -50,12 +54,18 @@ func (h *ReviewHandler) Create(c *gin.Context) {
 		Content:     req.Content,
 		IPAddress:   c.ClientIP(),
 		GeoLocation: req.GeoLocation,
+		Scores:      req.Scores,
+		Anonymous:   req.Anonymous,
 	})
+	if errors.Is(err, services.ErrEmailNotVerified) {
+		utils.JSONError(c, http.StatusForbidden, err.Error())
+		return
+	}
 	if err != nil {
 		utils.JSONError(c, http.StatusBadRequest, err.Error())
 		return
 	}
-	utils.JSONSuccess(c, http.StatusCreated, review)
+	utils.JSONSuccess(c, http.StatusCreated, dto.NewReview(*review))
 }
 
 func (h *ReviewHandler) ListByCompany(c *gin.Context) {
@@ -64,10 +74,10 @@ func (h *ReviewHandler) ListByCompany(c *gin.Context) {
 		utils.JSONError(c, http.StatusBadRequest, "invalid id")
 		return
 	}
-	reviews, err := h.service.ListByCompany(c.Request.Context(), companyID)
+	reviews, err := h.service.ListByCompany(c.Request.Context(), companyID, viewerFromContext(c))
 	if err != nil {
 		utils.JSONError(c, http.StatusInternalServerError, err.Error())
 		return
 	}
-	utils.JSONSuccess(c, http.StatusOK, reviews)
+	utils.JSONSuccess(c, http.StatusOK, dto.NewReviews(reviews))
 }
@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"crowdreview/config"
+	"crowdreview/internal/models"
 	"crowdreview/internal/services"
 	"crowdreview/pkg/middleware"
+	"crowdreview/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
@@ -19,46 +21,156 @@ type RouterDeps struct {
 // SetupRouter initializes Gin with routes and middleware.
 func SetupRouter(deps RouterDeps) *gin.Engine {
 	r := gin.New()
+	r.Use(middleware.RequestID())
+	r.Use(middleware.RequestLogger())
 	r.Use(gin.Recovery())
 	r.Use(middleware.CORSMiddleware())
-	r.Use(middleware.RequestLogger())
 	if deps.Redis != nil {
 		r.Use(middleware.RateLimitMiddleware(deps.Redis, deps.Config))
 	}
 
+	keys := deps.Services.Keys.KeySet()
+	denylist := utils.NewTokenDenylist(deps.Redis)
+	requireAuth := middleware.AuthRequired(deps.Config, keys, denylist)
+	optionalAuth := middleware.OptionalAuth(deps.Config, keys, denylist)
+	can := func(perm string) gin.HandlerFunc {
+		return middleware.RequirePermission(deps.Config, perm)
+	}
+	moderateReviews := can(models.PermReviewsModerate)
+	writeCompanies := can(models.PermCompaniesWrite)
+	manageRules := can(models.PermRulesManage)
+	manageUsers := can(models.PermUsersManage)
+	readInsights := can(models.PermInsightsRead)
+
 	authHandler := NewAuthHandler(deps.Services.Auth, deps.Config)
 	companyHandler := NewCompanyHandler(deps.Services.Company)
 	reviewHandler := NewReviewHandler(deps.Services.Review)
 	adminHandler := NewAdminHandler(deps.Services.Admin)
+	criteriaHandler := NewCriteriaHandler(deps.Services.Criteria)
+	searchHandler := NewSearchHandler(deps.Services.Search)
+	directoryHandler := NewDirectoryHandler(deps.Services.Directory)
+	transferHandler := NewTransferHandler(deps.Services.Transfer)
+	jwksHandler := NewJWKSHandler(deps.Services.Keys)
+	profileHandler := NewProfileHandler(deps.Services.Profile)
+	privacyHandler := NewPrivacyHandler(deps.Services.Privacy)
+	leaderboardHandler := NewLeaderboardHandler(deps.Services.Leaderboard)
+	pointsHandler := NewPointsHandler(deps.Services.Points)
+	achievementHandler := NewAchievementHandler(deps.Services.Achievements)
+	notificationHandler := NewNotificationHandler(deps.Services.Notifications)
+	webhookHandler := NewWebhookHandler(deps.Services.Webhooks)
+	activityHandler := NewActivityHandler(deps.Services.Activity)
+
+	r.GET("/.well-known/jwks.json", jwksHandler.JWKS)
 
 	auth := r.Group("/auth")
 	{
 		auth.POST("/register", authHandler.Register)
 		auth.POST("/login", authHandler.Login)
+		auth.POST("/login/2fa", authHandler.LoginTwoFactor)
 		auth.POST("/refresh", authHandler.Refresh)
+		auth.POST("/logout", requireAuth, authHandler.Logout)
+		auth.POST("/logout-all", requireAuth, authHandler.LogoutAll)
+		auth.POST("/verify-email", authHandler.VerifyEmail)
+		auth.POST("/verify-email/resend", requireAuth, authHandler.ResendVerification)
+		auth.POST("/password/forgot", authHandler.ForgotPassword)
+		auth.POST("/password/reset", authHandler.ResetPassword)
+		auth.POST("/2fa/enroll", requireAuth, authHandler.EnrollTOTP)
+		auth.POST("/2fa/confirm", requireAuth, authHandler.ConfirmTOTP)
+		auth.POST("/2fa/disable", requireAuth, authHandler.DisableTOTP)
+		auth.POST("/2fa/recovery-codes", requireAuth, authHandler.RegenerateRecoveryCodes)
+		auth.GET("/oidc/providers", authHandler.OIDCProviders)
+		auth.POST("/oidc/:provider/authorize", authHandler.OIDCAuthorize)
+		auth.POST("/oidc/:provider/callback", authHandler.OIDCCallback)
 	}
 
+	r.GET("/me", requireAuth, profileHandler.Me)
+	r.PATCH("/me", requireAuth, profileHandler.UpdateMe)
+	r.GET("/me/export", requireAuth, privacyHandler.Export)
+	r.POST("/me/delete", requireAuth, privacyHandler.Delete)
+	r.GET("/me/notifications", requireAuth, notificationHandler.List)
+	r.PATCH("/me/notifications/:id", requireAuth, notificationHandler.Update)
+	r.POST("/me/notifications/read-all", requireAuth, notificationHandler.ReadAll)
+	r.GET("/me/notifications/preferences", requireAuth, notificationHandler.Preferences)
+	r.PUT("/me/notifications/preferences", requireAuth, notificationHandler.UpdatePreferences)
+	r.GET("/users/:username", profileHandler.Show)
+	r.GET("/leaderboards", leaderboardHandler.List)
+	r.GET("/achievements", achievementHandler.Catalog)
+
 	companies := r.Group("/companies")
 	{
 		companies.GET("", companyHandler.List)
 		companies.GET("/:id", companyHandler.Get)
-		companies.POST("", middleware.AuthRequired(deps.Config), middleware.AdminRequired(), companyHandler.Create)
-		companies.PATCH("/:id", middleware.AuthRequired(deps.Config), middleware.AdminRequired(), companyHandler.Update)
-		companies.GET("/:id/reviews", reviewHandler.ListByCompany)
+		companies.POST("", requireAuth, writeCompanies, companyHandler.Create)
+		companies.PATCH("/:id", requireAuth, writeCompanies, companyHandler.Update)
+		companies.GET("/:id/reviews", optionalAuth, reviewHandler.ListByCompany)
+		companies.GET("/:id/criteria", criteriaHandler.ForCompany)
+	}
+
+	r.GET("/search", searchHandler.Search)
+
+	directory := r.Group("/directory")
+	{
+		directory.GET("/industries", directoryHandler.Industries)
+		directory.GET("/regions", directoryHandler.Regions)
+		directory.GET("/companies", directoryHandler.Companies)
 	}
 
 	reviews := r.Group("/reviews")
-	reviews.Use(middleware.AuthRequired(deps.Config))
+	reviews.Use(requireAuth)
 	{
 		reviews.POST("/create", reviewHandler.Create)
 	}
 
+	// Company owners manage their companies' webhooks; webhooks.manage
+	// holders manage every webhook, including global ones, and need the
+	// second factor under RequireAdmin2FA like the admin routes.
+	webhooks := r.Group("/webhooks")
+	webhooks.Use(requireAuth)
+	{
+		webhooks.GET("", webhookHandler.List)
+		webhooks.POST("", webhookHandler.Create)
+		webhooks.PATCH("/:id", webhookHandler.Update)
+		webhooks.DELETE("/:id", webhookHandler.Delete)
+		webhooks.GET("/:id/deliveries", webhookHandler.Deliveries)
+		webhooks.POST("/:id/deliveries/:deliveryId/redeliver", webhookHandler.Redeliver)
+	}
+
 	admin := r.Group("/admin")
-	admin.Use(middleware.AuthRequired(deps.Config), middleware.AdminRequired())
+	admin.Use(requireAuth)
 	{
-		admin.GET("/dashboard/insights", adminHandler.Insights)
-		admin.GET("/reviews/suspicious", adminHandler.Suspicious)
-		admin.POST("/reviews/:id/respond", adminHandler.Respond)
+		admin.GET("/dashboard/insights", readInsights, adminHandler.Insights)
+		admin.GET("/reviews/suspicious", moderateReviews, adminHandler.Suspicious)
+		admin.POST("/reviews/:id/respond", moderateReviews, adminHandler.Respond)
+		admin.POST("/reviews/:id/claim", moderateReviews, adminHandler.ClaimReview)
+		admin.DELETE("/reviews/:id/claim", moderateReviews, adminHandler.ReleaseReview)
+		// Moderators get review activity, users.manage holders incidents.
+		admin.GET("/events/stream", middleware.RequireAnyPermission(deps.Config, models.PermReviewsModerate, models.PermUsersManage), activityHandler.Stream)
+		admin.GET("/security-events", manageUsers, adminHandler.SecurityEvents)
+		admin.GET("/users/:id/access", manageUsers, adminHandler.UserAccess)
+		admin.PUT("/users/:id/access", manageUsers, adminHandler.SetUserAccess)
+		admin.GET("/users/:id/points", manageUsers, pointsHandler.History)
+		admin.POST("/users/:id/points/recompute", manageUsers, pointsHandler.Recompute)
+		admin.GET("/criteria", manageRules, criteriaHandler.List)
+		admin.POST("/criteria", manageRules, criteriaHandler.Create)
+		admin.PATCH("/criteria/:id", manageRules, criteriaHandler.Update)
+		admin.DELETE("/criteria/:id", manageRules, criteriaHandler.Delete)
+		admin.POST("/achievements", manageRules, achievementHandler.Create)
+		admin.POST("/achievements/seed", manageRules, achievementHandler.Seed)
+		admin.PATCH("/achievements/:id", manageRules, achievementHandler.Update)
+		admin.DELETE("/achievements/:id", manageRules, achievementHandler.Delete)
+		admin.GET("/companies/duplicates", writeCompanies, companyHandler.Duplicates)
+		admin.POST("/companies/import", writeCompanies, transferHandler.Import)
+		admin.GET("/companies/export", writeCompanies, transferHandler.Export)
+		admin.POST("/companies/:id/merge", writeCompanies, companyHandler.Merge)
+		admin.POST("/companies/:id/aliases", writeCompanies, companyHandler.AddAlias)
+		admin.DELETE("/companies/:id/aliases/:aliasId", writeCompanies, companyHandler.DeleteAlias)
+		admin.PUT("/companies/:id/owner", writeCompanies, companyHandler.SetOwner)
+		admin.POST("/industries", writeCompanies, directoryHandler.CreateIndustry)
+		admin.PATCH("/industries/:id", writeCompanies, directoryHandler.UpdateIndustry)
+		admin.DELETE("/industries/:id", writeCompanies, directoryHandler.DeleteIndustry)
+		admin.POST("/regions", writeCompanies, directoryHandler.CreateRegion)
+		admin.PATCH("/regions/:id", writeCompanies, directoryHandler.UpdateRegion)
+		admin.DELETE("/regions/:id", writeCompanies, directoryHandler.DeleteRegion)
 	}
 
 	// Swagger placeholder - requires docs generation (swag init)
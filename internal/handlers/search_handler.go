@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+
+	"crowdreview/internal/dto"
+	"crowdreview/internal/services"
+	"crowdreview/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchHandler exposes full-text search.
+type SearchHandler struct {
+	service services.SearchService
+}
+
+func NewSearchHandler(service services.SearchService) *SearchHandler {
+	return &SearchHandler{service: service}
+}
+
+type searchRequest struct {
+	Query    string `form:"q" binding:"required"`
+	Type     string `form:"type"`
+	Industry string `form:"industry"`
+	Location string `form:"location"`
+	Limit    int    `form:"limit"`
+	Offset   int    `form:"offset"`
+}
+
+func (h *SearchHandler) Search(c *gin.Context) {
+	var req searchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	results, err := h.service.Search(c.Request.Context(), services.SearchInput(req))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, dto.NewSearchResults(results))
+}
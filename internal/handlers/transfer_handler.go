@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"crowdreview/internal/dto"
+	"crowdreview/internal/services"
+	"crowdreview/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportBytes caps the size of an import file.
+const maxImportBytes = 16 << 20
+
+// TransferHandler exposes bulk company import and export.
+type TransferHandler struct {
+	service services.CompanyTransferService
+}
+
+func NewTransferHandler(service services.CompanyTransferService) *TransferHandler {
+	return &TransferHandler{service: service}
+}
+
+type importRequest struct {
+	Format          string `form:"format"`
+	DryRun          bool   `form:"dry_run"`
+	AllowDuplicates bool   `form:"allow_duplicates"`
+}
+
+// Import reads a CSV or NDJSON request body. The format comes from the
+// format query parameter or, failing that, the Content-Type header.
+func (h *TransferHandler) Import(c *gin.Context) {
+	var req importRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	format := req.Format
+	if format == "" {
+		format = formatFromContentType(c.ContentType())
+	}
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+	report, err := h.service.Import(c.Request.Context(), body, services.ImportOptions{
+		Format:          format,
+		DryRun:          req.DryRun,
+		AllowDuplicates: req.AllowDuplicates,
+	})
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		utils.JSONError(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("import file exceeds %d MiB", maxImportBytes>>20))
+		return
+	}
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, dto.NewImportReport(report))
+}
+
+// Export streams all companies as an attachment. Once streaming starts the
+// status is committed, so a late failure can only truncate the body.
+func (h *TransferHandler) Export(c *gin.Context) {
+	format := c.DefaultQuery("format", services.FormatCSV)
+	contentType := "text/csv; charset=utf-8"
+	switch format {
+	case services.FormatCSV:
+	case services.FormatNDJSON:
+		contentType = "application/x-ndjson"
+	default:
+		utils.JSONError(c, http.StatusBadRequest, "format must be csv or ndjson")
+		return
+	}
+	filename := fmt.Sprintf("companies-%s.%s", time.Now().UTC().Format("20060102"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+	if err := h.service.Export(c.Request.Context(), c.Writer, format); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+func formatFromContentType(contentType string) string {
+	switch {
+	case strings.Contains(contentType, "ndjson"), strings.Contains(contentType, "jsonl"):
+		return services.FormatNDJSON
+	default:
+		return services.FormatCSV
+	}
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"crowdreview/internal/dto"
+	"crowdreview/internal/services"
+	"crowdreview/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// WebhookHandler lets company owners and integrators manage webhook
+// subscriptions and inspect their deliveries.
+type WebhookHandler struct {
+	service services.WebhookService
+}
+
+func NewWebhookHandler(service services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service: service}
+}
+
+type webhookRequest struct {
+	CompanyID    *uuid.UUID `json:"company_id"`
+	URL          *string    `json:"url"`
+	Events       []string   `json:"events"`
+	Active       *bool      `json:"active"`
+	RotateSecret bool       `json:"rotate_secret"`
+}
+
+func (h *WebhookHandler) List(c *gin.Context) {
+	subs, err := h.service.List(c.Request.Context(), viewerFromContext(c))
+	if err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, dto.NewWebhooks(subs))
+}
+
+// Create subscribes a URL; the response is the only time the signing secret
+// is shown.
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	sub, err := h.service.Create(c.Request.Context(), viewerFromContext(c), services.WebhookInput(req))
+	if err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusCreated, dto.NewWebhook(*sub, true))
+}
+
+// Update changes only the fields present in the body; "rotate_secret": true
+// returns a new secret.
+func (h *WebhookHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	sub, err := h.service.Update(c.Request.Context(), viewerFromContext(c), id, services.WebhookInput(req))
+	if err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, dto.NewWebhook(*sub, req.RotateSecret))
+}
+
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+	if err := h.service.Delete(c.Request.Context(), viewerFromContext(c), id); err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, gin.H{"status": "deleted"})
+}
+
+type deliveriesRequest struct {
+	Limit  int `form:"limit"`
+	Offset int `form:"offset"`
+}
+
+// Deliveries pages through the subscription's delivery log, newest first.
+func (h *WebhookHandler) Deliveries(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var req deliveriesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, err := h.service.Deliveries(c.Request.Context(), viewerFromContext(c), id, req.Limit, req.Offset)
+	if err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusOK, dto.NewWebhookDeliveries(page))
+}
+
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+	deliveryID, err := uuid.Parse(c.Param("deliveryId"))
+	if err != nil {
+		utils.JSONError(c, http.StatusBadRequest, "invalid delivery id")
+		return
+	}
+	delivery, err := h.service.Redeliver(c.Request.Context(), viewerFromContext(c), id, deliveryID)
+	if err != nil {
+		writeWebhookError(c, err)
+		return
+	}
+	utils.JSONSuccess(c, http.StatusAccepted, dto.NewWebhookDelivery(*delivery))
+}
+
+func writeWebhookError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrWebhookNotFound):
+		utils.JSONError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrWebhookForbidden):
+		utils.JSONError(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrInvalidWebhook):
+		utils.JSONError(c, http.StatusBadRequest, err.Error())
+	default:
+		utils.JSONError(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -5,20 +5,23 @@ import (
 	"gorm.io/datatypes"
 )
 
-// Achievement describes gamification achievements.
+// Achievement describes gamification achievements. Meta["criteria"] holds
+// the award criteria; deleted achievements stay on the users who earned them.
 type Achievement struct {
 	Base
-	Name        string            `gorm:"uniqueIndex:idx_achievements_name"`
-	Description string
-	Points      int               `gorm:"index"`
-	Meta        datatypes.JSONMap `gorm:"type:jsonb;default:'{}'::jsonb"`
+	Name             string            `gorm:"uniqueIndex:idx_achievements_active_name,where:deleted_at IS NULL"`
+	Description      string
+	Icon             string
+	Points           int               `gorm:"index"`
+	Meta             datatypes.JSONMap `gorm:"type:jsonb;default:'{}'::jsonb"`
 	UserAchievements []UserAchievement
 }
 
-// UserAchievement joins users and achievements.
+// UserAchievement joins users and achievements; each is earned at most once.
 type UserAchievement struct {
 	Base
-	UserID        uuid.UUID `gorm:"type:uuid;index"`
-	AchievementID uuid.UUID `gorm:"type:uuid;index"`
+	UserID        uuid.UUID `gorm:"type:uuid;index;uniqueIndex:idx_user_achievement"`
+	AchievementID uuid.UUID `gorm:"type:uuid;index;uniqueIndex:idx_user_achievement"`
+	Achievement   Achievement `gorm:"constraint:OnDelete:CASCADE"`
 	EarnedAt      int64     `gorm:"autoCreateTime"`
 }
@@ -1,16 +1,32 @@
 package models
 
-import "gorm.io/datatypes"
+import (
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
 
 // Company represents a company that receives reviews.
 type Company struct {
 	Base
 	Name        string            `gorm:"uniqueIndex:idx_companies_name,where:deleted_at IS NULL;not null"`
 	Domain      string            `gorm:"uniqueIndex:idx_companies_domain,where:deleted_at IS NULL"`
-	Industry    string            `gorm:"index"`
-	Location    string
+	IndustryID  *uuid.UUID        `gorm:"type:uuid;index"`
+	Industry    string            `gorm:"index"` // name of IndustryID, kept for display and search
+	RegionID    *uuid.UUID        `gorm:"type:uuid;index"`
+	Location    string            // "City, State, Country" of RegionID
 	Description string            `gorm:"type:text"`
 	Website     string
 	Metrics     datatypes.JSONMap `gorm:"type:jsonb;default:'{}'::jsonb"` // dashboard metrics cache
+	MergedIntoID *uuid.UUID       `gorm:"type:uuid;index"` // set on the soft-deleted side of a merge
+	OwnerID     *uuid.UUID        `gorm:"type:uuid;index"` // user who manages the company's integrations
 	Reviews     []Review
+	Aliases     []CompanyAlias
+}
+
+// CompanyAlias is an alternative name a company is known by, used for
+// duplicate detection. Merging a company keeps its name as an alias.
+type CompanyAlias struct {
+	Base
+	CompanyID uuid.UUID `gorm:"type:uuid;index;not null"`
+	Name      string    `gorm:"not null"`
 }
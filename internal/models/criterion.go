@@ -0,0 +1,25 @@
+package models
+
+import "github.com/google/uuid"
+
+// RatingCriterion is an admin-managed sub-rating (service, price, delivery...)
+// offered to reviewers of companies in an industry. An empty Industry applies
+// to every company.
+type RatingCriterion struct {
+	Base
+	Industry string `gorm:"uniqueIndex:idx_rating_criteria_industry_key,where:deleted_at IS NULL"`
+	Key      string `gorm:"uniqueIndex:idx_rating_criteria_industry_key,where:deleted_at IS NULL;not null"`
+	Label    string `gorm:"not null"`
+	Position int
+}
+
+// TableName avoids GORM's "rating_criterions" pluralization.
+func (RatingCriterion) TableName() string { return "rating_criteria" }
+
+// ReviewScore is a review's 1-5 score for one rating criterion.
+type ReviewScore struct {
+	ReviewID    uuid.UUID       `gorm:"type:uuid;primaryKey"`
+	CriterionID uuid.UUID       `gorm:"type:uuid;primaryKey;index"`
+	Criterion   RatingCriterion `gorm:"constraint:OnDelete:CASCADE"`
+	Score       int             `gorm:"check:score BETWEEN 1 AND 5"`
+}
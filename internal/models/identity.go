@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Identity links a user to an account at an external OAuth2/OIDC provider.
+type Identity struct {
+	Base
+	UserID   uuid.UUID `gorm:"type:uuid;index;not null"`
+	Provider string    `gorm:"type:varchar(64);uniqueIndex:idx_identities_provider_subject;not null"`
+	Subject  string    `gorm:"uniqueIndex:idx_identities_provider_subject;not null"`
+	Email    string
+}
+
+// OIDCState tracks a pending authorization request between the redirect to
+// the provider and its callback. Only a SHA-256 hash of the state is stored.
+type OIDCState struct {
+	Base
+	Provider     string    `gorm:"type:varchar(64);not null"`
+	StateHash    string    `gorm:"type:char(64);uniqueIndex;not null"`
+	CodeVerifier string    `gorm:"not null" json:"-"`
+	Nonce        string    `gorm:"not null" json:"-"`
+	ExpiresAt    time.Time `gorm:"not null"`
+	UsedAt       *time.Time
+}
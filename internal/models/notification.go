@@ -0,0 +1,120 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Notification types.
+const (
+	NotificationReviewApproved    = "review_approved"
+	NotificationReviewFlagged     = "review_flagged"
+	NotificationReviewRejected    = "review_rejected"
+	NotificationAchievementEarned = "achievement_earned"
+)
+
+// NotificationTypes lists every notification type users can configure.
+var NotificationTypes = []string{
+	NotificationReviewApproved,
+	NotificationReviewFlagged,
+	NotificationReviewRejected,
+	NotificationAchievementEarned,
+}
+
+// Notification channels. In-app notifications are the inbox itself; other
+// channels receive queued deliveries.
+const (
+	ChannelInApp = "in_app"
+	ChannelEmail = "email"
+)
+
+// Notification is an event addressed to a user. It shows in the inbox only
+// when InApp is set; otherwise it exists to be delivered elsewhere.
+type Notification struct {
+	Base
+	UserID  uuid.UUID         `gorm:"type:uuid;index;not null"`
+	Type    string            `gorm:"type:varchar(40);not null"`
+	Title   string            `gorm:"not null"`
+	Body    string            `gorm:"type:text"`
+	Data    datatypes.JSONMap `gorm:"type:jsonb;default:'{}'::jsonb"` // ids of the review, achievement, etc.
+	EventID *uuid.UUID        `gorm:"type:uuid;uniqueIndex"`          // outbox event it was sent for, if any
+	InApp   bool              `gorm:"not null;default:true"`
+	ReadAt  *time.Time        `gorm:"index"`
+}
+
+// NotificationDelivery queues a notification for a channel other than the
+// inbox, such as the email digest.
+type NotificationDelivery struct {
+	Base
+	NotificationID uuid.UUID    `gorm:"type:uuid;not null;uniqueIndex:idx_notification_channel"`
+	Notification   Notification `gorm:"constraint:OnDelete:CASCADE"`
+	UserID         uuid.UUID    `gorm:"type:uuid;index;not null"`
+	Channel        string       `gorm:"type:varchar(30);not null;uniqueIndex:idx_notification_channel"`
+	SentAt         *time.Time   `gorm:"index"`
+	Attempts       int          `gorm:"not null;default:0"`
+	LastError      string
+	ClaimedUntil   *time.Time `gorm:"index"` // lease of the replica sending it
+}
+
+// ProfileNotifications is the ProfileMeta key holding NotificationPrefs.
+const ProfileNotifications = "notifications"
+
+// NotificationPrefs maps a notification type to the channels it is sent
+// through.
+type NotificationPrefs map[string]map[string]bool
+
+// DefaultNotificationPrefs shows everything in the inbox and emails only
+// what needs the user's attention.
+func DefaultNotificationPrefs() NotificationPrefs {
+	prefs := NotificationPrefs{}
+	for _, t := range NotificationTypes {
+		prefs[t] = map[string]bool{ChannelInApp: true, ChannelEmail: false}
+	}
+	prefs[NotificationReviewFlagged][ChannelEmail] = true
+	prefs[NotificationReviewRejected][ChannelEmail] = true
+	return prefs
+}
+
+// Enabled reports whether notifications of type t go to channel.
+func (p NotificationPrefs) Enabled(t, channel string) bool {
+	return p[t][channel]
+}
+
+// NotificationPrefs reads the user's preferences over the defaults. Stored
+// preferences for types that no longer exist are ignored.
+func (u User) NotificationPrefs() NotificationPrefs {
+	prefs := DefaultNotificationPrefs()
+	stored, _ := u.ProfileMeta[ProfileNotifications].(map[string]interface{})
+	for t, raw := range stored {
+		channels, ok := raw.(map[string]interface{})
+		if !ok || prefs[t] == nil {
+			continue
+		}
+		for channel, v := range channels {
+			if enabled, ok := v.(bool); ok {
+				prefs[t][channel] = enabled
+			}
+		}
+	}
+	return prefs
+}
+
+// SetNotificationPrefs writes p into ProfileMeta, keeping unrelated keys.
+func (u *User) SetNotificationPrefs(p NotificationPrefs) {
+	meta := datatypes.JSONMap{}
+	for k, v := range u.ProfileMeta {
+		meta[k] = v
+	}
+	stored := map[string]interface{}{}
+	for t, channels := range p {
+		m := map[string]interface{}{}
+		for channel, enabled := range channels {
+			m[channel] = enabled
+		}
+		stored[t] = m
+	}
+	meta[ProfileNotifications] = stored
+	u.ProfileMeta = meta
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Outbox event types.
+const (
+	OutboxReviewCreated       = "review.created"
+	OutboxReviewStatusChanged = "review.status_changed" // payload: status, previous_status
+)
+
+// OutboxEvent is a domain event written in the same transaction as the
+// change it describes and relayed to its consumers afterwards. Consumers
+// see every event at least once and deduplicate by ID.
+type OutboxEvent struct {
+	Base
+	Type          string            `gorm:"type:varchar(60);not null"`
+	AggregateID   uuid.UUID         `gorm:"type:uuid;index;not null"` // the review
+	Payload       datatypes.JSONMap `gorm:"type:jsonb;default:'{}'::jsonb"`
+	RequestID     string            `gorm:"type:varchar(128)"` // request that caused the event, for log correlation
+	Attempts      int               `gorm:"not null;default:0"`
+	LastError     string
+	NextAttemptAt *time.Time `gorm:"index"` // set until published
+	PublishedAt   *time.Time `gorm:"index"`
+}
+
+// Status is the new review status of a review.status_changed event.
+func (e OutboxEvent) Status() string {
+	status, _ := e.Payload["status"].(string)
+	return status
+}
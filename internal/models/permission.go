@@ -0,0 +1,65 @@
+package models
+
+import "sort"
+
+// Admin permissions checked by RequirePermission.
+const (
+	PermReviewsModerate = "reviews.moderate"
+	PermCompaniesWrite  = "companies.write"
+	PermRulesManage     = "rules.manage"
+	PermUsersManage     = "users.manage"
+	PermInsightsRead    = "insights.read"
+	PermWebhooksManage  = "webhooks.manage"
+)
+
+// AllPermissions lists every known permission.
+var AllPermissions = []string{
+	PermReviewsModerate,
+	PermCompaniesWrite,
+	PermRulesManage,
+	PermUsersManage,
+	PermInsightsRead,
+	PermWebhooksManage,
+}
+
+// RolePermissions are the permissions each role starts with.
+var RolePermissions = map[string][]string{
+	RoleUser:      nil,
+	RoleModerator: {PermReviewsModerate, PermInsightsRead},
+	RoleAdmin:     AllPermissions,
+}
+
+// IsPermission reports whether name is a known permission.
+func IsPermission(name string) bool {
+	for _, p := range AllPermissions {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// EffectivePermissions combines the role's permissions with per-admin
+// grants from AdminUser.Permissions, where true adds a permission and false
+// removes one the role would give. The result is sorted.
+func EffectivePermissions(role string, grants map[string]interface{}) []string {
+	set := make(map[string]bool)
+	for _, p := range RolePermissions[role] {
+		set[p] = true
+	}
+	for name, value := range grants {
+		granted, ok := value.(bool)
+		if !ok || !IsPermission(name) {
+			continue
+		}
+		set[name] = granted
+	}
+	perms := make([]string, 0, len(set))
+	for p, granted := range set {
+		if granted {
+			perms = append(perms, p)
+		}
+	}
+	sort.Strings(perms)
+	return perms
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffectivePermissions(t *testing.T) {
+	require.Empty(t, EffectivePermissions(RoleUser, nil))
+	require.Equal(t, []string{PermInsightsRead, PermReviewsModerate}, EffectivePermissions(RoleModerator, nil))
+
+	perms := EffectivePermissions(RoleModerator, map[string]interface{}{
+		PermRulesManage:  true,
+		PermInsightsRead: false,
+		"unknown.perm":   true,
+		PermUsersManage:  "yes", // only booleans count
+	})
+	require.Equal(t, []string{PermReviewsModerate, PermRulesManage}, perms)
+
+	require.Len(t, EffectivePermissions(RoleAdmin, nil), len(AllPermissions))
+}
@@ -0,0 +1,26 @@
+package models
+
+import "github.com/google/uuid"
+
+// Reasons for a points ledger entry.
+const (
+	PointsAchievement    = "achievement"     // an achievement was granted
+	PointsClawback       = "clawback"        // the source review was rejected
+	PointsDecay          = "decay"           // the entry in DecayOf aged out
+	PointsOpeningBalance = "opening_balance" // score held before the ledger existed
+)
+
+// PointsEntry is one change to a user's gamification score. The score is
+// always the sum of the user's entries; entries are never updated.
+type PointsEntry struct {
+	Base
+	UserID        uuid.UUID  `gorm:"type:uuid;index;not null"`
+	Delta         int        `gorm:"not null"`
+	Reason        string     `gorm:"type:varchar(30);index;not null"`
+	ReviewID      *uuid.UUID `gorm:"type:uuid;index"` // review that earned (or lost) the points
+	AchievementID *uuid.UUID `gorm:"type:uuid;index"`
+	DecayOf       *uuid.UUID `gorm:"type:uuid;uniqueIndex"` // entry decayed by this one
+}
+
+// TableName pins the table name used by the ledger SQL.
+func (PointsEntry) TableName() string { return "points_ledger" }
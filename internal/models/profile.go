@@ -0,0 +1,53 @@
+package models
+
+import "gorm.io/datatypes"
+
+// ProfileMeta keys holding the user's public profile and privacy settings.
+const (
+	ProfileDisplayName      = "display_name"
+	ProfileAvatarURL        = "avatar_url"
+	ProfileBio              = "bio"
+	ProfileLocale           = "locale"
+	ProfileAnonymousReviews = "anonymous_reviews"
+)
+
+// Profile is the typed view of User.ProfileMeta.
+type Profile struct {
+	DisplayName string
+	AvatarURL   string
+	Bio         string
+	Locale      string
+	// AnonymousReviews makes new reviews hide the author publicly by
+	// default; moderators still see who wrote them.
+	AnonymousReviews bool
+}
+
+// Profile reads the profile stored in ProfileMeta.
+func (u User) Profile() Profile {
+	str := func(key string) string {
+		s, _ := u.ProfileMeta[key].(string)
+		return s
+	}
+	anonymous, _ := u.ProfileMeta[ProfileAnonymousReviews].(bool)
+	return Profile{
+		DisplayName:      str(ProfileDisplayName),
+		AvatarURL:        str(ProfileAvatarURL),
+		Bio:              str(ProfileBio),
+		Locale:           str(ProfileLocale),
+		AnonymousReviews: anonymous,
+	}
+}
+
+// SetProfile writes p into ProfileMeta, keeping unrelated keys.
+func (u *User) SetProfile(p Profile) {
+	meta := datatypes.JSONMap{}
+	for k, v := range u.ProfileMeta {
+		meta[k] = v
+	}
+	meta[ProfileDisplayName] = p.DisplayName
+	meta[ProfileAvatarURL] = p.AvatarURL
+	meta[ProfileBio] = p.Bio
+	meta[ProfileLocale] = p.Locale
+	meta[ProfileAnonymousReviews] = p.AnonymousReviews
+	u.ProfileMeta = meta
+}
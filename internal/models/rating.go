@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CompanyRatingStats holds counters over a company's approved reviews. Rows
+// are adjusted whenever a review enters or leaves the approved state.
+type CompanyRatingStats struct {
+	CompanyID     uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ApprovedCount int64     `gorm:"not null;default:0"`
+	RatingSum     int64     `gorm:"not null;default:0"`
+	Star1         int64     `gorm:"not null;default:0"`
+	Star2         int64     `gorm:"not null;default:0"`
+	Star3         int64     `gorm:"not null;default:0"`
+	Star4         int64     `gorm:"not null;default:0"`
+	Star5         int64     `gorm:"not null;default:0"`
+	UpdatedAt     time.Time
+}
+
+// CompanyRatingDay buckets approved reviews by the UTC day they were written,
+// backing the 30/90-day trend windows.
+type CompanyRatingDay struct {
+	CompanyID     uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Day           time.Time `gorm:"type:date;primaryKey"`
+	ApprovedCount int64     `gorm:"not null;default:0"`
+	RatingSum     int64     `gorm:"not null;default:0"`
+}
+
+// CompanyCriterionStats holds per-criterion counters over a company's approved
+// reviews, maintained alongside CompanyRatingStats.
+type CompanyCriterionStats struct {
+	CompanyID   uuid.UUID `gorm:"type:uuid;primaryKey"`
+	CriterionID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ScoreCount  int64     `gorm:"not null;default:0"`
+	ScoreSum    int64     `gorm:"not null;default:0"`
+}
+
+// TableName pins the table name used by the aggregate SQL.
+func (CompanyRatingStats) TableName() string { return "company_rating_stats" }
+
+// TableName pins the table name used by the aggregate SQL.
+func (CompanyRatingDay) TableName() string { return "company_rating_days" }
+
+// TableName pins the table name used by the aggregate SQL.
+func (CompanyCriterionStats) TableName() string { return "company_criterion_stats" }
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken is the server-side record of an issued refresh token. Only a
+// SHA-256 hash of the token is stored. Every rotation issues a new token in
+// the same family; presenting a used or revoked token revokes the family.
+type RefreshToken struct {
+	Base
+	UserID    uuid.UUID `gorm:"type:uuid;index;not null"`
+	FamilyID  uuid.UUID `gorm:"type:uuid;index;not null"`
+	TokenHash string    `gorm:"type:char(64);uniqueIndex;not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+	MFA       bool // the login completed a second factor; kept across rotations
+}
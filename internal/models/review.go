@@ -1,10 +1,20 @@
 package models
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
 )
 
+// Review lifecycle states.
+const (
+	ReviewStatusPending  = "pending"
+	ReviewStatusApproved = "approved"
+	ReviewStatusFlagged  = "flagged"
+	ReviewStatusRejected = "rejected"
+)
+
 // Review represents a user review awaiting validation.
 type Review struct {
 	Base
@@ -19,7 +29,11 @@ type Review struct {
 	GeoLocation        string
 	Status             string            `gorm:"type:varchar(20);index;default:'pending'"`
 	Suspicious         bool              `gorm:"index"`
+	Anonymous          bool              `gorm:"not null;default:false"` // author hidden publicly, known to moderators
+	ClaimedByID        *uuid.UUID        `gorm:"type:uuid;index"`        // moderator working on the review
+	ClaimedAt          *time.Time
 	ValidationResultID *uuid.UUID
 	ValidationResult   *ReviewValidationResult
 	Metadata           datatypes.JSONMap `gorm:"type:jsonb;default:'{}'::jsonb"`
+	Scores             []ReviewScore     `gorm:"constraint:OnDelete:CASCADE"`
 }
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Security event types.
+const (
+	SecurityLoginFailed   = "login_failed"
+	SecurityAccountLocked = "account_locked"
+	SecurityIPLocked      = "ip_locked"
+	SecurityLoginNewIP    = "login_new_ip"
+)
+
+// SecurityEvent is an audit record of an authentication event. UserID is
+// empty when the attempted email matches no account.
+type SecurityEvent struct {
+	Base
+	Type      string            `gorm:"type:varchar(32);index;not null"`
+	UserID    *uuid.UUID        `gorm:"type:uuid;index"`
+	Email     string            `gorm:"index"`
+	IPAddress string            `gorm:"type:varchar(64);index"`
+	Meta      datatypes.JSONMap `gorm:"type:jsonb;default:'{}'::jsonb"`
+}
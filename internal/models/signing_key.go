@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// SigningKey stores a JWT signing key pair. Keys are shared by every API
+// instance; the private half never leaves the database and the API process.
+type SigningKey struct {
+	Base
+	Kid           string    `gorm:"type:varchar(64);uniqueIndex;not null"`
+	Algorithm     string    `gorm:"type:varchar(10);not null"`
+	PrivateKeyPEM string    `gorm:"type:text;not null" json:"-"`
+	ActivatesAt   time.Time `gorm:"index;not null"`
+	ExpiresAt     time.Time `gorm:"index;not null"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "github.com/google/uuid"
+
+// Region kinds, from least to most specific.
+const (
+	RegionCountry = "country"
+	RegionState   = "state"
+	RegionCity    = "city"
+)
+
+// Industry is a node in the managed industry taxonomy.
+type Industry struct {
+	Base
+	ParentID *uuid.UUID `gorm:"type:uuid;index"`
+	Slug     string     `gorm:"uniqueIndex:idx_industries_slug,where:deleted_at IS NULL;not null"`
+	Name     string     `gorm:"not null"`
+}
+
+// Region is a node in the country/state/city location hierarchy. Slugs are
+// unique among siblings.
+type Region struct {
+	Base
+	ParentID *uuid.UUID `gorm:"type:uuid;index"`
+	Kind     string     `gorm:"type:varchar(10);index;not null"`
+	Slug     string     `gorm:"index;not null"`
+	Name     string     `gorm:"not null"`
+}
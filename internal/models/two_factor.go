@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TOTPCredential is a user's authenticator app secret. It only protects
+// logins once ConfirmedAt is set by a first valid code.
+type TOTPCredential struct {
+	Base
+	UserID       uuid.UUID `gorm:"type:uuid;uniqueIndex;not null"`
+	Secret       string    `gorm:"not null" json:"-"`
+	ConfirmedAt  *time.Time
+	LastUsedStep int64 `gorm:"not null;default:0"` // rejects replay of an accepted code
+}
+
+// RecoveryCode is a single-use fallback for a lost authenticator. Only a
+// SHA-256 hash is stored.
+type RecoveryCode struct {
+	Base
+	UserID   uuid.UUID `gorm:"type:uuid;index;not null"`
+	CodeHash string    `gorm:"type:char(64);not null"`
+	UsedAt   *time.Time
+}
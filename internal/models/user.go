@@ -1,17 +1,27 @@
 package models
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
 )
 
+// User roles.
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
 // User represents an end-user with optional gamification data.
 type User struct {
 	Base
 	Email            string            `gorm:"uniqueIndex:idx_users_email,where:deleted_at IS NULL;not null"`
 	Username         string            `gorm:"uniqueIndex:idx_users_username,where:deleted_at IS NULL;not null"`
-	PasswordHash     string            `gorm:"not null"`
+	PasswordHash     string            `gorm:"not null" json:"-"`
 	Role             string            `gorm:"type:varchar(20);index;default:'user'"` // user or admin
+	EmailVerifiedAt  *time.Time
 	GamificationScore int              `gorm:"default:0"`
 	ProfileMeta      datatypes.JSONMap `gorm:"type:jsonb;default:'{}'::jsonb"`
 	Achievements     []UserAchievement
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// User token purposes.
+const (
+	TokenEmailVerification = "email_verification"
+	TokenPasswordReset     = "password_reset"
+)
+
+// UserToken is a single-use, expiring token mailed to a user. Only a
+// SHA-256 hash of the token is stored.
+type UserToken struct {
+	Base
+	UserID    uuid.UUID `gorm:"type:uuid;index;not null"`
+	Purpose   string    `gorm:"type:varchar(32);index;not null"`
+	TokenHash string    `gorm:"type:char(64);uniqueIndex;not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+}
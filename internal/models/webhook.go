@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Webhook delivery states.
+const (
+	WebhookPending   = "pending"
+	WebhookDelivered = "delivered"
+	WebhookFailed    = "failed" // gave up after the last retry
+)
+
+// WebhookSubscription sends the chosen events of one company, or of every
+// company when CompanyID is nil, to URL.
+type WebhookSubscription struct {
+	Base
+	CompanyID   *uuid.UUID                  `gorm:"type:uuid;index"`
+	Company     *Company                    `gorm:"constraint:OnDelete:CASCADE"`
+	CreatedByID uuid.UUID                   `gorm:"type:uuid;not null"`
+	URL         string                      `gorm:"not null"`
+	Secret      string                      `gorm:"not null"` // HMAC-SHA256 key, kept in clear to sign payloads
+	Events      datatypes.JSONSlice[string] `gorm:"type:jsonb;not null"`
+	Active      bool                        `gorm:"not null;default:true"`
+}
+
+// WebhookDelivery is one event queued for a subscription and the log of
+// its attempts. An event is queued once per subscription; a redelivery is
+// a new row with the same EventID.
+type WebhookDelivery struct {
+	Base
+	SubscriptionID uuid.UUID           `gorm:"type:uuid;index;not null;uniqueIndex:idx_webhook_delivery_event,where:redelivery_of IS NULL"`
+	Subscription   WebhookSubscription `gorm:"constraint:OnDelete:CASCADE"`
+	EventID        uuid.UUID           `gorm:"type:uuid;index;not null;uniqueIndex:idx_webhook_delivery_event"`
+	Event          string              `gorm:"type:varchar(60);not null"`
+	Payload        datatypes.JSON      `gorm:"type:jsonb;not null"`
+	Status         string              `gorm:"type:varchar(20);not null;default:'pending'"`
+	Attempts       int                 `gorm:"not null;default:0"`
+	StatusCode     int                 // response status of the last attempt; 0 when none arrived
+	LastError      string
+	NextAttemptAt  *time.Time `gorm:"index"` // set while pending
+	DeliveredAt    *time.Time
+	RedeliveryOf   *uuid.UUID `gorm:"type:uuid"`
+}
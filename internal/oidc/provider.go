@@ -0,0 +1,330 @@
+// Package oidc implements the client side of OpenID Connect (and plain
+// OAuth2) social login: authorization code flow with PKCE, ID token
+// verification against the provider's JWKS, and userinfo lookups.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"crowdreview/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Token is the token endpoint response.
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+}
+
+// Identity is who the provider says the user is.
+type Identity struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+// Provider is one configured login provider. Providers with an issuer are
+// OpenID Connect: endpoints come from discovery and identities from the
+// signed ID token. Providers without one (e.g. GitHub) are plain OAuth2 and
+// identities come from the configured userinfo endpoint only.
+type Provider struct {
+	Name        string
+	cfg         config.OIDCProvider
+	redirectURL string
+	client      *http.Client
+
+	mu         sync.Mutex
+	discovered bool
+	authURL    string
+	tokenURL   string
+	userInfo   string
+	keys       *keyCache
+}
+
+// NewProvider builds a provider; client defaults to a 10 second timeout.
+func NewProvider(cfg config.OIDCProvider, redirectURL string, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Provider{Name: cfg.Name, cfg: cfg, redirectURL: redirectURL, client: client}
+}
+
+// Registry maps provider names to providers.
+type Registry map[string]*Provider
+
+// NewRegistry builds every provider in cfg.OIDCProviders. Callbacks land on
+// the frontend at FRONTEND_URL/oauth/callback/<name> unless overridden.
+func NewRegistry(cfg config.Config) Registry {
+	registry := make(Registry, len(cfg.OIDCProviders))
+	for _, p := range cfg.OIDCProviders {
+		redirect := p.RedirectURL
+		if redirect == "" {
+			redirect = strings.TrimRight(cfg.FrontendURL, "/") + "/oauth/callback/" + p.Name
+		}
+		registry[p.Name] = NewProvider(p, redirect, nil)
+	}
+	return registry
+}
+
+// Names lists the configured providers in a stable order.
+func (r Registry) Names() []string {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsOIDC reports whether the provider issues verifiable ID tokens.
+func (p *Provider) IsOIDC() bool {
+	return p.cfg.Issuer != ""
+}
+
+// discover loads the provider metadata once; explicit configuration wins.
+func (p *Provider) discover(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovered {
+		return nil
+	}
+	p.authURL, p.tokenURL, p.userInfo = p.cfg.AuthURL, p.cfg.TokenURL, p.cfg.UserInfoURL
+	if p.IsOIDC() {
+		var doc struct {
+			Issuer                string `json:"issuer"`
+			AuthorizationEndpoint string `json:"authorization_endpoint"`
+			TokenEndpoint         string `json:"token_endpoint"`
+			UserInfoEndpoint      string `json:"userinfo_endpoint"`
+			JWKSURI               string `json:"jwks_uri"`
+		}
+		wellKnown := strings.TrimRight(p.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+		if err := getJSON(ctx, p.client, wellKnown, "", &doc); err != nil {
+			return fmt.Errorf("oidc discovery for %s: %w", p.Name, err)
+		}
+		if doc.Issuer != p.cfg.Issuer {
+			return fmt.Errorf("oidc discovery for %s: issuer mismatch %q", p.Name, doc.Issuer)
+		}
+		if p.authURL == "" {
+			p.authURL = doc.AuthorizationEndpoint
+		}
+		if p.tokenURL == "" {
+			p.tokenURL = doc.TokenEndpoint
+		}
+		if p.userInfo == "" {
+			p.userInfo = doc.UserInfoEndpoint
+		}
+		p.keys = &keyCache{url: doc.JWKSURI, client: p.client}
+	}
+	if p.authURL == "" || p.tokenURL == "" {
+		return fmt.Errorf("provider %s has no authorization or token endpoint", p.Name)
+	}
+	if !p.IsOIDC() && p.userInfo == "" {
+		return fmt.Errorf("provider %s needs a userinfo endpoint", p.Name)
+	}
+	p.discovered = true
+	return nil
+}
+
+// AuthCodeURL is where the browser is sent to sign in. The verifier stays
+// on the server; only its S256 challenge goes to the provider.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	if err := p.discover(ctx); err != nil {
+		return "", err
+	}
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", p.cfg.ClientID)
+	q.Set("redirect_uri", p.redirectURL)
+	q.Set("scope", strings.Join(p.scopes(), " "))
+	q.Set("state", state)
+	q.Set("code_challenge", PKCEChallenge(verifier))
+	q.Set("code_challenge_method", "S256")
+	if p.IsOIDC() {
+		q.Set("nonce", nonce)
+	}
+	sep := "?"
+	if strings.Contains(p.authURL, "?") {
+		sep = "&"
+	}
+	return p.authURL + sep + q.Encode(), nil
+}
+
+func (p *Provider) scopes() []string {
+	if len(p.cfg.Scopes) > 0 {
+		return p.cfg.Scopes
+	}
+	if p.IsOIDC() {
+		return []string{"openid", "email", "profile"}
+	}
+	return nil
+}
+
+// Exchange trades the authorization code for tokens.
+func (p *Provider) Exchange(ctx context.Context, code, verifier string) (Token, error) {
+	if err := p.discover(ctx); err != nil {
+		return Token{}, err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.redirectURL)
+	form.Set("client_id", p.cfg.ClientID)
+	form.Set("code_verifier", verifier)
+	if p.cfg.ClientSecret != "" {
+		form.Set("client_secret", p.cfg.ClientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Token{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return Token{}, err
+	}
+	defer resp.Body.Close()
+	var token Token
+	if resp.StatusCode != http.StatusOK {
+		return Token{}, fmt.Errorf("token exchange with %s failed: %s", p.Name, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return Token{}, err
+	}
+	if token.AccessToken == "" {
+		return Token{}, fmt.Errorf("token exchange with %s returned no access token", p.Name)
+	}
+	if p.IsOIDC() && token.IDToken == "" {
+		return Token{}, fmt.Errorf("token exchange with %s returned no id_token", p.Name)
+	}
+	return token, nil
+}
+
+// idClaims are the ID token and userinfo claims we read.
+type idClaims struct {
+	Nonce             string `json:"nonce"`
+	Email             string `json:"email"`
+	EmailVerified     any    `json:"email_verified"` // some providers send "true"
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	Login             string `json:"login"` // GitHub
+	ID                any    `json:"id"`    // GitHub numeric user id
+	jwt.RegisteredClaims
+}
+
+// Identity verifies the ID token (issuer, audience, expiry, signature and
+// nonce) and returns the user's identity, completed from userinfo if the
+// token carries no email.
+func (p *Provider) Identity(ctx context.Context, token Token, nonce string) (Identity, error) {
+	if err := p.discover(ctx); err != nil {
+		return Identity{}, err
+	}
+	if !p.IsOIDC() {
+		var info idClaims
+		if err := getJSON(ctx, p.client, p.userInfo, token.AccessToken, &info); err != nil {
+			return Identity{}, err
+		}
+		subject := info.Subject
+		if subject == "" {
+			subject = stringID(info.ID)
+		}
+		if subject == "" {
+			return Identity{}, fmt.Errorf("userinfo from %s has no subject", p.Name)
+		}
+		return identityFrom(subject, info), nil
+	}
+
+	var claims idClaims
+	_, err := jwt.ParseWithClaims(token.IDToken, &claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.keys.get(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithIssuer(p.cfg.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return Identity{}, fmt.Errorf("invalid id_token from %s: %w", p.Name, err)
+	}
+	if claims.Nonce != nonce {
+		return Identity{}, errors.New("id_token nonce mismatch")
+	}
+	if claims.Email == "" && p.userInfo != "" {
+		var info idClaims
+		if err := getJSON(ctx, p.client, p.userInfo, token.AccessToken, &info); err != nil {
+			return Identity{}, err
+		}
+		if info.Subject != claims.Subject {
+			return Identity{}, errors.New("userinfo subject does not match id_token")
+		}
+		info.RegisteredClaims = claims.RegisteredClaims
+		claims = info
+	}
+	return identityFrom(claims.Subject, claims), nil
+}
+
+func identityFrom(subject string, c idClaims) Identity {
+	username := c.PreferredUsername
+	if username == "" {
+		username = c.Login
+	}
+	verified := false
+	switch v := c.EmailVerified.(type) {
+	case bool:
+		verified = v
+	case string:
+		verified, _ = strconv.ParseBool(v)
+	}
+	return Identity{
+		Subject:           subject,
+		Email:             strings.TrimSpace(c.Email),
+		EmailVerified:     verified && c.Email != "",
+		Name:              c.Name,
+		PreferredUsername: username,
+	}
+}
+
+func stringID(v any) string {
+	switch id := v.(type) {
+	case string:
+		return id
+	case float64:
+		return strconv.FormatFloat(id, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+// RandomString returns n random bytes, base64url encoded, for states,
+// nonces and PKCE verifiers.
+func RandomString(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// PKCEChallenge is the S256 code challenge for a verifier (RFC 7636).
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,58 @@
+package oidc
+
+import (
+	"context"
+	"testing"
+
+	"crowdreview/config"
+	"crowdreview/internal/oidc/oidctest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderCodeFlowWithPKCE(t *testing.T) {
+	idp := oidctest.NewServer("crowdreview")
+	defer idp.Close()
+	ctx := context.Background()
+
+	p := NewProvider(config.OIDCProvider{Name: "mock", Issuer: idp.Issuer(), ClientID: "crowdreview"}, "http://app/callback", nil)
+	verifier, err := RandomString(32)
+	require.NoError(t, err)
+	authURL, err := p.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+	require.NoError(t, err)
+	require.NotContains(t, authURL, verifier)
+
+	user := oidctest.User{Subject: "abc", Email: "a@b.com", EmailVerified: true, Name: "Ana"}
+	code, state, err := idp.Authorize(authURL, user)
+	require.NoError(t, err)
+	require.Equal(t, "state-1", state)
+
+	// A wrong verifier is rejected by the provider.
+	_, err = p.Exchange(ctx, code, "wrong")
+	require.Error(t, err)
+
+	code, _, err = idp.Authorize(authURL, user)
+	require.NoError(t, err)
+	token, err := p.Exchange(ctx, code, verifier)
+	require.NoError(t, err)
+
+	identity, err := p.Identity(ctx, token, "nonce-1")
+	require.NoError(t, err)
+	require.Equal(t, Identity{Subject: "abc", Email: "a@b.com", EmailVerified: true, Name: "Ana"}, identity)
+
+	_, err = p.Identity(ctx, token, "other-nonce")
+	require.Error(t, err)
+}
+
+func TestProviderRejectsTokensForOtherClients(t *testing.T) {
+	idp := oidctest.NewServer("someone-else")
+	defer idp.Close()
+	ctx := context.Background()
+
+	// Misconfigured client ID on our side: the provider refuses to authorize.
+	p := NewProvider(config.OIDCProvider{Name: "mock", Issuer: idp.Issuer(), ClientID: "crowdreview"}, "http://app/callback", nil)
+	authURL, err := p.AuthCodeURL(ctx, "s", "n", "verifier")
+	require.NoError(t, err)
+	_, _, err = idp.Authorize(authURL, oidctest.User{Subject: "abc"})
+	require.Error(t, err)
+}
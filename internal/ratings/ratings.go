@@ -0,0 +1,109 @@
+package ratings
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// DefaultPriorWeight is how many "virtual" reviews at the global mean are
+// blended into a company's Bayesian rating.
+const DefaultPriorWeight = 10.0
+
+// DefaultPriorMean is used as the global mean before any review is approved.
+const DefaultPriorMean = 3.0
+
+// Counts are the raw counters maintained per company.
+type Counts struct {
+	Approved int64
+	Sum      int64
+	Stars    [5]int64 // Stars[0] holds 1-star reviews
+}
+
+// Window summarizes approved reviews created within a recent period.
+type Window struct {
+	Days   int     `json:"days"`
+	Count  int64   `json:"count"`
+	Mean   float64 `json:"mean"`
+	Change float64 `json:"change"` // window mean minus overall mean
+}
+
+// Snapshot is the derived view of a company's ratings.
+type Snapshot struct {
+	ApprovedCount int64            `json:"approved_count"`
+	Mean          float64          `json:"mean"`
+	Bayesian      float64          `json:"bayesian"`
+	Histogram     map[string]int64 `json:"histogram"`
+	Trend30       Window           `json:"trend_30d"`
+	Trend90       Window           `json:"trend_90d"`
+	Criteria      []CriterionMean  `json:"criteria"`
+}
+
+// CriterionMean is the average score of one rating criterion.
+type CriterionMean struct {
+	Key   string  `json:"key"`
+	Label string  `json:"label"`
+	Count int64   `json:"count"`
+	Mean  float64 `json:"mean"`
+}
+
+// Mean returns sum/count, or zero when there is nothing to average.
+func Mean(sum, count int64) float64 {
+	if count == 0 {
+		return 0
+	}
+	return round(float64(sum) / float64(count))
+}
+
+// Bayesian shrinks a company's mean towards the global mean so companies with
+// few reviews do not outrank well-established ones.
+func Bayesian(sum, count int64, priorMean, priorWeight float64) float64 {
+	return round((priorWeight*priorMean + float64(sum)) / (priorWeight + float64(count)))
+}
+
+// Build derives a snapshot from the company counters, its 30/90-day window
+// counters and the global mean.
+func Build(c Counts, last30, last90 Counts, priorMean float64) Snapshot {
+	mean := Mean(c.Sum, c.Approved)
+	histogram := make(map[string]int64, len(c.Stars))
+	for i, n := range c.Stars {
+		histogram[string(rune('1'+i))] = n
+	}
+	return Snapshot{
+		ApprovedCount: c.Approved,
+		Mean:          mean,
+		Bayesian:      Bayesian(c.Sum, c.Approved, priorMean, DefaultPriorWeight),
+		Histogram:     histogram,
+		Trend30:       window(30, last30, mean),
+		Trend90:       window(90, last90, mean),
+		Criteria:      []CriterionMean{},
+	}
+}
+
+func window(days int, c Counts, overall float64) Window {
+	w := Window{Days: days, Count: c.Approved, Mean: Mean(c.Sum, c.Approved)}
+	if c.Approved > 0 {
+		w.Change = round(w.Mean - overall)
+	}
+	return w
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// FromCache decodes the snapshot cached under Company.Metrics["ratings"].
+func FromCache(metrics map[string]interface{}) (Snapshot, bool) {
+	raw, ok := metrics["ratings"]
+	if !ok {
+		return Snapshot{}, false
+	}
+	payload, err := json.Marshal(raw)
+	if err != nil {
+		return Snapshot{}, false
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(payload, &snapshot); err != nil {
+		return Snapshot{}, false
+	}
+	return snapshot, true
+}
@@ -0,0 +1,29 @@
+package ratings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBayesianShrinksSmallSamples(t *testing.T) {
+	// A single 5-star review should not beat 100 reviews averaging 4.5.
+	few := Bayesian(5, 1, DefaultPriorMean, DefaultPriorWeight)
+	many := Bayesian(450, 100, DefaultPriorMean, DefaultPriorWeight)
+	require.Less(t, few, many)
+	require.Equal(t, DefaultPriorMean, Bayesian(0, 0, DefaultPriorMean, DefaultPriorWeight))
+}
+
+func TestBuildSnapshot(t *testing.T) {
+	counts := Counts{Approved: 4, Sum: 14, Stars: [5]int64{0, 1, 0, 1, 2}}
+	recent := Counts{Approved: 2, Sum: 10, Stars: [5]int64{0, 0, 0, 0, 2}}
+	snap := Build(counts, recent, counts, 4)
+
+	require.Equal(t, int64(4), snap.ApprovedCount)
+	require.Equal(t, 3.5, snap.Mean)
+	require.Equal(t, int64(2), snap.Histogram["5"])
+	require.Equal(t, int64(1), snap.Histogram["2"])
+	require.Equal(t, 5.0, snap.Trend30.Mean)
+	require.Equal(t, 1.5, snap.Trend30.Change)
+	require.Equal(t, 0.0, snap.Trend90.Change)
+}
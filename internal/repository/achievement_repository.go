@@ -7,12 +7,48 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // AchievementRepository manages gamification data.
 type AchievementRepository interface {
+	// List returns the catalog ordered by points, then name.
 	List(ctx context.Context) ([]models.Achievement, error)
-	Grant(ctx context.Context, userID, achievementID uuid.UUID) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Achievement, error)
+	Create(ctx context.Context, achievement *models.Achievement) error
+	Update(ctx context.Context, achievement *models.Achievement) error
+	// Delete retires the achievement; users keep it if already earned.
+	Delete(ctx context.Context, id uuid.UUID) error
+	// RetiredNames returns the names of deleted achievements.
+	RetiredNames(ctx context.Context) ([]string, error)
+	// Grant awards the achievement once, recording its points in the ledger
+	// against the review that earned it (if any), and reports whether it was
+	// newly granted.
+	Grant(ctx context.Context, userID, achievementID uuid.UUID, points int, reviewID *uuid.UUID) (bool, error)
+	// ListByUser returns the user's achievements, most recent first.
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]models.UserAchievement, error)
+	// ReviewFacts gathers what achievement criteria need to know about the
+	// author of an approved review.
+	ReviewFacts(ctx context.Context, reviewID uuid.UUID) (ReviewFacts, error)
+}
+
+// IndustryCount is the number of approved reviews a user wrote about
+// companies of one industry (by taxonomy ID or free-text name).
+type IndustryCount struct {
+	IndustryID *uuid.UUID
+	Industry   string
+	Reviews    int64
+}
+
+// ReviewFacts describes a review's author and whether the review was the
+// first approved one of its company or industry.
+type ReviewFacts struct {
+	UserID          uuid.UUID
+	Industry        IndustryCount // the review's company industry; Reviews is unset
+	ApprovedReviews int64
+	Industries      []IndustryCount
+	FirstInCompany  bool
+	FirstInIndustry bool
 }
 
 type GormAchievementRepository struct {
@@ -21,16 +57,133 @@ type GormAchievementRepository struct {
 
 func (r *GormAchievementRepository) List(ctx context.Context) ([]models.Achievement, error) {
 	var achievements []models.Achievement
-	if err := r.db.WithContext(ctx).Find(&achievements).Error; err != nil {
+	if err := r.db.WithContext(ctx).Order("points, name").Find(&achievements).Error; err != nil {
 		return nil, err
 	}
 	return achievements, nil
 }
 
-func (r *GormAchievementRepository) Grant(ctx context.Context, userID, achievementID uuid.UUID) error {
-	ua := models.UserAchievement{
-		UserID:        userID,
-		AchievementID: achievementID,
+func (r *GormAchievementRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Achievement, error) {
+	var achievement models.Achievement
+	if err := r.db.WithContext(ctx).First(&achievement, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &achievement, nil
+}
+
+func (r *GormAchievementRepository) Create(ctx context.Context, achievement *models.Achievement) error {
+	return r.db.WithContext(ctx).Create(achievement).Error
+}
+
+func (r *GormAchievementRepository) Update(ctx context.Context, achievement *models.Achievement) error {
+	return r.db.WithContext(ctx).Save(achievement).Error
+}
+
+func (r *GormAchievementRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	res := r.db.WithContext(ctx).Delete(&models.Achievement{}, "id = ?", id)
+	if res.Error == nil && res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
 	}
-	return r.db.WithContext(ctx).Create(&ua).Error
+	return res.Error
+}
+
+func (r *GormAchievementRepository) RetiredNames(ctx context.Context) ([]string, error) {
+	var names []string
+	err := r.db.WithContext(ctx).Unscoped().Model(&models.Achievement{}).
+		Where("deleted_at IS NOT NULL").
+		Distinct().Pluck("name", &names).Error
+	return names, err
+}
+
+func (r *GormAchievementRepository) Grant(ctx context.Context, userID, achievementID uuid.UUID, points int, reviewID *uuid.UUID) (bool, error) {
+	granted := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ua := models.UserAchievement{
+			UserID:        userID,
+			AchievementID: achievementID,
+		}
+		res := tx.Omit("Achievement").Clauses(clause.OnConflict{DoNothing: true}).Create(&ua)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		granted = true
+		entry := models.PointsEntry{
+			UserID:        userID,
+			Delta:         points,
+			Reason:        models.PointsAchievement,
+			ReviewID:      reviewID,
+			AchievementID: &achievementID,
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
+		return recomputeScores(tx, "id = ?", userID)
+	})
+	return granted, err
+}
+
+func (r *GormAchievementRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.UserAchievement, error) {
+	var earned []models.UserAchievement
+	if err := r.db.WithContext(ctx).
+		Preload("Achievement", unscoped).
+		Where("user_id = ?", userID).
+		Order("earned_at DESC").
+		Find(&earned).Error; err != nil {
+		return nil, err
+	}
+	return earned, nil
+}
+
+func (r *GormAchievementRepository) ReviewFacts(ctx context.Context, reviewID uuid.UUID) (ReviewFacts, error) {
+	db := r.db.WithContext(ctx)
+	var review models.Review
+	if err := db.Preload("Company").First(&review, "id = ?", reviewID).Error; err != nil {
+		return ReviewFacts{}, err
+	}
+	facts := ReviewFacts{
+		UserID:   review.UserID,
+		Industry: IndustryCount{IndustryID: review.Company.IndustryID, Industry: review.Company.Industry},
+	}
+
+	if err := db.Model(&models.Review{}).
+		Select("companies.industry_id, companies.industry, count(*) AS reviews").
+		Joins("JOIN companies ON companies.id = reviews.company_id").
+		Where("reviews.user_id = ? AND reviews.status = ?", review.UserID, models.ReviewStatusApproved).
+		Group("companies.industry_id, companies.industry").
+		Scan(&facts.Industries).Error; err != nil {
+		return ReviewFacts{}, err
+	}
+	for _, c := range facts.Industries {
+		facts.ApprovedReviews += c.Reviews
+	}
+
+	earlier := func() *gorm.DB {
+		return db.Model(&models.Review{}).
+			Joins("JOIN companies ON companies.id = reviews.company_id").
+			Where("reviews.status = ? AND reviews.id <> ? AND reviews.created_at <= ?",
+				models.ReviewStatusApproved, review.ID, review.CreatedAt)
+	}
+	var count int64
+	if err := earlier().Where("reviews.company_id = ?", review.CompanyID).Count(&count).Error; err != nil {
+		return ReviewFacts{}, err
+	}
+	facts.FirstInCompany = count == 0
+
+	industry := earlier()
+	if review.Company.IndustryID != nil {
+		industry = industry.Where("companies.industry_id = ?", *review.Company.IndustryID)
+	} else {
+		industry = industry.Where("companies.industry_id IS NULL AND lower(companies.industry) = lower(?)", review.Company.Industry)
+	}
+	if err := industry.Count(&count).Error; err != nil {
+		return ReviewFacts{}, err
+	}
+	facts.FirstInIndustry = count == 0 && (review.Company.IndustryID != nil || review.Company.Industry != "")
+	return facts, nil
+}
+
+// unscoped preloads deleted achievements too, so earned ones keep their
+// name and points.
+func unscoped(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
 }
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+
+	"crowdreview/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AdminUserRepository stores per-admin permission grants.
+type AdminUserRepository interface {
+	GetByUser(ctx context.Context, userID uuid.UUID) (*models.AdminUser, error)
+	// SetAccess updates the user's role and replaces their permission grants.
+	SetAccess(ctx context.Context, userID uuid.UUID, role string, grants datatypes.JSONMap) error
+}
+
+type GormAdminUserRepository struct {
+	db *gorm.DB
+}
+
+func (r *GormAdminUserRepository) GetByUser(ctx context.Context, userID uuid.UUID) (*models.AdminUser, error) {
+	var admin models.AdminUser
+	if err := r.db.WithContext(ctx).First(&admin, "user_id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	return &admin, nil
+}
+
+func (r *GormAdminUserRepository) SetAccess(ctx context.Context, userID uuid.UUID, role string, grants datatypes.JSONMap) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.User{}).Where("id = ?", userID).Update("role", role)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		admin := models.AdminUser{UserID: userID, Permissions: grants}
+		return tx.Omit("User").Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"permissions", "updated_at"}),
+		}).Create(&admin).Error
+	})
+}
@@ -2,11 +2,13 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"crowdreview/internal/models"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // CompanyRepository stores company data.
@@ -15,6 +17,29 @@ type CompanyRepository interface {
 	Update(ctx context.Context, company *models.Company) error
 	List(ctx context.Context) ([]models.Company, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Company, error)
+	GetByDomain(ctx context.Context, domain string) (*models.Company, error)
+	// Each streams every company in primary-key order, in batches.
+	Each(ctx context.Context, batchSize int, fn func([]models.Company) error) error
+	Browse(ctx context.Context, filter CompanyFilter) ([]models.Company, int64, error)
+	ListUnclassified(ctx context.Context) ([]models.Company, error)
+	// ListIdentities returns every live company with its aliases loaded, for
+	// duplicate detection.
+	ListIdentities(ctx context.Context) ([]models.Company, error)
+	// MergedInto reports which company a merged company was folded into.
+	MergedInto(ctx context.Context, id uuid.UUID) (*uuid.UUID, error)
+	Merge(ctx context.Context, sourceID, targetID uuid.UUID) error
+	AddAlias(ctx context.Context, alias *models.CompanyAlias) error
+	DeleteAlias(ctx context.Context, companyID, aliasID uuid.UUID) error
+	// SetOwner assigns the company's owner; nil removes it.
+	SetOwner(ctx context.Context, id uuid.UUID, ownerID *uuid.UUID) error
+}
+
+// CompanyFilter narrows a directory listing. Empty ID slices do not filter.
+type CompanyFilter struct {
+	IndustryIDs []uuid.UUID
+	RegionIDs   []uuid.UUID
+	Limit       int
+	Offset      int
 }
 
 type GormCompanyRepository struct {
@@ -26,12 +51,12 @@ func (r *GormCompanyRepository) Create(ctx context.Context, company *models.Comp
 }
 
 func (r *GormCompanyRepository) Update(ctx context.Context, company *models.Company) error {
-	return r.db.WithContext(ctx).Save(company).Error
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(company).Error
 }
 
 func (r *GormCompanyRepository) List(ctx context.Context) ([]models.Company, error) {
 	var companies []models.Company
-	if err := r.db.WithContext(ctx).Preload("Reviews").Find(&companies).Error; err != nil {
+	if err := r.db.WithContext(ctx).Find(&companies).Error; err != nil {
 		return nil, err
 	}
 	return companies, nil
@@ -39,8 +64,165 @@ func (r *GormCompanyRepository) List(ctx context.Context) ([]models.Company, err
 
 func (r *GormCompanyRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Company, error) {
 	var company models.Company
-	if err := r.db.WithContext(ctx).Preload("Reviews").First(&company, "id = ?", id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Aliases").First(&company, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &company, nil
+}
+
+func (r *GormCompanyRepository) GetByDomain(ctx context.Context, domain string) (*models.Company, error) {
+	var company models.Company
+	if err := r.db.WithContext(ctx).First(&company, "lower(domain) = lower(?)", domain).Error; err != nil {
 		return nil, err
 	}
 	return &company, nil
 }
+
+func (r *GormCompanyRepository) Each(ctx context.Context, batchSize int, fn func([]models.Company) error) error {
+	var batch []models.Company
+	return r.db.WithContext(ctx).FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	}).Error
+}
+
+func (r *GormCompanyRepository) Browse(ctx context.Context, filter CompanyFilter) ([]models.Company, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Company{})
+	if len(filter.IndustryIDs) > 0 {
+		query = query.Where("industry_id IN ?", filter.IndustryIDs)
+	}
+	if len(filter.RegionIDs) > 0 {
+		query = query.Where("region_id IN ?", filter.RegionIDs)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var companies []models.Company
+	if err := query.Order("name").Limit(filter.Limit).Offset(filter.Offset).Find(&companies).Error; err != nil {
+		return nil, 0, err
+	}
+	return companies, total, nil
+}
+
+// ListUnclassified returns companies whose free-text industry or location is
+// not yet linked to the taxonomy.
+func (r *GormCompanyRepository) ListUnclassified(ctx context.Context) ([]models.Company, error) {
+	var companies []models.Company
+	if err := r.db.WithContext(ctx).
+		Where("(industry_id IS NULL AND industry <> '') OR (region_id IS NULL AND location <> '')").
+		Find(&companies).Error; err != nil {
+		return nil, err
+	}
+	return companies, nil
+}
+
+func (r *GormCompanyRepository) ListIdentities(ctx context.Context) ([]models.Company, error) {
+	var companies []models.Company
+	if err := r.db.WithContext(ctx).
+		Select("id", "name", "domain").
+		Preload("Aliases").
+		Find(&companies).Error; err != nil {
+		return nil, err
+	}
+	return companies, nil
+}
+
+func (r *GormCompanyRepository) MergedInto(ctx context.Context, id uuid.UUID) (*uuid.UUID, error) {
+	var company models.Company
+	if err := r.db.WithContext(ctx).Unscoped().
+		Select("id", "merged_into_id").
+		First(&company, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return company.MergedIntoID, nil
+}
+
+// Merge folds source into target in one transaction: reviews, aliases and
+// webhook subscriptions move to target, source's owner becomes target's when
+// it has none, source's name becomes an alias, source is soft-deleted with a
+// redirect to target, and both companies' rating aggregates are rebuilt.
+func (r *GormCompanyRepository) Merge(ctx context.Context, sourceID, targetID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var companies []models.Company
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", []uuid.UUID{sourceID, targetID}).
+			Order("id").
+			Find(&companies).Error; err != nil {
+			return err
+		}
+		if len(companies) != 2 {
+			return gorm.ErrRecordNotFound
+		}
+		source := companies[0]
+		if source.ID != sourceID {
+			source = companies[1]
+		}
+
+		if err := tx.Model(&models.Review{}).Where("company_id = ?", sourceID).
+			Update("company_id", targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.CompanyAlias{}).Where("company_id = ?", sourceID).
+			Update("company_id", targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.CompanyAlias{CompanyID: targetID, Name: source.Name}).Error; err != nil {
+			return err
+		}
+		// Source's webhooks keep following its reviews, now on target.
+		if err := tx.Unscoped().Model(&models.WebhookSubscription{}).Where("company_id = ?", sourceID).
+			Update("company_id", targetID).Error; err != nil {
+			return err
+		}
+		if source.OwnerID != nil {
+			if err := tx.Model(&models.Company{}).Where("id = ? AND owner_id IS NULL", targetID).
+				Update("owner_id", source.OwnerID).Error; err != nil {
+				return err
+			}
+		}
+		// Earlier merges into source now redirect straight to target.
+		if err := tx.Unscoped().Model(&models.Company{}).Where("merged_into_id = ?", sourceID).
+			Update("merged_into_id", targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Company{}).Where("id = ?", sourceID).
+			Updates(map[string]interface{}{"merged_into_id": targetID, "deleted_at": time.Now()}).Error; err != nil {
+			return err
+		}
+
+		if err := rebuildRatingRows(tx, []uuid.UUID{sourceID, targetID}); err != nil {
+			return err
+		}
+		prior, err := globalRatingMean(tx)
+		if err != nil {
+			return err
+		}
+		return refreshRatingCache(tx, targetID, time.Now(), prior)
+	})
+}
+
+func (r *GormCompanyRepository) AddAlias(ctx context.Context, alias *models.CompanyAlias) error {
+	return r.db.WithContext(ctx).Create(alias).Error
+}
+
+func (r *GormCompanyRepository) DeleteAlias(ctx context.Context, companyID, aliasID uuid.UUID) error {
+	res := r.db.WithContext(ctx).Where("company_id = ?", companyID).Delete(&models.CompanyAlias{}, "id = ?", aliasID)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *GormCompanyRepository) SetOwner(ctx context.Context, id uuid.UUID, ownerID *uuid.UUID) error {
+	res := r.db.WithContext(ctx).Model(&models.Company{}).Where("id = ?", id).Update("owner_id", ownerID)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
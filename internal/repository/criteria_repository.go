@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+
+	"crowdreview/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CriteriaRepository stores per-industry rating criteria.
+type CriteriaRepository interface {
+	Create(ctx context.Context, criterion *models.RatingCriterion) error
+	Update(ctx context.Context, criterion *models.RatingCriterion) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.RatingCriterion, error)
+	List(ctx context.Context) ([]models.RatingCriterion, error)
+	ListForIndustry(ctx context.Context, industry string) ([]models.RatingCriterion, error)
+}
+
+type GormCriteriaRepository struct {
+	db *gorm.DB
+}
+
+func (r *GormCriteriaRepository) Create(ctx context.Context, criterion *models.RatingCriterion) error {
+	return r.db.WithContext(ctx).Create(criterion).Error
+}
+
+func (r *GormCriteriaRepository) Update(ctx context.Context, criterion *models.RatingCriterion) error {
+	return r.db.WithContext(ctx).Save(criterion).Error
+}
+
+func (r *GormCriteriaRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.RatingCriterion{}, "id = ?", id).Error
+}
+
+func (r *GormCriteriaRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.RatingCriterion, error) {
+	var criterion models.RatingCriterion
+	if err := r.db.WithContext(ctx).First(&criterion, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &criterion, nil
+}
+
+func (r *GormCriteriaRepository) List(ctx context.Context) ([]models.RatingCriterion, error) {
+	var criteria []models.RatingCriterion
+	if err := r.db.WithContext(ctx).Order("industry, position, key").Find(&criteria).Error; err != nil {
+		return nil, err
+	}
+	return criteria, nil
+}
+
+// ListForIndustry returns the criteria for industry plus the global ones.
+func (r *GormCriteriaRepository) ListForIndustry(ctx context.Context, industry string) ([]models.RatingCriterion, error) {
+	var criteria []models.RatingCriterion
+	if err := r.db.WithContext(ctx).
+		Where("industry = '' OR industry = ?", industry).
+		Order("position, key").
+		Find(&criteria).Error; err != nil {
+		return nil, err
+	}
+	return criteria, nil
+}
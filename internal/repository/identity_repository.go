@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"crowdreview/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdentityRepository stores external login identities and pending
+// authorization states.
+type IdentityRepository interface {
+	GetByProviderSubject(ctx context.Context, provider, subject string) (*models.Identity, error)
+	Create(ctx context.Context, identity *models.Identity) error
+	// CreateUser creates a user together with its first identity.
+	CreateUser(ctx context.Context, user *models.User, identity *models.Identity) error
+	SaveState(ctx context.Context, state *models.OIDCState) error
+	// ConsumeState marks an unused, unexpired state of the provider as used
+	// and returns it, or gorm.ErrRecordNotFound.
+	ConsumeState(ctx context.Context, provider, hash string) (*models.OIDCState, error)
+}
+
+type GormIdentityRepository struct {
+	db *gorm.DB
+}
+
+func (r *GormIdentityRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (*models.Identity, error) {
+	var identity models.Identity
+	if err := r.db.WithContext(ctx).First(&identity, "provider = ? AND subject = ?", provider, subject).Error; err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *GormIdentityRepository) Create(ctx context.Context, identity *models.Identity) error {
+	return r.db.WithContext(ctx).Create(identity).Error
+}
+
+func (r *GormIdentityRepository) CreateUser(ctx context.Context, user *models.User, identity *models.Identity) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return tx.Create(identity).Error
+	})
+}
+
+func (r *GormIdentityRepository) SaveState(ctx context.Context, state *models.OIDCState) error {
+	return r.db.WithContext(ctx).Create(state).Error
+}
+
+func (r *GormIdentityRepository) ConsumeState(ctx context.Context, provider, hash string) (*models.OIDCState, error) {
+	var state models.OIDCState
+	now := time.Now()
+	res := r.db.WithContext(ctx).Model(&state).
+		Clauses(clause.Returning{}).
+		Where("state_hash = ? AND provider = ? AND used_at IS NULL AND expires_at > ?", hash, provider, now).
+		Update("used_at", now)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &state, nil
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"crowdreview/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationRepository stores user notifications and their pending
+// deliveries to external channels.
+type NotificationRepository interface {
+	// Create stores the notification and queues a delivery for each channel.
+	// A notification whose EventID was already stored is skipped.
+	Create(ctx context.Context, n *models.Notification, channels []string) error
+	// ListByUser returns a page of the user's inbox, newest first, and the
+	// number of matching notifications.
+	ListByUser(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]models.Notification, int64, error)
+	CountUnread(ctx context.Context, userID uuid.UUID) (int64, error)
+	// SetRead marks one inbox notification read or unread.
+	SetRead(ctx context.Context, userID, id uuid.UUID, read bool) error
+	MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error)
+	// Claim locks up to limit unsent deliveries of a channel with fewer than
+	// maxAttempts failures, oldest first, with their notifications, by
+	// leasing them until now+lease, so concurrent replicas do not send them
+	// twice.
+	Claim(ctx context.Context, channel string, maxAttempts, limit int, now time.Time, lease time.Duration) ([]models.NotificationDelivery, error)
+	MarkSent(ctx context.Context, ids []uuid.UUID) error
+	// MarkFailed counts a failed attempt for each delivery.
+	MarkFailed(ctx context.Context, ids []uuid.UUID, reason string) error
+}
+
+type GormNotificationRepository struct {
+	db *gorm.DB
+}
+
+func (r *GormNotificationRepository) Create(ctx context.Context, n *models.Notification, channels []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(n)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		for _, channel := range channels {
+			delivery := models.NotificationDelivery{NotificationID: n.ID, UserID: n.UserID, Channel: channel}
+			if err := tx.Create(&delivery).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *GormNotificationRepository) ListByUser(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]models.Notification, int64, error) {
+	db := r.db.WithContext(ctx).Model(&models.Notification{}).Where("user_id = ? AND in_app", userID)
+	if unreadOnly {
+		db = db.Where("read_at IS NULL")
+	}
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var notifications []models.Notification
+	if err := db.Order("created_at DESC").Limit(limit).Offset(offset).Find(&notifications).Error; err != nil {
+		return nil, 0, err
+	}
+	return notifications, total, nil
+}
+
+func (r *GormNotificationRepository) CountUnread(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var unread int64
+	err := r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("user_id = ? AND in_app AND read_at IS NULL", userID).
+		Count(&unread).Error
+	return unread, err
+}
+
+func (r *GormNotificationRepository) SetRead(ctx context.Context, userID, id uuid.UUID, read bool) error {
+	var readAt interface{}
+	if read {
+		readAt = gorm.Expr("COALESCE(read_at, ?)", time.Now())
+	}
+	res := r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("id = ? AND user_id = ? AND in_app", id, userID).
+		Update("read_at", readAt)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *GormNotificationRepository) MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	res := r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("user_id = ? AND in_app AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	return res.RowsAffected, res.Error
+}
+
+func (r *GormNotificationRepository) Claim(ctx context.Context, channel string, maxAttempts, limit int, now time.Time, lease time.Duration) ([]models.NotificationDelivery, error) {
+	var deliveries []models.NotificationDelivery
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []uuid.UUID
+		if err := tx.Model(&models.NotificationDelivery{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("channel = ? AND sent_at IS NULL AND attempts < ?", channel, maxAttempts).
+			Where("claimed_until IS NULL OR claimed_until <= ?", now).
+			Order("created_at").
+			Limit(limit).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := tx.Model(&models.NotificationDelivery{}).
+			Where("id IN ?", ids).
+			Update("claimed_until", now.Add(lease)).Error; err != nil {
+			return err
+		}
+		return tx.Preload("Notification").Where("id IN ?", ids).Order("created_at").Find(&deliveries).Error
+	})
+	return deliveries, err
+}
+
+func (r *GormNotificationRepository) MarkSent(ctx context.Context, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Model(&models.NotificationDelivery{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{"sent_at": time.Now(), "claimed_until": nil}).Error
+}
+
+func (r *GormNotificationRepository) MarkFailed(ctx context.Context, ids []uuid.UUID, reason string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Model(&models.NotificationDelivery{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{"attempts": gorm.Expr("attempts + 1"), "last_error": reason, "claimed_until": nil}).Error
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"crowdreview/internal/models"
+	"crowdreview/pkg/logging"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboxRepository reads the outbox of domain events for the relay. Events
+// are written by the repositories that make the changes, inside their
+// transactions.
+type OutboxRepository interface {
+	// Claim locks up to limit due, unpublished events, oldest first, by
+	// moving their next attempt lease into the future, so concurrent relays
+	// do not publish them twice.
+	Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.OutboxEvent, error)
+	MarkPublished(ctx context.Context, id uuid.UUID) error
+	// MarkFailed counts a failed attempt and schedules the next one.
+	MarkFailed(ctx context.Context, id uuid.UUID, reason string, next time.Time) error
+	// Purge deletes events published before the cutoff.
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+type GormOutboxRepository struct {
+	db *gorm.DB
+}
+
+// recordEvent adds an event to the outbox within tx, so it is relayed if
+// and only if tx commits. The event keeps the request ID of tx's context.
+func recordEvent(tx *gorm.DB, eventType string, aggregateID uuid.UUID, payload map[string]interface{}) error {
+	now := time.Now()
+	event := models.OutboxEvent{
+		Type:          eventType,
+		AggregateID:   aggregateID,
+		Payload:       datatypes.JSONMap{},
+		RequestID:     logging.RequestID(tx.Statement.Context),
+		NextAttemptAt: &now,
+	}
+	for k, v := range payload {
+		event.Payload[k] = v
+	}
+	return tx.Create(&event).Error
+}
+
+func (r *GormOutboxRepository) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []uuid.UUID
+		if err := tx.Model(&models.OutboxEvent{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL AND next_attempt_at <= ?", now).
+			Order("created_at").
+			Limit(limit).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := tx.Model(&models.OutboxEvent{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error; err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", ids).Order("created_at").Find(&events).Error
+	})
+	return events, err
+}
+
+func (r *GormOutboxRepository) MarkPublished(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&models.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"published_at":    time.Now(),
+			"next_attempt_at": nil,
+			"last_error":      "",
+		}).Error
+}
+
+func (r *GormOutboxRepository) MarkFailed(ctx context.Context, id uuid.UUID, reason string, next time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"last_error":      reason,
+			"next_attempt_at": next,
+		}).Error
+}
+
+func (r *GormOutboxRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Unscoped().
+		Where("published_at < ?", before).
+		Delete(&models.OutboxEvent{})
+	return res.RowsAffected, res.Error
+}
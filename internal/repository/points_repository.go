@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"crowdreview/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PointsReversal is the net points a user had earned from a review for one
+// achievement, taken back when the review was rejected.
+type PointsReversal struct {
+	UserID        uuid.UUID
+	AchievementID uuid.UUID
+	Points        int
+	EarnedAt      time.Time
+	Industry      IndustryCount // the review's company industry; Reviews is unset
+}
+
+// PointsRepository manages the append-only points ledger that
+// User.GamificationScore is derived from.
+type PointsRepository interface {
+	// ListByUser returns a user's ledger, most recent first, and its length.
+	ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]models.PointsEntry, int64, error)
+	// Balance is the sum of a user's ledger.
+	Balance(ctx context.Context, userID uuid.UUID) (int, error)
+	// Clawback reverses what every user still holds from the review's
+	// achievements and revokes those achievements, in one transaction.
+	// Repeating it is a no-op.
+	Clawback(ctx context.Context, reviewID uuid.UUID) ([]PointsReversal, error)
+	// Decay takes percent of every achievement grant made before the cutoff
+	// back, once per grant, and returns the users whose score changed.
+	Decay(ctx context.Context, before time.Time, percent int) ([]uuid.UUID, error)
+	// Recompute resets scores to their ledger sums, for one user or (nil)
+	// everyone, and returns how many scores were wrong.
+	Recompute(ctx context.Context, userID *uuid.UUID) (int64, error)
+}
+
+type GormPointsRepository struct {
+	db *gorm.DB
+}
+
+func (r *GormPointsRepository) ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]models.PointsEntry, int64, error) {
+	db := r.db.WithContext(ctx).Model(&models.PointsEntry{}).Where("user_id = ?", userID)
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var entries []models.PointsEntry
+	if err := db.Order("created_at DESC").Limit(limit).Offset(offset).Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+func (r *GormPointsRepository) Balance(ctx context.Context, userID uuid.UUID) (int, error) {
+	var balance int
+	err := r.db.WithContext(ctx).Model(&models.PointsEntry{}).
+		Select("COALESCE(SUM(delta), 0)").
+		Where("user_id = ?", userID).
+		Scan(&balance).Error
+	return balance, err
+}
+
+func (r *GormPointsRepository) Clawback(ctx context.Context, reviewID uuid.UUID) ([]PointsReversal, error) {
+	var reversals []PointsReversal
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var review models.Review
+		if err := tx.Unscoped().Preload("Company").First(&review, "id = ?", reviewID).Error; err != nil {
+			return err
+		}
+		type held struct {
+			UserID        uuid.UUID
+			AchievementID uuid.UUID
+			Points        int
+			EarnedAt      time.Time
+		}
+		var rows []held
+		if err := tx.Model(&models.PointsEntry{}).
+			Select("user_id, achievement_id, SUM(delta) AS points, MIN(created_at) AS earned_at").
+			Where("review_id = ? AND achievement_id IS NOT NULL", reviewID).
+			Group("user_id, achievement_id").
+			Having("SUM(delta) > 0").
+			Scan(&rows).Error; err != nil {
+			return err
+		}
+		industry := IndustryCount{IndustryID: review.Company.IndustryID, Industry: review.Company.Industry}
+		for _, h := range rows {
+			achievementID := h.AchievementID
+			entry := models.PointsEntry{
+				UserID:        h.UserID,
+				Delta:         -h.Points,
+				Reason:        models.PointsClawback,
+				ReviewID:      &reviewID,
+				AchievementID: &achievementID,
+			}
+			if err := tx.Create(&entry).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().
+				Where("user_id = ? AND achievement_id = ?", h.UserID, h.AchievementID).
+				Delete(&models.UserAchievement{}).Error; err != nil {
+				return err
+			}
+			reversals = append(reversals, PointsReversal{
+				UserID:        h.UserID,
+				AchievementID: h.AchievementID,
+				Points:        h.Points,
+				EarnedAt:      h.EarnedAt,
+				Industry:      industry,
+			})
+		}
+		if len(reversals) == 0 {
+			return nil
+		}
+		return recomputeScores(tx, "id IN (SELECT DISTINCT user_id FROM points_ledger WHERE review_id = ?)", reviewID)
+	})
+	return reversals, err
+}
+
+func (r *GormPointsRepository) Decay(ctx context.Context, before time.Time, percent int) ([]uuid.UUID, error) {
+	var users []uuid.UUID
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Grants still held (not clawed back since) and not yet decayed.
+		var decayed []struct{ UserID uuid.UUID }
+		if err := tx.Raw(`
+			INSERT INTO points_ledger (id, created_at, updated_at, user_id, delta, reason, review_id, achievement_id, decay_of)
+			SELECT gen_random_uuid(), now(), now(), g.user_id, -(g.delta * ? / 100), ?, g.review_id, g.achievement_id, g.id
+			FROM points_ledger g
+			WHERE g.reason = ? AND g.deleted_at IS NULL AND g.created_at < ? AND g.delta * ? / 100 > 0
+			  AND NOT EXISTS (SELECT 1 FROM points_ledger d WHERE d.decay_of = g.id)
+			  AND NOT EXISTS (
+				SELECT 1 FROM points_ledger c
+				WHERE c.reason = ? AND c.user_id = g.user_id AND c.achievement_id = g.achievement_id
+				  AND c.created_at >= g.created_at)
+			RETURNING user_id`,
+			percent, models.PointsDecay, models.PointsAchievement, before, percent, models.PointsClawback,
+		).Scan(&decayed).Error; err != nil {
+			return err
+		}
+		seen := map[uuid.UUID]bool{}
+		for _, d := range decayed {
+			if !seen[d.UserID] {
+				seen[d.UserID] = true
+				users = append(users, d.UserID)
+			}
+		}
+		if len(users) == 0 {
+			return nil
+		}
+		return recomputeScores(tx, "id IN ?", users)
+	})
+	return users, err
+}
+
+func (r *GormPointsRepository) Recompute(ctx context.Context, userID *uuid.UUID) (int64, error) {
+	var changed int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		scope := tx.Model(&models.User{}).Unscoped().Where(ledgerMismatch)
+		if userID != nil {
+			scope = scope.Where("id = ?", *userID)
+		}
+		res := scope.Update("gamification_score", gorm.Expr(ledgerSum))
+		changed = res.RowsAffected
+		return res.Error
+	})
+	return changed, err
+}
+
+const (
+	ledgerSum      = "(SELECT COALESCE(SUM(delta), 0) FROM points_ledger WHERE points_ledger.user_id = users.id AND points_ledger.deleted_at IS NULL)"
+	ledgerMismatch = "gamification_score <> " + ledgerSum
+)
+
+// recomputeScores sets the score of the matching users to their ledger sum.
+func recomputeScores(tx *gorm.DB, where string, args ...interface{}) error {
+	return tx.Model(&models.User{}).Unscoped().
+		Where(where, args...).
+		Update("gamification_score", gorm.Expr(ledgerSum)).Error
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"crowdreview/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserData is everything stored about a user, for data portability requests.
+type UserData struct {
+	User           models.User
+	Identities     []models.Identity
+	TwoFactor      *models.TOTPCredential
+	Reviews        []models.Review // with company, scores and moderation results
+	Achievements   []models.UserAchievement
+	Points         []models.PointsEntry
+	Notifications  []models.Notification
+	SecurityEvents []models.SecurityEvent
+}
+
+// PrivacyRepository implements data subject requests and retention limits.
+type PrivacyRepository interface {
+	Export(ctx context.Context, userID uuid.UUID) (*UserData, error)
+	// Erase removes the user's personal data in one transaction. Reviews are
+	// kept for rating aggregates but become anonymous and lose IP,
+	// geolocation and metadata; the user row becomes a soft-deleted tombstone.
+	Erase(ctx context.Context, userID uuid.UUID) error
+	// ScrubReviews clears IP, geolocation and metadata of reviews created
+	// before the cutoff, returning how many were changed.
+	ScrubReviews(ctx context.Context, before time.Time) (int64, error)
+}
+
+type GormPrivacyRepository struct {
+	db *gorm.DB
+}
+
+func (r *GormPrivacyRepository) Export(ctx context.Context, userID uuid.UUID) (*UserData, error) {
+	db := r.db.WithContext(ctx)
+	var data UserData
+	if err := db.First(&data.User, "id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("user_id = ?", userID).Order("created_at").Find(&data.Identities).Error; err != nil {
+		return nil, err
+	}
+	var cred models.TOTPCredential
+	switch err := db.First(&cred, "user_id = ?", userID).Error; {
+	case err == nil:
+		data.TwoFactor = &cred
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+	if err := db.
+		Preload("Company").
+		Preload("Scores.Criterion").
+		Preload("ValidationResult.Signals").
+		Where("user_id = ?", userID).
+		Order("created_at").
+		Find(&data.Reviews).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Preload("Achievement", unscoped).Where("user_id = ?", userID).Order("earned_at").Find(&data.Achievements).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("user_id = ?", userID).Order("created_at").Find(&data.Points).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("user_id = ?", userID).Order("created_at").Find(&data.Notifications).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("user_id = ? OR email = ?", userID, data.User.Email).Order("created_at").Find(&data.SecurityEvents).Error; err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (r *GormPrivacyRepository) Erase(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "id = ?", userID).Error; err != nil {
+			return err
+		}
+		if _, err := scrubReviewPII(tx, "user_id = ?", userID); err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.Review{}).Where("user_id = ?", userID).Update("anonymous", true).Error; err != nil {
+			return err
+		}
+		// Deliveries keep their payload for redelivery; drop the author
+		// from the ones about this user's reviews.
+		reviews := tx.Unscoped().Model(&models.Review{}).Select("id::text").Where("user_id = ?", userID)
+		if err := tx.Unscoped().Model(&models.WebhookDelivery{}).
+			Where("payload #>> '{data,review,id}' IN (?)", reviews).
+			Update("payload", gorm.Expr(`jsonb_set(payload #- '{data,review,author}', '{data,review,anonymous}', 'true')`)).Error; err != nil {
+			return err
+		}
+		owned := []interface{}{
+			&models.Identity{},
+			&models.TOTPCredential{},
+			&models.RecoveryCode{},
+			&models.UserToken{},
+			&models.RefreshToken{},
+			&models.UserAchievement{},
+			&models.PointsEntry{},
+			&models.NotificationDelivery{},
+			&models.Notification{},
+			&models.AdminUser{},
+		}
+		for _, model := range owned {
+			if err := tx.Unscoped().Where("user_id = ?", userID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Unscoped().Where("user_id = ? OR email = ?", userID, user.Email).Delete(&models.SecurityEvent{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.Company{}).Where("owner_id = ?", userID).Update("owner_id", nil).Error; err != nil {
+			return err
+		}
+		tombstone := fmt.Sprintf("deleted-%s", userID)
+		return tx.Model(&user).Updates(map[string]interface{}{
+			"email":              tombstone + "@deleted.invalid",
+			"username":           tombstone,
+			"password_hash":      "!",
+			"role":               models.RoleUser,
+			"gamification_score": 0,
+			"email_verified_at":  nil,
+			"profile_meta":       datatypes.JSONMap{},
+			"deleted_at":         time.Now(),
+		}).Error
+	})
+}
+
+func (r *GormPrivacyRepository) ScrubReviews(ctx context.Context, before time.Time) (int64, error) {
+	var scrubbed int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		scrubbed, err = scrubReviewPII(tx,
+			"created_at < ? AND (ip_address <> '' OR geo_location <> '' OR metadata <> '{}'::jsonb)", before)
+		return err
+	})
+	return scrubbed, err
+}
+
+// scrubReviewPII clears IP, geolocation and metadata from the matching
+// reviews (soft-deleted ones included) and from the fraud checks and
+// signals that copied them.
+func scrubReviewPII(tx *gorm.DB, where string, args ...interface{}) (int64, error) {
+	reviews := tx.Unscoped().Model(&models.Review{}).Select("id").Where(where, args...)
+	results := tx.Unscoped().Model(&models.ReviewValidationResult{}).Select("id").Where("review_id IN (?)", reviews)
+	if err := tx.Unscoped().Model(&models.FraudSignal{}).
+		Where("validation_result_id IN (?)", results).
+		Update("details", gorm.Expr("details - 'geo' - 'ip'")).Error; err != nil {
+		return 0, err
+	}
+	if err := tx.Unscoped().Model(&models.ReviewValidationResult{}).
+		Where("review_id IN (?)", reviews).
+		Update("checks", gorm.Expr("checks #- '{geolocation,geo}' #- '{ip_presence,ip}'")).Error; err != nil {
+		return 0, err
+	}
+	res := tx.Unscoped().Model(&models.Review{}).Where(where, args...).Updates(map[string]interface{}{
+		"ip_address":   "",
+		"geo_location": "",
+		"metadata":     datatypes.JSONMap{},
+	})
+	return res.RowsAffected, res.Error
+}
@@ -4,22 +4,54 @@ import "gorm.io/gorm"
 
 // Repositories aggregates all repo interfaces for easy injection.
 type Repositories struct {
-	User        UserRepository
-	Company     CompanyRepository
-	Review      ReviewRepository
-	Validation  ValidationRepository
-	Achievement AchievementRepository
-	DB          *gorm.DB
+	User          UserRepository
+	Company       CompanyRepository
+	Review        ReviewRepository
+	Validation    ValidationRepository
+	Achievement   AchievementRepository
+	Rating        RatingRepository
+	Criteria      CriteriaRepository
+	Search        SearchRepository
+	Taxonomy      TaxonomyRepository
+	Tokens        RefreshTokenRepository
+	Keys          SigningKeyRepository
+	UserTokens    UserTokenRepository
+	Security      SecurityEventRepository
+	TwoFactor     TwoFactorRepository
+	Identities    IdentityRepository
+	Admins        AdminUserRepository
+	Privacy       PrivacyRepository
+	Points        PointsRepository
+	Notifications NotificationRepository
+	Webhooks      WebhookRepository
+	Outbox        OutboxRepository
+	DB            *gorm.DB
 }
 
 // NewRepositories wires GORM implementations.
 func NewRepositories(db *gorm.DB) Repositories {
 	return Repositories{
-		User:        &GormUserRepository{db},
-		Company:     &GormCompanyRepository{db},
-		Review:      &GormReviewRepository{db},
-		Validation:  &GormValidationRepository{db},
-		Achievement: &GormAchievementRepository{db},
-		DB:          db,
+		User:          &GormUserRepository{db},
+		Company:       &GormCompanyRepository{db},
+		Review:        &GormReviewRepository{db},
+		Validation:    &GormValidationRepository{db},
+		Achievement:   &GormAchievementRepository{db},
+		Rating:        &GormRatingRepository{db},
+		Criteria:      &GormCriteriaRepository{db},
+		Search:        &GormSearchRepository{db},
+		Taxonomy:      &GormTaxonomyRepository{db},
+		Tokens:        &GormRefreshTokenRepository{db},
+		Keys:          &GormSigningKeyRepository{db},
+		UserTokens:    &GormUserTokenRepository{db},
+		Security:      &GormSecurityEventRepository{db},
+		TwoFactor:     &GormTwoFactorRepository{db},
+		Identities:    &GormIdentityRepository{db},
+		Admins:        &GormAdminUserRepository{db},
+		Privacy:       &GormPrivacyRepository{db},
+		Points:        &GormPointsRepository{db},
+		Notifications: &GormNotificationRepository{db},
+		Webhooks:      &GormWebhookRepository{db},
+		Outbox:        &GormOutboxRepository{db},
+		DB:            db,
 	}
 }
@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"crowdreview/internal/models"
+	"crowdreview/internal/ratings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RatingRepository reads and rebuilds company rating aggregates. The counters
+// themselves are adjusted by the review status transitions in the same
+// transaction as the status change.
+type RatingRepository interface {
+	Snapshot(ctx context.Context, companyID uuid.UUID) (ratings.Snapshot, error)
+	Rebuild(ctx context.Context) error
+}
+
+type GormRatingRepository struct {
+	db *gorm.DB
+}
+
+// Snapshot computes the snapshot on read, against the current global mean.
+func (r *GormRatingRepository) Snapshot(ctx context.Context, companyID uuid.UUID) (ratings.Snapshot, error) {
+	db := r.db.WithContext(ctx)
+	prior, err := globalRatingMean(db)
+	if err != nil {
+		return ratings.Snapshot{}, err
+	}
+	return loadRatingSnapshot(db, companyID, time.Now(), prior)
+}
+
+// Rebuild recomputes every counter from the reviews table and refreshes the
+// cached snapshot of each company. Concurrent transitions wait on the table
+// locks and apply their deltas on top of the rebuilt counters.
+func (r *GormRatingRepository) Rebuild(ctx context.Context) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := rebuildRatingRows(tx, nil); err != nil {
+			return err
+		}
+		var ids []uuid.UUID
+		if err := tx.Model(&models.Company{}).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		prior, err := globalRatingMean(tx)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		for _, id := range ids {
+			if err := refreshRatingCache(tx, id, now, prior); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// rebuildRatingRows recomputes the counter rows of the given companies, or of
+// every company when companyIDs is nil, from their approved reviews.
+func rebuildRatingRows(tx *gorm.DB, companyIDs []uuid.UUID) error {
+	if err := tx.Exec(`LOCK TABLE company_rating_stats, company_rating_days, company_criterion_stats IN EXCLUSIVE MODE`).Error; err != nil {
+		return err
+	}
+	filter, reviewFilter := "TRUE", "TRUE"
+	args := []interface{}{}
+	if companyIDs != nil {
+		filter, reviewFilter = "company_id IN ?", "r.company_id IN ?"
+		args = append(args, companyIDs)
+	}
+	for _, table := range []string{"company_criterion_stats", "company_rating_days", "company_rating_stats"} {
+		if err := tx.Exec(`DELETE FROM `+table+` WHERE `+filter, args...).Error; err != nil {
+			return err
+		}
+	}
+
+	args = append([]interface{}{models.ReviewStatusApproved}, args...)
+	if err := tx.Exec(`
+		INSERT INTO company_rating_stats (company_id, approved_count, rating_sum, star1, star2, star3, star4, star5, updated_at)
+		SELECT company_id, COUNT(*), SUM(rating),
+			COUNT(*) FILTER (WHERE rating = 1), COUNT(*) FILTER (WHERE rating = 2),
+			COUNT(*) FILTER (WHERE rating = 3), COUNT(*) FILTER (WHERE rating = 4),
+			COUNT(*) FILTER (WHERE rating = 5), now()
+		FROM reviews
+		WHERE status = ? AND deleted_at IS NULL AND `+filter+`
+		GROUP BY company_id`, args...).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec(`
+		INSERT INTO company_rating_days (company_id, day, approved_count, rating_sum)
+		SELECT company_id, (created_at AT TIME ZONE 'UTC')::date, COUNT(*), SUM(rating)
+		FROM reviews
+		WHERE status = ? AND deleted_at IS NULL AND `+filter+`
+		GROUP BY 1, 2`, args...).Error; err != nil {
+		return err
+	}
+	return tx.Exec(`
+		INSERT INTO company_criterion_stats (company_id, criterion_id, score_count, score_sum)
+		SELECT r.company_id, s.criterion_id, COUNT(*), SUM(s.score)
+		FROM review_scores s
+		JOIN reviews r ON r.id = s.review_id
+		WHERE r.status = ? AND r.deleted_at IS NULL AND `+reviewFilter+`
+		GROUP BY 1, 2`, args...).Error
+}
+
+// applyRatingTransition adjusts the counters when a review enters or leaves the
+// approved state. It must run inside the transaction that changes the status.
+func applyRatingTransition(tx *gorm.DB, review models.Review, from, to string) error {
+	var delta int64
+	switch {
+	case from != models.ReviewStatusApproved && to == models.ReviewStatusApproved:
+		delta = 1
+	case from == models.ReviewStatusApproved && to != models.ReviewStatusApproved:
+		delta = -1
+	default:
+		return nil
+	}
+	if review.Rating < 1 || review.Rating > 5 {
+		return fmt.Errorf("review %s has invalid rating %d", review.ID, review.Rating)
+	}
+
+	now := time.Now()
+	star := fmt.Sprintf("star%d", review.Rating)
+	if err := tx.Exec(`
+		INSERT INTO company_rating_stats (company_id, approved_count, rating_sum, `+star+`, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (company_id) DO UPDATE SET
+			approved_count = company_rating_stats.approved_count + EXCLUDED.approved_count,
+			rating_sum = company_rating_stats.rating_sum + EXCLUDED.rating_sum,
+			`+star+` = company_rating_stats.`+star+` + EXCLUDED.`+star+`,
+			updated_at = EXCLUDED.updated_at`,
+		review.CompanyID, delta, delta*int64(review.Rating), delta, now).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec(`
+		INSERT INTO company_rating_days (company_id, day, approved_count, rating_sum)
+		VALUES (?, ?::date, ?, ?)
+		ON CONFLICT (company_id, day) DO UPDATE SET
+			approved_count = company_rating_days.approved_count + EXCLUDED.approved_count,
+			rating_sum = company_rating_days.rating_sum + EXCLUDED.rating_sum`,
+		review.CompanyID, utcDay(review.CreatedAt), delta, delta*int64(review.Rating)).Error; err != nil {
+		return err
+	}
+
+	var scores []models.ReviewScore
+	if err := tx.Where("review_id = ?", review.ID).Find(&scores).Error; err != nil {
+		return err
+	}
+	for _, score := range scores {
+		if err := tx.Exec(`
+			INSERT INTO company_criterion_stats (company_id, criterion_id, score_count, score_sum)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT (company_id, criterion_id) DO UPDATE SET
+				score_count = company_criterion_stats.score_count + EXCLUDED.score_count,
+				score_sum = company_criterion_stats.score_sum + EXCLUDED.score_sum`,
+			review.CompanyID, score.CriterionID, delta, delta*int64(score.Score)).Error; err != nil {
+			return err
+		}
+	}
+	prior, err := globalRatingMean(tx)
+	if err != nil {
+		return err
+	}
+	return refreshRatingCache(tx, review.CompanyID, now, prior)
+}
+
+// refreshRatingCache stores the current snapshot under Company.Metrics["ratings"].
+// Its Bayesian rating uses the global mean passed in, so the cached value
+// drifts from Snapshot as other companies are reviewed until the company is
+// refreshed again or the aggregates are rebuilt.
+func refreshRatingCache(tx *gorm.DB, companyID uuid.UUID, now time.Time, prior float64) error {
+	snapshot, err := loadRatingSnapshot(tx, companyID, now, prior)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	return tx.Model(&models.Company{}).
+		Where("id = ?", companyID).
+		UpdateColumn("metrics", gorm.Expr("jsonb_set(COALESCE(metrics, '{}'::jsonb), '{ratings}', ?::jsonb)", string(payload))).Error
+}
+
+func loadRatingSnapshot(db *gorm.DB, companyID uuid.UUID, now time.Time, prior float64) (ratings.Snapshot, error) {
+	var stats models.CompanyRatingStats
+	if err := db.Where("company_id = ?", companyID).Limit(1).Find(&stats).Error; err != nil {
+		return ratings.Snapshot{}, err
+	}
+	counts := ratings.Counts{
+		Approved: stats.ApprovedCount,
+		Sum:      stats.RatingSum,
+		Stars:    [5]int64{stats.Star1, stats.Star2, stats.Star3, stats.Star4, stats.Star5},
+	}
+	last30, err := ratingWindow(db, companyID, now, 30)
+	if err != nil {
+		return ratings.Snapshot{}, err
+	}
+	last90, err := ratingWindow(db, companyID, now, 90)
+	if err != nil {
+		return ratings.Snapshot{}, err
+	}
+	snapshot := ratings.Build(counts, last30, last90, prior)
+	snapshot.Criteria, err = criterionMeans(db, companyID)
+	if err != nil {
+		return ratings.Snapshot{}, err
+	}
+	return snapshot, nil
+}
+
+func criterionMeans(db *gorm.DB, companyID uuid.UUID) ([]ratings.CriterionMean, error) {
+	var rows []struct {
+		Key   string
+		Label string
+		Count int64
+		Sum   int64
+	}
+	if err := db.Table("company_criterion_stats AS s").
+		Select("c.key, c.label, s.score_count AS count, s.score_sum AS sum").
+		Joins("JOIN rating_criteria c ON c.id = s.criterion_id AND c.deleted_at IS NULL").
+		Where("s.company_id = ? AND s.score_count > 0", companyID).
+		Order("c.position, c.key").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	means := make([]ratings.CriterionMean, 0, len(rows))
+	for _, row := range rows {
+		means = append(means, ratings.CriterionMean{
+			Key:   row.Key,
+			Label: row.Label,
+			Count: row.Count,
+			Mean:  ratings.Mean(row.Sum, row.Count),
+		})
+	}
+	return means, nil
+}
+
+func ratingWindow(db *gorm.DB, companyID uuid.UUID, now time.Time, days int) (ratings.Counts, error) {
+	var row struct {
+		Count int64
+		Sum   int64
+	}
+	since := utcDay(now.AddDate(0, 0, -(days - 1)))
+	if err := db.Model(&models.CompanyRatingDay{}).
+		Select("COALESCE(SUM(approved_count), 0) AS count, COALESCE(SUM(rating_sum), 0) AS sum").
+		Where("company_id = ? AND day >= ?::date", companyID, since).
+		Scan(&row).Error; err != nil {
+		return ratings.Counts{}, err
+	}
+	return ratings.Counts{Approved: row.Count, Sum: row.Sum}, nil
+}
+
+func globalRatingMean(db *gorm.DB) (float64, error) {
+	var row struct {
+		Count int64
+		Sum   int64
+	}
+	if err := db.Model(&models.CompanyRatingStats{}).
+		Select("COALESCE(SUM(approved_count), 0) AS count, COALESCE(SUM(rating_sum), 0) AS sum").
+		Scan(&row).Error; err != nil {
+		return 0, err
+	}
+	if row.Count == 0 {
+		return ratings.DefaultPriorMean, nil
+	}
+	return float64(row.Sum) / float64(row.Count), nil
+}
+
+func utcDay(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
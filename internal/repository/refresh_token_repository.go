@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"crowdreview/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrRefreshTokenReused reports that an already rotated or revoked refresh
+// token was presented. Its family has been revoked by the time it is returned.
+var ErrRefreshTokenReused = errors.New("refresh token reused")
+
+// RefreshTokenRepository stores refresh token families.
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *models.RefreshToken) error
+	GetByHash(ctx context.Context, hash string) (*models.RefreshToken, error)
+	// Consume marks the token as used so it can be rotated exactly once.
+	Consume(ctx context.Context, hash string) (*models.RefreshToken, error)
+	RevokeFamily(ctx context.Context, familyID uuid.UUID) error
+	RevokeUser(ctx context.Context, userID uuid.UUID) error
+}
+
+type GormRefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func (r *GormRefreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *GormRefreshTokenRepository) GetByHash(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := r.db.WithContext(ctx).First(&token, "token_hash = ?", hash).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *GormRefreshTokenRepository) Consume(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	reused := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&token, "token_hash = ?", hash).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		if token.UsedAt != nil || token.RevokedAt != nil {
+			// Commit the revocation; the error is reported after the transaction.
+			reused = true
+			return revokeFamily(tx, token.FamilyID, now)
+		}
+		token.UsedAt = &now
+		return tx.Model(&token).Update("used_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return &token, ErrRefreshTokenReused
+	}
+	return &token, nil
+}
+
+func (r *GormRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	return revokeFamily(r.db.WithContext(ctx), familyID, time.Now())
+}
+
+func (r *GormRefreshTokenRepository) RevokeUser(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func revokeFamily(tx *gorm.DB, familyID uuid.UUID, at time.Time) error {
+	return tx.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", at).Error
+}
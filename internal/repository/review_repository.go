@@ -2,19 +2,39 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"crowdreview/internal/models"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ReviewRepository stores reviews and aggregates.
 type ReviewRepository interface {
+	// Create stores the review and records its review.created event.
 	Create(ctx context.Context, review *models.Review) error
-	ListByCompany(ctx context.Context, companyID uuid.UUID) ([]models.Review, error)
+	// GetByID returns a review with its company and author.
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Review, error)
+	ListByCompany(ctx context.Context, companyID uuid.UUID, visibility ReviewVisibility) ([]models.Review, error)
+	// ListPublicByUser returns the user's approved, non-anonymous reviews,
+	// newest first.
+	ListPublicByUser(ctx context.Context, userID uuid.UUID, limit int) ([]models.Review, error)
 	ListSuspicious(ctx context.Context) ([]models.Review, error)
 	Respond(ctx context.Context, id uuid.UUID, status string) error
+	// Claim assigns a pending or flagged review to the moderator unless
+	// another moderator claimed it after staleBefore, reporting whether it
+	// did. Claiming again renews the claim.
+	Claim(ctx context.Context, id, moderatorID uuid.UUID, staleBefore time.Time) (bool, error)
+	// Release clears the moderator's claim, reporting whether they held it.
+	Release(ctx context.Context, id, moderatorID uuid.UUID) (bool, error)
+}
+
+// ReviewVisibility narrows a listing to the reviews a viewer may see.
+type ReviewVisibility struct {
+	Statuses []string   // empty means every status
+	AuthorID *uuid.UUID // the author's own reviews are included regardless of status
 }
 
 type GormReviewRepository struct {
@@ -22,15 +42,50 @@ type GormReviewRepository struct {
 }
 
 func (r *GormReviewRepository) Create(ctx context.Context, review *models.Review) error {
-	return r.db.WithContext(ctx).Create(review).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(review).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, models.OutboxReviewCreated, review.ID, nil)
+	})
 }
 
-func (r *GormReviewRepository) ListByCompany(ctx context.Context, companyID uuid.UUID) ([]models.Review, error) {
+func (r *GormReviewRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Review, error) {
+	var review models.Review
+	if err := r.db.WithContext(ctx).Preload("Company").Preload("User").First(&review, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *GormReviewRepository) ListByCompany(ctx context.Context, companyID uuid.UUID, visibility ReviewVisibility) ([]models.Review, error) {
 	var reviews []models.Review
-	if err := r.db.WithContext(ctx).
+	query := r.db.WithContext(ctx).
 		Preload("User").
-		Where("company_id = ?", companyID).
+		Preload("Scores.Criterion").
+		Where("company_id = ?", companyID)
+	if len(visibility.Statuses) > 0 {
+		if visibility.AuthorID != nil {
+			query = query.Where("(status IN ? OR user_id = ?)", visibility.Statuses, *visibility.AuthorID)
+		} else {
+			query = query.Where("status IN ?", visibility.Statuses)
+		}
+	}
+	if err := query.
+		Order("created_at DESC").
+		Find(&reviews).Error; err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+func (r *GormReviewRepository) ListPublicByUser(ctx context.Context, userID uuid.UUID, limit int) ([]models.Review, error) {
+	var reviews []models.Review
+	if err := r.db.WithContext(ctx).
+		Preload("Scores.Criterion").
+		Where("user_id = ? AND status = ? AND NOT anonymous", userID, models.ReviewStatusApproved).
 		Order("created_at DESC").
+		Limit(limit).
 		Find(&reviews).Error; err != nil {
 		return nil, err
 	}
@@ -46,5 +101,50 @@ func (r *GormReviewRepository) ListSuspicious(ctx context.Context) ([]models.Rev
 }
 
 func (r *GormReviewRepository) Respond(ctx context.Context, id uuid.UUID, status string) error {
-	return r.db.WithContext(ctx).Model(&models.Review{}).Where("id = ?", id).Update("status", status).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return transitionReview(tx, id, status, nil)
+	})
+}
+
+func (r *GormReviewRepository) Claim(ctx context.Context, id, moderatorID uuid.UUID, staleBefore time.Time) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&models.Review{}).
+		Where("id = ? AND status IN ?", id, []string{models.ReviewStatusPending, models.ReviewStatusFlagged}).
+		Where("(claimed_by_id IS NULL OR claimed_by_id = ? OR claimed_at < ?)", moderatorID, staleBefore).
+		Updates(map[string]interface{}{"claimed_by_id": moderatorID, "claimed_at": time.Now()})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *GormReviewRepository) Release(ctx context.Context, id, moderatorID uuid.UUID) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&models.Review{}).
+		Where("id = ? AND claimed_by_id = ?", id, moderatorID).
+		Updates(map[string]interface{}{"claimed_by_id": nil, "claimed_at": nil})
+	return res.RowsAffected > 0, res.Error
+}
+
+// transitionReview locks the review, sets its status along with any extra
+// columns, ends any moderator claim, keeps the company rating aggregates in
+// step and records a review.status_changed event when the status actually
+// changes.
+func transitionReview(tx *gorm.DB, id uuid.UUID, status string, extra map[string]interface{}) error {
+	var review models.Review
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&review, "id = ?", id).Error; err != nil {
+		return err
+	}
+	updates := map[string]interface{}{"status": status, "claimed_by_id": nil, "claimed_at": nil}
+	for k, v := range extra {
+		updates[k] = v
+	}
+	if err := tx.Model(&models.Review{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return err
+	}
+	if err := applyRatingTransition(tx, review, review.Status, status); err != nil {
+		return err
+	}
+	if review.Status == status {
+		return nil
+	}
+	return recordEvent(tx, models.OutboxReviewStatusChanged, id, map[string]interface{}{
+		"status":          status,
+		"previous_status": review.Status,
+	})
 }
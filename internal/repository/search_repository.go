@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"crowdreview/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Highlight markers emitted by ts_headline. They are control characters so
+// callers can escape the snippet before turning them into markup.
+const (
+	HighlightStart = "\x02"
+	HighlightStop  = "\x03"
+)
+
+// SearchQuery parameterizes a full-text search.
+type SearchQuery struct {
+	Text     string
+	Industry string // optional exact (case-insensitive) filter
+	Location string // optional exact (case-insensitive) filter
+	Limit    int
+	Offset   int
+}
+
+// CompanyHit is a ranked company search result.
+type CompanyHit struct {
+	ID       uuid.UUID
+	Name     string
+	Industry string
+	Location string
+	Rank     float64
+	Snippet  string
+}
+
+// ReviewHit is a ranked review search result.
+type ReviewHit struct {
+	ID          uuid.UUID
+	CompanyID   uuid.UUID
+	CompanyName string
+	Title       string
+	Rating      int
+	Rank        float64
+	Snippet     string
+	CreatedAt   time.Time
+}
+
+// Facet is a value with the number of matching companies.
+type Facet struct {
+	Value string
+	Count int64
+}
+
+// SearchRepository runs full-text queries over companies and reviews.
+type SearchRepository interface {
+	SearchCompanies(ctx context.Context, q SearchQuery) ([]CompanyHit, int64, error)
+	SearchReviews(ctx context.Context, q SearchQuery) ([]ReviewHit, int64, error)
+	CompanyFacets(ctx context.Context, q SearchQuery) (industries []Facet, locations []Facet, err error)
+}
+
+type GormSearchRepository struct {
+	db *gorm.DB
+}
+
+// tsQuery matches the text under both text configurations.
+const tsQuery = `(websearch_to_tsquery('portuguese', @text) || websearch_to_tsquery('english', @text))`
+
+const headlineOptions = `'StartSel=` + HighlightStart + `, StopSel=` + HighlightStop + `, MaxFragments=2, MaxWords=25, MinWords=8'`
+
+func (r *GormSearchRepository) SearchCompanies(ctx context.Context, q SearchQuery) ([]CompanyHit, int64, error) {
+	where, args := companyMatch(q)
+
+	var total int64
+	if err := r.db.WithContext(ctx).Raw(`SELECT COUNT(*) FROM companies c WHERE `+where, args).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var hits []CompanyHit
+	args["limit"] = q.Limit
+	args["offset"] = q.Offset
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT c.id, c.name, c.industry, c.location,
+			ts_rank_cd(c.search_vector, `+tsQuery+`) AS rank,
+			ts_headline('portuguese', coalesce(NULLIF(c.description, ''), c.name), `+tsQuery+`, `+headlineOptions+`) AS snippet
+		FROM companies c
+		WHERE `+where+`
+		ORDER BY rank DESC, c.name
+		LIMIT @limit OFFSET @offset`, args).Scan(&hits).Error; err != nil {
+		return nil, 0, err
+	}
+	return hits, total, nil
+}
+
+// SearchReviews only ever returns approved reviews.
+func (r *GormSearchRepository) SearchReviews(ctx context.Context, q SearchQuery) ([]ReviewHit, int64, error) {
+	where, args := companyMatch(SearchQuery{Industry: q.Industry, Location: q.Location})
+	where = `r.deleted_at IS NULL AND r.status = @approved AND r.search_vector @@ ` + tsQuery + ` AND ` + where
+	args["text"] = q.Text
+	args["approved"] = models.ReviewStatusApproved
+
+	var total int64
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT COUNT(*) FROM reviews r JOIN companies c ON c.id = r.company_id
+		WHERE `+where, args).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var hits []ReviewHit
+	args["limit"] = q.Limit
+	args["offset"] = q.Offset
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT r.id, r.company_id, c.name AS company_name, r.title, r.rating, r.created_at,
+			ts_rank_cd(r.search_vector, `+tsQuery+`) AS rank,
+			ts_headline('portuguese', r.content, `+tsQuery+`, `+headlineOptions+`) AS snippet
+		FROM reviews r JOIN companies c ON c.id = r.company_id
+		WHERE `+where+`
+		ORDER BY rank DESC, r.created_at DESC
+		LIMIT @limit OFFSET @offset`, args).Scan(&hits).Error; err != nil {
+		return nil, 0, err
+	}
+	return hits, total, nil
+}
+
+// CompanyFacets counts matching companies per industry and per location. The
+// facet filters themselves are ignored so every option stays selectable.
+func (r *GormSearchRepository) CompanyFacets(ctx context.Context, q SearchQuery) ([]Facet, []Facet, error) {
+	where, args := companyMatch(SearchQuery{Text: q.Text})
+	industries, err := r.facet(ctx, "industry", where, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	locations, err := r.facet(ctx, "location", where, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return industries, locations, nil
+}
+
+func (r *GormSearchRepository) facet(ctx context.Context, column, where string, args map[string]interface{}) ([]Facet, error) {
+	var facets []Facet
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT c.`+column+` AS value, COUNT(*) AS count
+		FROM companies c
+		WHERE `+where+` AND coalesce(c.`+column+`, '') <> ''
+		GROUP BY c.`+column+`
+		ORDER BY count DESC, value
+		LIMIT 20`, args).Scan(&facets).Error; err != nil {
+		return nil, err
+	}
+	return facets, nil
+}
+
+// companyMatch builds the WHERE clause over companies aliased as c.
+func companyMatch(q SearchQuery) (string, map[string]interface{}) {
+	clauses := []string{"c.deleted_at IS NULL"}
+	args := map[string]interface{}{}
+	if q.Text != "" {
+		clauses = append(clauses, "c.search_vector @@ "+tsQuery)
+		args["text"] = q.Text
+	}
+	if q.Industry != "" {
+		clauses = append(clauses, "lower(c.industry) = lower(@industry)")
+		args["industry"] = q.Industry
+	}
+	if q.Location != "" {
+		clauses = append(clauses, "lower(c.location) = lower(@location)")
+		args["location"] = q.Location
+	}
+	return strings.Join(clauses, " AND "), args
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+
+	"crowdreview/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SecurityEventFilter narrows an admin listing of security events.
+type SecurityEventFilter struct {
+	Type      string
+	UserID    *uuid.UUID
+	IPAddress string
+	Limit     int
+	Offset    int
+}
+
+// SecurityEventRepository stores the authentication audit trail.
+type SecurityEventRepository interface {
+	Record(ctx context.Context, event *models.SecurityEvent) error
+	List(ctx context.Context, filter SecurityEventFilter) ([]models.SecurityEvent, int64, error)
+	// KnownIP reports whether the user has logged in from ip before.
+	KnownIP(ctx context.Context, userID uuid.UUID, ip string) (bool, error)
+}
+
+type GormSecurityEventRepository struct {
+	db *gorm.DB
+}
+
+func (r *GormSecurityEventRepository) Record(ctx context.Context, event *models.SecurityEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+func (r *GormSecurityEventRepository) List(ctx context.Context, filter SecurityEventFilter) ([]models.SecurityEvent, int64, error) {
+	q := r.db.WithContext(ctx).Model(&models.SecurityEvent{})
+	if filter.Type != "" {
+		q = q.Where("type = ?", filter.Type)
+	}
+	if filter.UserID != nil {
+		q = q.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.IPAddress != "" {
+		q = q.Where("ip_address = ?", filter.IPAddress)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var events []models.SecurityEvent
+	err := q.Order("created_at DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&events).Error
+	return events, total, err
+}
+
+// KnownIP relies on every first login from an address being recorded as a
+// login_new_ip event, so those events double as the set of known addresses.
+func (r *GormSecurityEventRepository) KnownIP(ctx context.Context, userID uuid.UUID, ip string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.SecurityEvent{}).
+		Where("user_id = ? AND ip_address = ? AND type = ?", userID, ip, models.SecurityLoginNewIP).
+		Limit(1).Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"crowdreview/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// signingKeyLock is the Postgres advisory lock serializing key rotation
+// across API instances.
+const signingKeyLock = 4_181_034
+
+// SigningKeyRepository stores JWT signing keys.
+type SigningKeyRepository interface {
+	// List returns the keys that have not expired.
+	List(ctx context.Context, now time.Time) ([]models.SigningKey, error)
+	// Rotate calls plan with the unexpired keys while holding a cluster-wide
+	// lock and stores the key it returns, if any.
+	Rotate(ctx context.Context, now time.Time, plan func(current []models.SigningKey) (*models.SigningKey, error)) (*models.SigningKey, error)
+	// DeleteExpired removes keys that can no longer verify any token.
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type GormSigningKeyRepository struct {
+	db *gorm.DB
+}
+
+func (r *GormSigningKeyRepository) List(ctx context.Context, now time.Time) ([]models.SigningKey, error) {
+	return listSigningKeys(r.db.WithContext(ctx), now)
+}
+
+func (r *GormSigningKeyRepository) Rotate(ctx context.Context, now time.Time, plan func([]models.SigningKey) (*models.SigningKey, error)) (*models.SigningKey, error) {
+	var created *models.SigningKey
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", signingKeyLock).Error; err != nil {
+			return err
+		}
+		current, err := listSigningKeys(tx, now)
+		if err != nil {
+			return err
+		}
+		key, err := plan(current)
+		if err != nil || key == nil {
+			return err
+		}
+		if err := tx.Create(key).Error; err != nil {
+			return err
+		}
+		created = key
+		return nil
+	})
+	return created, err
+}
+
+func (r *GormSigningKeyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Unscoped().Where("expires_at <= ?", now).Delete(&models.SigningKey{})
+	return res.RowsAffected, res.Error
+}
+
+func listSigningKeys(db *gorm.DB, now time.Time) ([]models.SigningKey, error) {
+	var keys []models.SigningKey
+	err := db.Where("expires_at > ?", now).Order("activates_at DESC").Find(&keys).Error
+	return keys, err
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+
+	"crowdreview/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TaxonomyRepository stores the industry taxonomy and region hierarchy.
+type TaxonomyRepository interface {
+	ListIndustries(ctx context.Context) ([]models.Industry, error)
+	GetIndustry(ctx context.Context, id uuid.UUID) (*models.Industry, error)
+	GetIndustryBySlug(ctx context.Context, slug string) (*models.Industry, error)
+	CreateIndustry(ctx context.Context, industry *models.Industry) error
+	UpdateIndustry(ctx context.Context, industry *models.Industry) error
+	DeleteIndustry(ctx context.Context, id uuid.UUID) error
+
+	ListRegions(ctx context.Context) ([]models.Region, error)
+	GetRegion(ctx context.Context, id uuid.UUID) (*models.Region, error)
+	FindRegion(ctx context.Context, parentID *uuid.UUID, slug string) (*models.Region, error)
+	CreateRegion(ctx context.Context, region *models.Region) error
+	UpdateRegion(ctx context.Context, region *models.Region) error
+	DeleteRegion(ctx context.Context, id uuid.UUID) error
+
+	// CountCompanies returns the number of companies linked directly to each
+	// industry and region.
+	CountCompanies(ctx context.Context) (byIndustry, byRegion map[uuid.UUID]int64, err error)
+	// RenameIndustry refreshes the denormalized industry name of linked companies.
+	RenameIndustry(ctx context.Context, id uuid.UUID, name string) error
+	// SetLocation refreshes the denormalized location text of companies in region.
+	SetLocation(ctx context.Context, regionID uuid.UUID, location string) error
+}
+
+type GormTaxonomyRepository struct {
+	db *gorm.DB
+}
+
+func (r *GormTaxonomyRepository) ListIndustries(ctx context.Context) ([]models.Industry, error) {
+	var industries []models.Industry
+	if err := r.db.WithContext(ctx).Order("name").Find(&industries).Error; err != nil {
+		return nil, err
+	}
+	return industries, nil
+}
+
+func (r *GormTaxonomyRepository) GetIndustry(ctx context.Context, id uuid.UUID) (*models.Industry, error) {
+	var industry models.Industry
+	if err := r.db.WithContext(ctx).First(&industry, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &industry, nil
+}
+
+func (r *GormTaxonomyRepository) GetIndustryBySlug(ctx context.Context, slug string) (*models.Industry, error) {
+	var industry models.Industry
+	if err := r.db.WithContext(ctx).First(&industry, "slug = ?", slug).Error; err != nil {
+		return nil, err
+	}
+	return &industry, nil
+}
+
+func (r *GormTaxonomyRepository) CreateIndustry(ctx context.Context, industry *models.Industry) error {
+	return r.db.WithContext(ctx).Create(industry).Error
+}
+
+func (r *GormTaxonomyRepository) UpdateIndustry(ctx context.Context, industry *models.Industry) error {
+	return r.db.WithContext(ctx).Save(industry).Error
+}
+
+func (r *GormTaxonomyRepository) DeleteIndustry(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.Industry{}, "id = ?", id).Error
+}
+
+func (r *GormTaxonomyRepository) ListRegions(ctx context.Context) ([]models.Region, error) {
+	var regions []models.Region
+	if err := r.db.WithContext(ctx).Order("name").Find(&regions).Error; err != nil {
+		return nil, err
+	}
+	return regions, nil
+}
+
+func (r *GormTaxonomyRepository) GetRegion(ctx context.Context, id uuid.UUID) (*models.Region, error) {
+	var region models.Region
+	if err := r.db.WithContext(ctx).First(&region, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &region, nil
+}
+
+func (r *GormTaxonomyRepository) FindRegion(ctx context.Context, parentID *uuid.UUID, slug string) (*models.Region, error) {
+	var region models.Region
+	query := r.db.WithContext(ctx).Where("slug = ?", slug)
+	if parentID == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+	if err := query.First(&region).Error; err != nil {
+		return nil, err
+	}
+	return &region, nil
+}
+
+func (r *GormTaxonomyRepository) CreateRegion(ctx context.Context, region *models.Region) error {
+	return r.db.WithContext(ctx).Create(region).Error
+}
+
+func (r *GormTaxonomyRepository) UpdateRegion(ctx context.Context, region *models.Region) error {
+	return r.db.WithContext(ctx).Save(region).Error
+}
+
+func (r *GormTaxonomyRepository) DeleteRegion(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.Region{}, "id = ?", id).Error
+}
+
+func (r *GormTaxonomyRepository) CountCompanies(ctx context.Context) (map[uuid.UUID]int64, map[uuid.UUID]int64, error) {
+	byIndustry, err := r.countCompaniesBy(ctx, "industry_id")
+	if err != nil {
+		return nil, nil, err
+	}
+	byRegion, err := r.countCompaniesBy(ctx, "region_id")
+	if err != nil {
+		return nil, nil, err
+	}
+	return byIndustry, byRegion, nil
+}
+
+func (r *GormTaxonomyRepository) countCompaniesBy(ctx context.Context, column string) (map[uuid.UUID]int64, error) {
+	var rows []struct {
+		ID    uuid.UUID
+		Count int64
+	}
+	if err := r.db.WithContext(ctx).Model(&models.Company{}).
+		Select(column + " AS id, COUNT(*) AS count").
+		Where(column + " IS NOT NULL").
+		Group(column).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[uuid.UUID]int64, len(rows))
+	for _, row := range rows {
+		counts[row.ID] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *GormTaxonomyRepository) RenameIndustry(ctx context.Context, id uuid.UUID, name string) error {
+	return r.db.WithContext(ctx).Model(&models.Company{}).Where("industry_id = ?", id).Update("industry", name).Error
+}
+
+func (r *GormTaxonomyRepository) SetLocation(ctx context.Context, regionID uuid.UUID, location string) error {
+	return r.db.WithContext(ctx).Model(&models.Company{}).Where("region_id = ?", regionID).Update("location", location).Error
+}
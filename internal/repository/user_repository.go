@@ -2,10 +2,12 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"crowdreview/internal/models"
 
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -14,6 +16,12 @@ type UserRepository interface {
 	Create(ctx context.Context, user *models.User) error
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+	// ListByIDs returns the existing users among ids, in no particular order.
+	ListByIDs(ctx context.Context, ids []uuid.UUID) ([]models.User, error)
+	MarkEmailVerified(ctx context.Context, id uuid.UUID) error
+	UpdatePassword(ctx context.Context, id uuid.UUID, hash string) error
+	UpdateProfile(ctx context.Context, id uuid.UUID, meta datatypes.JSONMap) error
 }
 
 type GormUserRepository struct {
@@ -39,3 +47,36 @@ func (r *GormUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models
 	}
 	return &user, nil
 }
+
+func (r *GormUserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *GormUserRepository) ListByIDs(ctx context.Context, ids []uuid.UUID) ([]models.User, error) {
+	var users []models.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *GormUserRepository) MarkEmailVerified(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND email_verified_at IS NULL", id).
+		Update("email_verified_at", time.Now()).Error
+}
+
+func (r *GormUserRepository) UpdatePassword(ctx context.Context, id uuid.UUID, hash string) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Update("password_hash", hash).Error
+}
+
+func (r *GormUserRepository) UpdateProfile(ctx context.Context, id uuid.UUID, meta datatypes.JSONMap) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Update("profile_meta", meta).Error
+}
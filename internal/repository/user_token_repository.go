@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"crowdreview/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserTokenRepository stores email verification and password reset tokens.
+type UserTokenRepository interface {
+	// Create stores a token, invalidating earlier unused tokens of the same
+	// user and purpose so only the latest email works.
+	Create(ctx context.Context, token *models.UserToken) error
+	// Consume marks an unused, unexpired token as used and returns it, or
+	// gorm.ErrRecordNotFound.
+	Consume(ctx context.Context, purpose, hash string) (*models.UserToken, error)
+}
+
+type GormUserTokenRepository struct {
+	db *gorm.DB
+}
+
+func (r *GormUserTokenRepository) Create(ctx context.Context, token *models.UserToken) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := invalidateUserTokens(tx, token.UserID, token.Purpose); err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+func (r *GormUserTokenRepository) Consume(ctx context.Context, purpose, hash string) (*models.UserToken, error) {
+	var token models.UserToken
+	now := time.Now()
+	res := r.db.WithContext(ctx).Model(&token).
+		Clauses(clause.Returning{}).
+		Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", hash, purpose, now).
+		Update("used_at", now)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &token, nil
+}
+
+func invalidateUserTokens(tx *gorm.DB, userID uuid.UUID, purpose string) error {
+	return tx.Model(&models.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error
+}
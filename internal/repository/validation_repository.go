@@ -7,12 +7,17 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ValidationRepository persists validation results.
 type ValidationRepository interface {
+	// SaveResult stores the result, replacing one an interrupted run left
+	// for a review that is still pending.
 	SaveResult(ctx context.Context, result *models.ReviewValidationResult) error
-	MarkReview(ctx context.Context, reviewID uuid.UUID, resultID uuid.UUID, status string, suspicious bool) error
+	// MarkReview applies a validation verdict unless the review has left
+	// the pending state meanwhile, reporting whether it did.
+	MarkReview(ctx context.Context, reviewID uuid.UUID, resultID uuid.UUID, status string, suspicious bool) (bool, error)
 }
 
 type GormValidationRepository struct {
@@ -20,16 +25,33 @@ type GormValidationRepository struct {
 }
 
 func (r *GormValidationRepository) SaveResult(ctx context.Context, result *models.ReviewValidationResult) error {
-	return r.db.WithContext(ctx).Create(result).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		stale := tx.Model(&models.Review{}).Select("1").
+			Where("validation_result_id = review_validation_results.id")
+		if err := tx.Unscoped().
+			Where("review_id = ? AND NOT EXISTS (?)", result.ReviewID, stale).
+			Delete(&models.ReviewValidationResult{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(result).Error
+	})
 }
 
-func (r *GormValidationRepository) MarkReview(ctx context.Context, reviewID uuid.UUID, resultID uuid.UUID, status string, suspicious bool) error {
-	return r.db.WithContext(ctx).
-		Model(&models.Review{}).
-		Where("id = ?", reviewID).
-		Updates(map[string]interface{}{
+func (r *GormValidationRepository) MarkReview(ctx context.Context, reviewID uuid.UUID, resultID uuid.UUID, status string, suspicious bool) (bool, error) {
+	marked := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var review models.Review
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "status").First(&review, "id = ?", reviewID).Error; err != nil {
+			return err
+		}
+		if review.Status != models.ReviewStatusPending {
+			return nil
+		}
+		marked = true
+		return transitionReview(tx, reviewID, status, map[string]interface{}{
 			"validation_result_id": resultID,
-			"status":               status,
 			"suspicious":           suspicious,
-		}).Error
+		})
+	})
+	return marked, err
 }
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"crowdreview/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WebhookRepository stores webhook subscriptions and their deliveries.
+type WebhookRepository interface {
+	// ListSubscriptions returns every subscription, or (ownerID set) those
+	// of the companies the user owns.
+	ListSubscriptions(ctx context.Context, ownerID *uuid.UUID) ([]models.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, id uuid.UUID) (*models.WebhookSubscription, error)
+	CreateSubscription(ctx context.Context, sub *models.WebhookSubscription) error
+	UpdateSubscription(ctx context.Context, sub *models.WebhookSubscription) error
+	DeleteSubscription(ctx context.Context, id uuid.UUID) error
+	// Enqueue queues the event for every active subscription to it, global
+	// or of the company, returning how many deliveries were queued.
+	// Subscriptions that already have the event are skipped.
+	Enqueue(ctx context.Context, companyID uuid.UUID, event string, eventID uuid.UUID, payload []byte) (int64, error)
+	// Claim locks up to limit due deliveries, with their subscriptions, by
+	// moving their next attempt lease into the future, so concurrent
+	// dispatchers do not send them twice.
+	Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error)
+	// SaveAttempt records the outcome of an attempt.
+	SaveAttempt(ctx context.Context, delivery *models.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit, offset int) ([]models.WebhookDelivery, int64, error)
+	GetDelivery(ctx context.Context, subscriptionID, id uuid.UUID) (*models.WebhookDelivery, error)
+	CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+}
+
+type GormWebhookRepository struct {
+	db *gorm.DB
+}
+
+func (r *GormWebhookRepository) ListSubscriptions(ctx context.Context, ownerID *uuid.UUID) ([]models.WebhookSubscription, error) {
+	query := r.db.WithContext(ctx).Preload("Company")
+	if ownerID != nil {
+		query = query.Where("company_id IN (?)",
+			r.db.Model(&models.Company{}).Select("id").Where("owner_id = ?", *ownerID))
+	}
+	var subs []models.WebhookSubscription
+	if err := query.Order("created_at").Find(&subs).Error; err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+func (r *GormWebhookRepository) GetSubscription(ctx context.Context, id uuid.UUID) (*models.WebhookSubscription, error) {
+	var sub models.WebhookSubscription
+	if err := r.db.WithContext(ctx).Preload("Company").First(&sub, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (r *GormWebhookRepository) CreateSubscription(ctx context.Context, sub *models.WebhookSubscription) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(sub).Error
+}
+
+func (r *GormWebhookRepository) UpdateSubscription(ctx context.Context, sub *models.WebhookSubscription) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(sub).Error
+}
+
+func (r *GormWebhookRepository) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	res := r.db.WithContext(ctx).Delete(&models.WebhookSubscription{}, "id = ?", id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *GormWebhookRepository) Enqueue(ctx context.Context, companyID uuid.UUID, event string, eventID uuid.UUID, payload []byte) (int64, error) {
+	res := r.db.WithContext(ctx).Exec(`
+		INSERT INTO webhook_deliveries (id, created_at, updated_at, subscription_id, event_id, event, payload, status, attempts, next_attempt_at)
+		SELECT gen_random_uuid(), now(), now(), s.id, ?, ?, ?, ?, 0, now()
+		FROM webhook_subscriptions s
+		WHERE s.deleted_at IS NULL AND s.active
+		  AND (s.company_id IS NULL OR s.company_id = ?)
+		  AND s.events @> ?
+		ON CONFLICT (subscription_id, event_id) WHERE redelivery_of IS NULL DO NOTHING`,
+		eventID, event, datatypes.JSON(payload), models.WebhookPending, companyID, datatypes.JSONSlice[string]{event})
+	return res.RowsAffected, res.Error
+}
+
+func (r *GormWebhookRepository) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []uuid.UUID
+		if err := tx.Model(&models.WebhookDelivery{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.WebhookPending, now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := tx.Model(&models.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error; err != nil {
+			return err
+		}
+		return tx.Preload("Subscription", unscoped).Where("id IN ?", ids).Find(&deliveries).Error
+	})
+	return deliveries, err
+}
+
+func (r *GormWebhookRepository) SaveAttempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return r.db.WithContext(ctx).Model(&models.WebhookDelivery{}).
+		Where("id = ?", delivery.ID).
+		Updates(map[string]interface{}{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"status_code":     delivery.StatusCode,
+			"last_error":      delivery.LastError,
+			"next_attempt_at": delivery.NextAttemptAt,
+			"delivered_at":    delivery.DeliveredAt,
+		}).Error
+}
+
+func (r *GormWebhookRepository) ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit, offset int) ([]models.WebhookDelivery, int64, error) {
+	db := r.db.WithContext(ctx).Model(&models.WebhookDelivery{}).Where("subscription_id = ?", subscriptionID)
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var deliveries []models.WebhookDelivery
+	if err := db.Order("created_at DESC").Limit(limit).Offset(offset).Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+func (r *GormWebhookRepository) GetDelivery(ctx context.Context, subscriptionID, id uuid.UUID) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := r.db.WithContext(ctx).First(&delivery, "id = ? AND subscription_id = ?", id, subscriptionID).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *GormWebhookRepository) CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(delivery).Error
+}
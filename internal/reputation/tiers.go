@@ -0,0 +1,92 @@
+// Package reputation derives reviewer tiers from their gamification score.
+package reputation
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultTiers is used when REPUTATION_TIERS is unset or invalid.
+const DefaultTiers = "novato:0,colaborador:100:3,confiavel:500:6,referencia:2000:10"
+
+// Tier is a reputation level reached at MinScore points. TrustBonus is
+// added to the fraud engine's score for reviews written at this tier.
+type Tier struct {
+	Name       string
+	MinScore   int
+	TrustBonus float64
+}
+
+// Tiers are ordered by ascending MinScore; the first starts at zero.
+type Tiers []Tier
+
+// ParseTiers reads comma-separated name:min_score[:trust_bonus] entries,
+// e.g. "novato:0,colaborador:100:3".
+func ParseTiers(spec string) (Tiers, error) {
+	var tiers Tiers
+	seen := map[string]bool{}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("tier %q: want name:min_score[:trust_bonus]", entry)
+		}
+		tier := Tier{Name: strings.TrimSpace(parts[0])}
+		if tier.Name == "" || seen[tier.Name] {
+			return nil, fmt.Errorf("tier %q: missing or repeated name", entry)
+		}
+		seen[tier.Name] = true
+		min, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || min < 0 {
+			return nil, fmt.Errorf("tier %q: invalid min_score", entry)
+		}
+		tier.MinScore = min
+		if len(parts) == 3 {
+			bonus, err := strconv.ParseFloat(strings.TrimSpace(parts[2]), 64)
+			if err != nil || bonus < 0 {
+				return nil, fmt.Errorf("tier %q: invalid trust_bonus", entry)
+			}
+			tier.TrustBonus = bonus
+		}
+		tiers = append(tiers, tier)
+	}
+	if len(tiers) == 0 {
+		return nil, fmt.Errorf("no tiers defined")
+	}
+	sort.SliceStable(tiers, func(i, j int) bool { return tiers[i].MinScore < tiers[j].MinScore })
+	if tiers[0].MinScore != 0 {
+		return nil, fmt.Errorf("the lowest tier must start at 0")
+	}
+	for i := 1; i < len(tiers); i++ {
+		if tiers[i].MinScore == tiers[i-1].MinScore {
+			return nil, fmt.Errorf("tiers %q and %q share min_score %d", tiers[i-1].Name, tiers[i].Name, tiers[i].MinScore)
+		}
+	}
+	return tiers, nil
+}
+
+// MustParseTiers is ParseTiers for known-good specs.
+func MustParseTiers(spec string) Tiers {
+	tiers, err := ParseTiers(spec)
+	if err != nil {
+		panic(err)
+	}
+	return tiers
+}
+
+// For returns the highest tier whose threshold score reaches. Negative
+// scores fall into the lowest tier; empty Tiers yield the zero Tier.
+func (t Tiers) For(score int) Tier {
+	var tier Tier
+	for i, candidate := range t {
+		if i == 0 || score >= candidate.MinScore {
+			tier = candidate
+		}
+	}
+	return tier
+}
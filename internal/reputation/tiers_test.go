@@ -0,0 +1,31 @@
+package reputation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTiers(t *testing.T) {
+	tiers, err := ParseTiers("ouro:500:8, bronze:0 ,prata:100:2.5")
+	require.NoError(t, err)
+	require.Equal(t, Tiers{
+		{Name: "bronze", MinScore: 0},
+		{Name: "prata", MinScore: 100, TrustBonus: 2.5},
+		{Name: "ouro", MinScore: 500, TrustBonus: 8},
+	}, tiers)
+
+	for _, bad := range []string{"", "bronze", "prata:100", "bronze:0,bronze:10", "bronze:0,prata:0", "bronze:0:-1", "bronze:x"} {
+		_, err := ParseTiers(bad)
+		require.Error(t, err, bad)
+	}
+}
+
+func TestTiersFor(t *testing.T) {
+	tiers := MustParseTiers(DefaultTiers)
+	require.Equal(t, "novato", tiers.For(-5).Name)
+	require.Equal(t, "novato", tiers.For(99).Name)
+	require.Equal(t, "colaborador", tiers.For(100).Name)
+	require.Equal(t, "referencia", tiers.For(1_000_000).Name)
+	require.Equal(t, Tier{}, Tiers(nil).For(10))
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"crowdreview/internal/achievements"
+	"crowdreview/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	maxAchievementName        = 80
+	maxAchievementDescription = 500
+	maxAchievementIcon        = 200
+)
+
+// Achievement catalog errors.
+var (
+	ErrAchievementNotFound = errors.New("achievement not found")
+	ErrInvalidAchievement  = errors.New("invalid achievement")
+)
+
+// AchievementInput creates or partially updates an achievement; nil fields
+// are left unchanged. Empty criteria ({}) make the achievement manual-only.
+type AchievementInput struct {
+	Name        *string
+	Description *string
+	Icon        *string
+	Points      *int
+	Criteria    *achievements.Criteria
+}
+
+// SeedReport summarizes a catalog seeding run.
+type SeedReport struct {
+	Created []string
+	Updated []string
+	Skipped []string // already present and not overwritten
+	Retired []string // deleted by an admin, so not re-created
+}
+
+func (s *DefaultAchievementService) Catalog(ctx context.Context) ([]models.Achievement, error) {
+	return s.Achievements.List(ctx)
+}
+
+func (s *DefaultAchievementService) Create(ctx context.Context, input AchievementInput) (*models.Achievement, error) {
+	if input.Name == nil || input.Points == nil {
+		return nil, fmt.Errorf("%w: name and points are required", ErrInvalidAchievement)
+	}
+	achievement := &models.Achievement{Meta: datatypes.JSONMap{}}
+	if err := s.apply(ctx, achievement, input); err != nil {
+		return nil, err
+	}
+	if err := s.Achievements.Create(ctx, achievement); err != nil {
+		return nil, err
+	}
+	return achievement, nil
+}
+
+func (s *DefaultAchievementService) Update(ctx context.Context, id uuid.UUID, input AchievementInput) (*models.Achievement, error) {
+	achievement, err := s.Achievements.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAchievementNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, achievement, input); err != nil {
+		return nil, err
+	}
+	if err := s.Achievements.Update(ctx, achievement); err != nil {
+		return nil, err
+	}
+	return achievement, nil
+}
+
+func (s *DefaultAchievementService) Delete(ctx context.Context, id uuid.UUID) error {
+	err := s.Achievements.Delete(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrAchievementNotFound
+	}
+	return err
+}
+
+func (s *DefaultAchievementService) Seed(ctx context.Context, overwrite bool) (SeedReport, error) {
+	var report SeedReport
+	catalog, err := achievements.DefaultCatalog()
+	if err != nil {
+		return report, err
+	}
+	existing, err := s.Achievements.List(ctx)
+	if err != nil {
+		return report, err
+	}
+	byName := make(map[string]models.Achievement, len(existing))
+	for _, a := range existing {
+		byName[strings.ToLower(a.Name)] = a
+	}
+	retiredNames, err := s.Achievements.RetiredNames(ctx)
+	if err != nil {
+		return report, err
+	}
+	retired := make(map[string]bool, len(retiredNames))
+	for _, name := range retiredNames {
+		retired[strings.ToLower(name)] = true
+	}
+	for _, entry := range catalog {
+		entry := entry
+		criteria := entry.Criteria
+		if criteria == nil {
+			criteria = &achievements.Criteria{}
+		}
+		input := AchievementInput{
+			Name:        &entry.Name,
+			Description: &entry.Description,
+			Icon:        &entry.Icon,
+			Points:      &entry.Points,
+			Criteria:    criteria,
+		}
+		current, ok := byName[strings.ToLower(entry.Name)]
+		switch {
+		case !ok && retired[strings.ToLower(entry.Name)]:
+			report.Retired = append(report.Retired, entry.Name)
+		case !ok:
+			if _, err := s.Create(ctx, input); err != nil {
+				return report, fmt.Errorf("seed %q: %w", entry.Name, err)
+			}
+			report.Created = append(report.Created, entry.Name)
+		case overwrite:
+			if _, err := s.Update(ctx, current.ID, input); err != nil {
+				return report, fmt.Errorf("seed %q: %w", entry.Name, err)
+			}
+			report.Updated = append(report.Updated, entry.Name)
+		default:
+			report.Skipped = append(report.Skipped, entry.Name)
+		}
+	}
+	return report, nil
+}
+
+// apply validates input and copies it onto achievement.
+func (s *DefaultAchievementService) apply(ctx context.Context, achievement *models.Achievement, input AchievementInput) error {
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" || utf8.RuneCountInString(name) > maxAchievementName {
+			return fmt.Errorf("%w: name must have 1-%d characters", ErrInvalidAchievement, maxAchievementName)
+		}
+		if !strings.EqualFold(name, achievement.Name) {
+			existing, err := s.Achievements.List(ctx)
+			if err != nil {
+				return err
+			}
+			for _, a := range existing {
+				if a.ID != achievement.ID && strings.EqualFold(a.Name, name) {
+					return fmt.Errorf("%w: %q already exists", ErrInvalidAchievement, name)
+				}
+			}
+		}
+		achievement.Name = name
+	}
+	if input.Description != nil {
+		achievement.Description = strings.TrimSpace(*input.Description)
+		if utf8.RuneCountInString(achievement.Description) > maxAchievementDescription {
+			return fmt.Errorf("%w: description is longer than %d characters", ErrInvalidAchievement, maxAchievementDescription)
+		}
+	}
+	if input.Icon != nil {
+		achievement.Icon = strings.TrimSpace(*input.Icon)
+		if len(achievement.Icon) > maxAchievementIcon {
+			return fmt.Errorf("%w: icon is longer than %d bytes", ErrInvalidAchievement, maxAchievementIcon)
+		}
+	}
+	if input.Points != nil {
+		if *input.Points < 0 {
+			return fmt.Errorf("%w: points must not be negative", ErrInvalidAchievement)
+		}
+		achievement.Points = *input.Points
+	}
+	if input.Criteria != nil {
+		if achievement.Meta == nil {
+			achievement.Meta = datatypes.JSONMap{}
+		}
+		if *input.Criteria == (achievements.Criteria{}) {
+			achievements.SetCriteria(achievement.Meta, nil)
+		} else {
+			if err := input.Criteria.Validate(); err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidAchievement, err)
+			}
+			achievements.SetCriteria(achievement.Meta, input.Criteria)
+		}
+	}
+	return nil
+}
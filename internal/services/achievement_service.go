@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"crowdreview/internal/achievements"
+	"crowdreview/internal/models"
+	"crowdreview/internal/repository"
+	"crowdreview/pkg/logging"
+
+	"github.com/google/uuid"
+)
+
+// AchievementService manages the achievement catalog and awards
+// achievements in response to domain events.
+type AchievementService interface {
+	// Catalog lists every active achievement.
+	Catalog(ctx context.Context) ([]models.Achievement, error)
+	Create(ctx context.Context, input AchievementInput) (*models.Achievement, error)
+	Update(ctx context.Context, id uuid.UUID, input AchievementInput) (*models.Achievement, error)
+	// Delete retires an achievement; users who earned it keep it.
+	Delete(ctx context.Context, id uuid.UUID) error
+	// Seed adds the embedded default catalog, matching by name. Existing
+	// achievements are only changed when overwrite is set.
+	Seed(ctx context.Context, overwrite bool) (SeedReport, error)
+
+	// Handle grants every achievement whose criteria listen to the event and
+	// are now met, returning the newly granted ones. Grants are idempotent.
+	Handle(ctx context.Context, event achievements.Event) ([]models.Achievement, error)
+	// ReviewStatusChanged emits the events of a moderation decision.
+	// Rejection takes back the achievements and points the review earned.
+	// Both are idempotent, so repeated decisions change nothing.
+	ReviewStatusChanged(ctx context.Context, reviewID uuid.UUID, status string) error
+}
+
+type DefaultAchievementService struct {
+	Achievements  repository.AchievementRepository
+	Points        repository.PointsRepository
+	Users         repository.UserRepository
+	Leaderboard   LeaderboardService  // optional
+	Notifications NotificationService // optional
+	Classifier    classifier
+}
+
+// achievementSubject is whom an event's facts describe and the industry
+// slug points earned from it are attributed to.
+type achievementSubject struct {
+	userID   uuid.UUID
+	reviewID *uuid.UUID
+	industry string
+}
+
+func (s *DefaultAchievementService) Handle(ctx context.Context, event achievements.Event) ([]models.Achievement, error) {
+	all, err := s.Achievements.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	type candidate struct {
+		achievement models.Achievement
+		criteria    achievements.Criteria
+	}
+	var candidates []candidate
+	for _, a := range all {
+		criteria, err := achievements.ParseCriteria(a.Meta)
+		if errors.Is(err, achievements.ErrNoCriteria) {
+			continue
+		}
+		if err != nil {
+			slog.WarnContext(ctx, "achievement has invalid criteria", slog.String("achievement", a.Name), logging.Err(err))
+			continue
+		}
+		if criteria.Event == event.Type {
+			candidates = append(candidates, candidate{a, criteria})
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	facts, subject, err := s.facts(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	var granted []models.Achievement
+	points := 0
+	for _, c := range candidates {
+		if !c.criteria.Met(facts) {
+			continue
+		}
+		ok, err := s.Achievements.Grant(ctx, subject.userID, c.achievement.ID, c.achievement.Points, subject.reviewID)
+		if err != nil {
+			return granted, err
+		}
+		if ok {
+			granted = append(granted, c.achievement)
+			points += c.achievement.Points
+		}
+	}
+	if len(granted) > 0 {
+		s.recordLeaderboard(ctx, subject.userID, points, subject.industry, time.Now())
+		if s.Notifications != nil {
+			s.Notifications.AchievementsEarned(ctx, subject.userID, granted)
+		}
+	}
+	return granted, nil
+}
+
+// recordLeaderboard ranks the user's new score; the database stays the
+// source of truth, so failures are only logged.
+func (s *DefaultAchievementService) recordLeaderboard(ctx context.Context, userID uuid.UUID, points int, industry string, at time.Time) {
+	if s.Leaderboard == nil || s.Users == nil {
+		return
+	}
+	user, err := s.Users.GetByID(ctx, userID)
+	if err == nil {
+		err = s.Leaderboard.Record(ctx, userID, user.GamificationScore, points, industry, at)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "leaderboard update failed", slog.String("user_id", userID.String()), logging.Err(err))
+	}
+}
+
+func (s *DefaultAchievementService) ReviewStatusChanged(ctx context.Context, reviewID uuid.UUID, status string) error {
+	switch status {
+	case models.ReviewStatusApproved:
+	case models.ReviewStatusRejected:
+		return s.clawback(ctx, reviewID)
+	default:
+		return nil
+	}
+	granted, err := s.Handle(ctx, achievements.Event{Type: achievements.EventReviewApproved, ReviewID: reviewID})
+	for _, a := range granted {
+		slog.InfoContext(ctx, "achievement granted", slog.String("achievement", a.Name), slog.String("review_id", reviewID.String()))
+	}
+	return err
+}
+
+// clawback reverses the achievements and points the review earned.
+func (s *DefaultAchievementService) clawback(ctx context.Context, reviewID uuid.UUID) error {
+	if s.Points == nil {
+		return nil
+	}
+	reversals, err := s.Points.Clawback(ctx, reviewID)
+	if err != nil {
+		return err
+	}
+	for _, r := range reversals {
+		slog.InfoContext(ctx, "points taken back for rejected review",
+			slog.Int("points", r.Points), slog.String("user_id", r.UserID.String()), slog.String("review_id", reviewID.String()))
+		industry, err := s.Classifier.industryKey(ctx, &models.Company{IndustryID: r.Industry.IndustryID, Industry: r.Industry.Industry})
+		if err != nil {
+			// The reversal is committed; only the industry board misses it.
+			slog.WarnContext(ctx, "leaderboard update after clawback failed",
+				slog.String("user_id", r.UserID.String()), slog.String("review_id", reviewID.String()), logging.Err(err))
+			industry = ""
+		}
+		s.recordLeaderboard(ctx, r.UserID, -r.Points, industry, r.EarnedAt)
+	}
+	return nil
+}
+
+// facts gathers what the criteria of event need and whom they describe.
+func (s *DefaultAchievementService) facts(ctx context.Context, event achievements.Event) (achievements.Facts, achievementSubject, error) {
+	if event.Type != achievements.EventReviewApproved {
+		return achievements.Facts{HelpfulVotes: event.Count}, achievementSubject{userID: event.UserID}, nil
+	}
+	rf, err := s.Achievements.ReviewFacts(ctx, event.ReviewID)
+	if err != nil {
+		return achievements.Facts{}, achievementSubject{}, err
+	}
+	facts := achievements.Facts{
+		ApprovedReviews: rf.ApprovedReviews,
+		IndustryReviews: make(map[string]int64, len(rf.Industries)),
+		FirstInCompany:  rf.FirstInCompany,
+		FirstInIndustry: rf.FirstInIndustry,
+	}
+	for _, ic := range rf.Industries {
+		key, err := s.Classifier.industryKey(ctx, &models.Company{IndustryID: ic.IndustryID, Industry: ic.Industry})
+		if err != nil {
+			return achievements.Facts{}, achievementSubject{}, err
+		}
+		if key != "" {
+			facts.IndustryReviews[key] += ic.Reviews
+		}
+	}
+	industry, err := s.Classifier.industryKey(ctx, &models.Company{IndustryID: rf.Industry.IndustryID, Industry: rf.Industry.Industry})
+	if err != nil {
+		return achievements.Facts{}, achievementSubject{}, err
+	}
+	reviewID := event.ReviewID
+	return facts, achievementSubject{userID: rf.UserID, reviewID: &reviewID, industry: industry}, nil
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"crowdreview/internal/achievements"
+	"crowdreview/internal/models"
+	"crowdreview/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type mockAchievementRepo struct {
+	achievements []models.Achievement
+	facts        repository.ReviewFacts
+	earned       map[uuid.UUID]bool
+	score        int
+	retired      []string
+}
+
+func (m *mockAchievementRepo) List(ctx context.Context) ([]models.Achievement, error) {
+	return m.achievements, nil
+}
+func (m *mockAchievementRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Achievement, error) {
+	for i := range m.achievements {
+		if m.achievements[i].ID == id {
+			a := m.achievements[i]
+			return &a, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+func (m *mockAchievementRepo) Create(ctx context.Context, a *models.Achievement) error {
+	a.ID = uuid.New()
+	m.achievements = append(m.achievements, *a)
+	return nil
+}
+func (m *mockAchievementRepo) Update(ctx context.Context, a *models.Achievement) error {
+	for i := range m.achievements {
+		if m.achievements[i].ID == a.ID {
+			m.achievements[i] = *a
+		}
+	}
+	return nil
+}
+func (m *mockAchievementRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	for i := range m.achievements {
+		if m.achievements[i].ID == id {
+			m.retired = append(m.retired, m.achievements[i].Name)
+			m.achievements = append(m.achievements[:i], m.achievements[i+1:]...)
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+func (m *mockAchievementRepo) RetiredNames(ctx context.Context) ([]string, error) {
+	return m.retired, nil
+}
+func (m *mockAchievementRepo) Grant(ctx context.Context, userID, achievementID uuid.UUID, points int, reviewID *uuid.UUID) (bool, error) {
+	if m.earned[achievementID] {
+		return false, nil
+	}
+	m.earned[achievementID] = true
+	m.score += points
+	return true, nil
+}
+func (m *mockAchievementRepo) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.UserAchievement, error) {
+	return nil, nil
+}
+func (m *mockAchievementRepo) ReviewFacts(ctx context.Context, reviewID uuid.UUID) (repository.ReviewFacts, error) {
+	return m.facts, nil
+}
+
+func achievement(name string, points int, criteria map[string]interface{}) models.Achievement {
+	a := models.Achievement{Name: name, Points: points, Meta: datatypes.JSONMap{}}
+	a.ID = uuid.New()
+	if criteria != nil {
+		a.Meta[achievements.MetaKey] = criteria
+	}
+	return a
+}
+
+func TestAchievementServiceGrantsOnce(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockAchievementRepo{
+		achievements: []models.Achievement{
+			achievement("Primeira avaliação", 10, map[string]interface{}{"event": "review_approved", "count": 1}),
+			achievement("Veterano", 50, map[string]interface{}{"event": "review_approved", "count": 5}),
+			achievement("Pioneiro do varejo", 20, map[string]interface{}{"event": "review_approved", "industry": "varejo", "first_in": "industry"}),
+			achievement("Manual", 100, nil),
+		},
+		facts: repository.ReviewFacts{
+			UserID:          uuid.New(),
+			ApprovedReviews: 1,
+			Industries:      []repository.IndustryCount{{Industry: "Varejo", Reviews: 1}},
+			FirstInIndustry: true,
+		},
+		earned: map[uuid.UUID]bool{},
+	}
+	service := &DefaultAchievementService{Achievements: repo}
+	event := achievements.Event{Type: achievements.EventReviewApproved, ReviewID: uuid.New()}
+
+	granted, err := service.Handle(ctx, event)
+	require.NoError(t, err)
+	require.Len(t, granted, 2)
+	require.Equal(t, 30, repo.score)
+
+	granted, err = service.Handle(ctx, event)
+	require.NoError(t, err)
+	require.Empty(t, granted)
+	require.Equal(t, 30, repo.score)
+}
+
+func TestAchievementCatalogSeed(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockAchievementRepo{achievements: []models.Achievement{achievement("Veterano", 999, nil)}}
+	service := &DefaultAchievementService{Achievements: repo}
+
+	report, err := service.Seed(ctx, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Veterano"}, report.Skipped)
+	require.NotEmpty(t, report.Created)
+	veterano, err := repo.GetByID(ctx, repo.achievements[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, 999, veterano.Points)
+
+	report, err = service.Seed(ctx, true)
+	require.NoError(t, err)
+	require.Empty(t, report.Created)
+	require.Contains(t, report.Updated, "Veterano")
+	veterano, _ = repo.GetByID(ctx, veterano.ID)
+	require.NotEqual(t, 999, veterano.Points)
+	_, err = achievements.ParseCriteria(veterano.Meta)
+	require.NoError(t, err)
+
+	// Deleted defaults stay deleted, even when overwriting.
+	require.NoError(t, service.Delete(ctx, veterano.ID))
+	report, err = service.Seed(ctx, true)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Veterano"}, report.Retired)
+	require.NotContains(t, report.Created, "Veterano")
+}
+
+func TestAchievementCatalogValidation(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockAchievementRepo{achievements: []models.Achievement{achievement("Veterano", 50, nil)}}
+	service := &DefaultAchievementService{Achievements: repo}
+	name, points := "veterano", 10
+
+	_, err := service.Create(ctx, AchievementInput{Name: &name, Points: &points})
+	require.ErrorIs(t, err, ErrInvalidAchievement, "names are unique regardless of case")
+
+	name, points = "Crítico", -1
+	_, err = service.Create(ctx, AchievementInput{Name: &name, Points: &points})
+	require.ErrorIs(t, err, ErrInvalidAchievement)
+
+	points = 15
+	created, err := service.Create(ctx, AchievementInput{Name: &name, Points: &points, Criteria: &achievements.Criteria{}})
+	require.NoError(t, err)
+	_, err = achievements.ParseCriteria(created.Meta)
+	require.Error(t, err, "empty criteria make the achievement manual-only")
+
+	require.ErrorIs(t, service.Delete(ctx, uuid.New()), ErrAchievementNotFound)
+	require.NoError(t, service.Delete(ctx, created.ID))
+}
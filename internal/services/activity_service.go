@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"crowdreview/internal/activity"
+	"crowdreview/internal/models"
+	"crowdreview/internal/repository"
+	"crowdreview/pkg/logging"
+	"crowdreview/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrActivityForbidden is returned to viewers who may see no live activity.
+var ErrActivityForbidden = errors.New("not allowed to follow moderation activity")
+
+// ActivityService streams live moderation activity to admins: newly
+// flagged reviews and claims to holders of reviews.moderate, incidents to
+// holders of users.manage.
+type ActivityService interface {
+	// Publish sends an event to every stream on every replica, logging
+	// failures instead of returning them; live activity is best effort.
+	Publish(ctx context.Context, event activity.Event)
+	// Subscribe streams the events the viewer may see until stop is called
+	// or ctx is done. Access is re-read periodically: events close once the
+	// access token expires or is revoked, or the user no longer holds either
+	// permission, and the filter follows permission changes.
+	Subscribe(ctx context.Context, viewer Viewer, token *utils.TokenClaims) (events <-chan activity.Event, stop func(), err error)
+	// ReviewStatusChanged announces reviews flagged by the outbox event,
+	// under the event's ID.
+	ReviewStatusChanged(ctx context.Context, eventID, reviewID uuid.UUID, status string) error
+	// Start relays events published on other replicas until ctx is done.
+	Start(ctx context.Context)
+}
+
+// activityRecheck is how often open streams re-read the viewer's access.
+const activityRecheck = 25 * time.Second
+
+type DefaultActivityService struct {
+	Bus      *activity.Bus
+	Reviews  repository.ReviewRepository
+	Users    repository.UserRepository
+	Admins   repository.AdminUserRepository
+	Denylist *utils.TokenDenylist
+	Recheck  time.Duration // zero means activityRecheck
+}
+
+func (s *DefaultActivityService) Publish(ctx context.Context, event activity.Event) {
+	if err := s.Bus.Publish(ctx, event); err != nil {
+		slog.WarnContext(ctx, "activity publish failed", slog.String("event_type", event.Type), logging.Err(err))
+	}
+}
+
+func (s *DefaultActivityService) Subscribe(ctx context.Context, viewer Viewer, token *utils.TokenClaims) (<-chan activity.Event, func(), error) {
+	if !canFollowActivity(viewer) {
+		return nil, nil, ErrActivityForbidden
+	}
+	interval := s.Recheck
+	if interval <= 0 {
+		interval = activityRecheck
+	}
+	source, unsubscribe := s.Bus.Subscribe()
+	events := make(chan activity.Event)
+	done := make(chan struct{})
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			close(done)
+			unsubscribe()
+		})
+	}
+	go func() {
+		defer close(events)
+		defer stop()
+		recheck := time.NewTicker(interval)
+		defer recheck.Stop()
+		var expired <-chan time.Time
+		if token != nil && token.ExpiresAt != nil {
+			timer := time.NewTimer(time.Until(token.ExpiresAt.Time))
+			defer timer.Stop()
+			expired = timer.C
+		}
+		for {
+			select {
+			case event, ok := <-source:
+				if !ok {
+					return
+				}
+				if !viewer.Can(activity.Permission(event.Type)) {
+					continue
+				}
+				select {
+				case events <- event:
+				case <-done:
+					return
+				case <-ctx.Done():
+					return
+				}
+			case <-recheck.C:
+				current, err := s.currentAccess(ctx, viewer, token)
+				if err != nil {
+					if !errors.Is(err, ErrActivityForbidden) {
+						slog.WarnContext(ctx, "activity access check failed", logging.Err(err))
+					}
+					return
+				}
+				viewer = current
+			case <-expired:
+				return
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, stop, nil
+}
+
+// currentAccess re-reads the access of a streaming viewer and returns the
+// viewer with the user's current permissions. It fails with
+// ErrActivityForbidden once the token is revoked or the user is gone or
+// holds neither activity permission.
+func (s *DefaultActivityService) currentAccess(ctx context.Context, viewer Viewer, token *utils.TokenClaims) (Viewer, error) {
+	if token != nil {
+		revoked, err := s.Denylist.IsRevoked(ctx, token)
+		if err != nil {
+			return viewer, err
+		}
+		if revoked {
+			return viewer, ErrActivityForbidden
+		}
+	}
+	user, err := s.Users.GetByID(ctx, viewer.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return viewer, ErrActivityForbidden
+	}
+	if err != nil {
+		return viewer, err
+	}
+	perms, err := userPermissions(ctx, s.Admins, user)
+	if err != nil {
+		return viewer, err
+	}
+	viewer.Role = user.Role
+	viewer.Permissions = perms
+	if !canFollowActivity(viewer) {
+		return viewer, ErrActivityForbidden
+	}
+	return viewer, nil
+}
+
+func canFollowActivity(viewer Viewer) bool {
+	return viewer.Can(models.PermReviewsModerate) || viewer.Can(models.PermUsersManage)
+}
+
+func (s *DefaultActivityService) ReviewStatusChanged(ctx context.Context, eventID, reviewID uuid.UUID, status string) error {
+	if status != models.ReviewStatusFlagged {
+		return nil
+	}
+	review, err := s.Reviews.GetByID(ctx, reviewID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	s.Publish(ctx, activity.Event{
+		ID:   eventID,
+		Type: activity.EventReviewFlagged,
+		Data: map[string]interface{}{
+			"review_id":    review.ID,
+			"company_id":   review.CompanyID,
+			"company_name": review.Company.Name,
+			"rating":       review.Rating,
+			"title":        review.Title,
+			"suspicious":   review.Suspicious,
+		},
+	})
+	return nil
+}
+
+func (s *DefaultActivityService) Start(ctx context.Context) {
+	s.Bus.Start(ctx)
+}
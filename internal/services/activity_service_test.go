@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"crowdreview/config"
+	"crowdreview/internal/activity"
+	"crowdreview/internal/models"
+	"crowdreview/pkg/utils"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+// lockedAdminRepo lets a test change grants while streams re-read them.
+type lockedAdminRepo struct {
+	mu sync.Mutex
+	mockAdminRepo
+}
+
+func (m *lockedAdminRepo) GetByUser(ctx context.Context, userID uuid.UUID) (*models.AdminUser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mockAdminRepo.GetByUser(ctx, userID)
+}
+func (m *lockedAdminRepo) SetAccess(ctx context.Context, userID uuid.UUID, role string, grants datatypes.JSONMap) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mockAdminRepo.SetAccess(ctx, userID, role, grants)
+}
+
+// mockClaimRepo implements review claims on top of mockReviewRepo.
+type mockClaimRepo struct {
+	mockReviewRepo
+}
+
+func (m *mockClaimRepo) Claim(ctx context.Context, id, moderatorID uuid.UUID, staleBefore time.Time) (bool, error) {
+	r, ok := m.reviews[id]
+	if !ok || (r.Status != models.ReviewStatusPending && r.Status != models.ReviewStatusFlagged) {
+		return false, nil
+	}
+	if r.ClaimedByID != nil && *r.ClaimedByID != moderatorID && !r.ClaimedAt.Before(staleBefore) {
+		return false, nil
+	}
+	now := time.Now()
+	r.ClaimedByID, r.ClaimedAt = &moderatorID, &now
+	return true, nil
+}
+func (m *mockClaimRepo) Release(ctx context.Context, id, moderatorID uuid.UUID) (bool, error) {
+	r, ok := m.reviews[id]
+	if !ok || r.ClaimedByID == nil || *r.ClaimedByID != moderatorID {
+		return false, nil
+	}
+	r.ClaimedByID, r.ClaimedAt = nil, nil
+	return true, nil
+}
+
+func receive(t *testing.T, events <-chan activity.Event) activity.Event {
+	t.Helper()
+	select {
+	case e := <-events:
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("no activity event")
+		return activity.Event{}
+	}
+}
+
+func TestActivityStreamFollowsPermissions(t *testing.T) {
+	ctx := context.Background()
+	service := &DefaultActivityService{Bus: activity.NewBus(nil)}
+
+	_, _, err := service.Subscribe(ctx, Viewer{UserID: uuid.New(), Role: models.RoleUser}, nil)
+	require.ErrorIs(t, err, ErrActivityForbidden)
+
+	moderator, stopModerator, err := service.Subscribe(ctx, Viewer{UserID: uuid.New(), Role: models.RoleModerator}, nil)
+	require.NoError(t, err)
+	defer stopModerator()
+	security, stopSecurity, err := service.Subscribe(ctx, Viewer{UserID: uuid.New(), Permissions: []string{models.PermUsersManage}}, nil)
+	require.NoError(t, err)
+	defer stopSecurity()
+
+	service.Publish(ctx, activity.Event{Type: activity.EventIncident})
+	service.Publish(ctx, activity.Event{Type: activity.EventReviewClaimed})
+	require.Equal(t, activity.EventReviewClaimed, receive(t, moderator).Type)
+	require.Equal(t, activity.EventIncident, receive(t, security).Type)
+}
+
+func requireStreamClosed(t *testing.T, events <-chan activity.Event) {
+	t.Helper()
+	select {
+	case _, ok := <-events:
+		require.False(t, ok, "stream should have ended")
+	case <-time.After(time.Second):
+		t.Fatal("stream still open")
+	}
+}
+
+func TestActivityStreamEndsWhenAccessIsRevoked(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	denylist := utils.NewTokenDenylist(redis.NewClient(&redis.Options{Addr: server.Addr()}))
+	ana := &models.User{Email: "ana@example.com", Role: models.RoleModerator}
+	ana.ID = uuid.New()
+	bia := &models.User{Email: "bia@example.com", Role: models.RoleModerator}
+	bia.ID = uuid.New()
+	admins := &lockedAdminRepo{}
+	service := &DefaultActivityService{
+		Bus:      activity.NewBus(nil),
+		Users:    &mockUserRepo{users: map[string]*models.User{ana.Email: ana, bia.Email: bia}},
+		Admins:   admins,
+		Denylist: denylist,
+		Recheck:  10 * time.Millisecond,
+	}
+	token := func(user *models.User, ttl time.Duration) *utils.TokenClaims {
+		return &utils.TokenClaims{Role: user.Role, RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   user.ID.String(),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+		}}
+	}
+	moderator := func(user *models.User) Viewer {
+		return Viewer{UserID: user.ID, Role: user.Role}
+	}
+
+	loggedOut, stopLoggedOut, err := service.Subscribe(ctx, moderator(ana), token(ana, time.Hour))
+	require.NoError(t, err)
+	defer stopLoggedOut()
+	demoted, stopDemoted, err := service.Subscribe(ctx, moderator(bia), token(bia, time.Hour))
+	require.NoError(t, err)
+	defer stopDemoted()
+	expiring, stopExpiring, err := service.Subscribe(ctx, moderator(bia), token(bia, 200*time.Millisecond))
+	require.NoError(t, err)
+	defer stopExpiring()
+
+	service.Publish(ctx, activity.Event{Type: activity.EventReviewClaimed})
+	receive(t, loggedOut)
+	receive(t, demoted)
+	receive(t, expiring)
+
+	requireStreamClosed(t, expiring)
+	require.NoError(t, denylist.RevokeUser(ctx, ana.ID, time.Hour))
+	requireStreamClosed(t, loggedOut)
+	require.NoError(t, admins.SetAccess(ctx, bia.ID, models.RoleModerator, datatypes.JSONMap{models.PermReviewsModerate: false}))
+	requireStreamClosed(t, demoted)
+}
+
+func TestReviewClaims(t *testing.T) {
+	ctx := context.Background()
+	review := &models.Review{Status: models.ReviewStatusFlagged, Company: models.Company{Name: "Acme"}}
+	review.ID = uuid.New()
+	approved := &models.Review{Status: models.ReviewStatusApproved}
+	approved.ID = uuid.New()
+	feed := &DefaultActivityService{Bus: activity.NewBus(nil)}
+	events, stop, err := feed.Subscribe(ctx, Viewer{UserID: uuid.New(), Role: models.RoleModerator}, nil)
+	require.NoError(t, err)
+	defer stop()
+	service := &DefaultAdminService{
+		Reviews:  &mockClaimRepo{mockReviewRepo{reviews: map[uuid.UUID]*models.Review{review.ID: review, approved.ID: approved}}},
+		Activity: feed,
+		Config:   config.Config{ReviewClaimTTL: 15 * time.Minute},
+	}
+	ana, bia := uuid.New(), uuid.New()
+
+	claimed, err := service.ClaimReview(ctx, ana, review.ID)
+	require.NoError(t, err)
+	require.Equal(t, ana, *claimed.ClaimedByID)
+	event := receive(t, events)
+	require.Equal(t, activity.EventReviewClaimed, event.Type)
+	require.Equal(t, ana, event.Data["moderator_id"])
+
+	_, err = service.ClaimReview(ctx, bia, review.ID)
+	require.ErrorIs(t, err, ErrReviewClaimed)
+	require.ErrorIs(t, service.ReleaseReview(ctx, bia, review.ID), ErrReviewClaimed)
+	_, err = service.ClaimReview(ctx, ana, approved.ID)
+	require.ErrorIs(t, err, ErrReviewNotClaimable)
+	_, err = service.ClaimReview(ctx, ana, uuid.New())
+	require.ErrorIs(t, err, ErrReviewNotFound)
+
+	// Stale claims can be taken over.
+	stale := time.Now().Add(-time.Hour)
+	review.ClaimedAt = &stale
+	_, err = service.ClaimReview(ctx, bia, review.ID)
+	require.NoError(t, err)
+	require.Equal(t, activity.EventReviewClaimed, receive(t, events).Type)
+
+	require.NoError(t, service.ReleaseReview(ctx, bia, review.ID))
+	require.Equal(t, activity.EventReviewReleased, receive(t, events).Type)
+	require.Nil(t, review.ClaimedByID)
+	require.NoError(t, service.ReleaseReview(ctx, bia, review.ID), "releasing twice does nothing")
+}
@@ -2,9 +2,14 @@ package services
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"crowdreview/config"
+	"crowdreview/internal/activity"
 	"crowdreview/internal/models"
 	"crowdreview/internal/repository"
+	"crowdreview/pkg/utils"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -18,16 +23,49 @@ type Insights struct {
 	CompaniesTracked int64
 }
 
+// Review claim errors.
+var (
+	ErrReviewNotFound     = errors.New("review not found")
+	ErrReviewClaimed      = errors.New("review is claimed by another moderator")
+	ErrReviewNotClaimable = errors.New("only pending or flagged reviews can be claimed")
+)
+
 // AdminService exposes admin-only operations.
 type AdminService interface {
 	GetInsights(ctx context.Context) (Insights, error)
 	ListSuspicious(ctx context.Context) ([]models.Review, error)
 	Respond(ctx context.Context, reviewID string, status string) error
+	// ClaimReview reserves a pending or flagged review for the moderator
+	// for REVIEW_CLAIM_MINUTES, so others skip it; claiming again renews
+	// the claim. Moderating the review ends it.
+	ClaimReview(ctx context.Context, moderatorID, reviewID uuid.UUID) (*models.Review, error)
+	// ReleaseReview gives up the moderator's claim; releasing an unclaimed
+	// review does nothing.
+	ReleaseReview(ctx context.Context, moderatorID, reviewID uuid.UUID) error
+	// SecurityEvents lists authentication audit events, newest first.
+	SecurityEvents(ctx context.Context, input SecurityEventsInput) ([]models.SecurityEvent, int64, error)
+	UserAccess(ctx context.Context, userID uuid.UUID) (UserAccess, error)
+	SetUserAccess(ctx context.Context, actorID, userID uuid.UUID, input UserAccessInput) (UserAccess, error)
+}
+
+// SecurityEventsInput filters and pages the security event listing.
+type SecurityEventsInput struct {
+	Type      string
+	UserID    *uuid.UUID
+	IPAddress string
+	Limit     int
+	Offset    int
 }
 
 type DefaultAdminService struct {
 	Reviews    repository.ReviewRepository
 	Validation repository.ValidationRepository
+	Security   repository.SecurityEventRepository
+	Users      repository.UserRepository
+	Admins     repository.AdminUserRepository
+	Denylist   *utils.TokenDenylist
+	Activity   ActivityService // optional
+	Config     config.Config
 	DB         *gorm.DB
 }
 
@@ -57,5 +95,85 @@ func (s *DefaultAdminService) Respond(ctx context.Context, reviewID string, stat
 	if err != nil {
 		return err
 	}
+	switch status {
+	case models.ReviewStatusPending, models.ReviewStatusApproved, models.ReviewStatusFlagged, models.ReviewStatusRejected:
+	default:
+		return errors.New("invalid review status")
+	}
+	// Achievements, notifications and webhooks follow from the recorded
+	// review.status_changed event.
 	return s.Reviews.Respond(ctx, id, status)
 }
+
+func (s *DefaultAdminService) ClaimReview(ctx context.Context, moderatorID, reviewID uuid.UUID) (*models.Review, error) {
+	ok, err := s.Reviews.Claim(ctx, reviewID, moderatorID, time.Now().Add(-s.Config.ReviewClaimTTL))
+	if err != nil {
+		return nil, err
+	}
+	review, err := s.Reviews.GetByID(ctx, reviewID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReviewNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if review.Status != models.ReviewStatusPending && review.Status != models.ReviewStatusFlagged {
+			return nil, ErrReviewNotClaimable
+		}
+		return nil, ErrReviewClaimed
+	}
+	s.publishClaim(ctx, activity.EventReviewClaimed, moderatorID, review)
+	return review, nil
+}
+
+func (s *DefaultAdminService) ReleaseReview(ctx context.Context, moderatorID, reviewID uuid.UUID) error {
+	ok, err := s.Reviews.Release(ctx, reviewID, moderatorID)
+	if err != nil {
+		return err
+	}
+	review, err := s.Reviews.GetByID(ctx, reviewID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrReviewNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if review.ClaimedByID != nil && review.ClaimedAt.After(time.Now().Add(-s.Config.ReviewClaimTTL)) {
+			return ErrReviewClaimed
+		}
+		return nil
+	}
+	s.publishClaim(ctx, activity.EventReviewReleased, moderatorID, review)
+	return nil
+}
+
+func (s *DefaultAdminService) publishClaim(ctx context.Context, eventType string, moderatorID uuid.UUID, review *models.Review) {
+	if s.Activity == nil {
+		return
+	}
+	data := map[string]interface{}{
+		"review_id":    review.ID,
+		"company_name": review.Company.Name,
+		"moderator_id": moderatorID,
+	}
+	if review.ClaimedAt != nil {
+		data["expires_at"] = review.ClaimedAt.Add(s.Config.ReviewClaimTTL).UTC()
+	}
+	s.Activity.Publish(ctx, activity.Event{Type: eventType, Data: data})
+}
+
+func (s *DefaultAdminService) SecurityEvents(ctx context.Context, input SecurityEventsInput) ([]models.SecurityEvent, int64, error) {
+	filter := repository.SecurityEventFilter(input)
+	if filter.Limit <= 0 {
+		filter.Limit = defaultDirectoryLimit
+	}
+	if filter.Limit > maxDirectoryLimit {
+		filter.Limit = maxDirectoryLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return s.Security.List(ctx, filter)
+}
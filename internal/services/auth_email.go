@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"crowdreview/internal/mail"
+	"crowdreview/internal/models"
+	"crowdreview/pkg/logging"
+	"crowdreview/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	verificationTokenTTL  = 48 * time.Hour
+	passwordResetTokenTTL = time.Hour
+	minPasswordLength     = 6
+)
+
+// Email token errors.
+var (
+	ErrInvalidEmailToken    = errors.New("invalid or expired token")
+	ErrEmailNotVerified     = errors.New("email address not verified")
+	ErrEmailAlreadyVerified = errors.New("email address already verified")
+)
+
+func (s *DefaultAuthService) SendVerification(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.Users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+	token, err := s.issueUserToken(ctx, user.ID, models.TokenEmailVerification, verificationTokenTTL)
+	if err != nil {
+		return err
+	}
+	return s.Mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Confirme seu e-mail no CrowdReview",
+		Body: fmt.Sprintf("Olá, %s!\n\nConfirme seu e-mail para publicar avaliações:\n%s\n\nO link expira em %d horas.",
+			user.Username, s.link("/verify-email", token), int(verificationTokenTTL.Hours())),
+	})
+}
+
+func (s *DefaultAuthService) VerifyEmail(ctx context.Context, token string) error {
+	record, err := s.UserTokens.Consume(ctx, models.TokenEmailVerification, utils.HashToken(token))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInvalidEmailToken
+	}
+	if err != nil {
+		return err
+	}
+	return s.Users.MarkEmailVerified(ctx, record.UserID)
+}
+
+// RequestPasswordReset mails a reset link. Unknown addresses are ignored
+// without error so the endpoint does not reveal which emails are registered.
+func (s *DefaultAuthService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.Users.GetByEmail(ctx, strings.TrimSpace(email))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	token, err := s.issueUserToken(ctx, user.ID, models.TokenPasswordReset, passwordResetTokenTTL)
+	if err != nil {
+		return err
+	}
+	return s.Mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Redefinição de senha do CrowdReview",
+		Body: fmt.Sprintf("Olá, %s!\n\nPara escolher uma nova senha, acesse:\n%s\n\nO link expira em %d minutos. Se você não pediu a redefinição, ignore este e-mail.",
+			user.Username, s.link("/reset-password", token), int(passwordResetTokenTTL.Minutes())),
+	})
+}
+
+// ResetPassword sets a new password and ends every existing session. Since
+// the reset link reached the user's inbox, it also confirms the address.
+func (s *DefaultAuthService) ResetPassword(ctx context.Context, token, password string) error {
+	if len(password) < minPasswordLength {
+		return fmt.Errorf("password must have at least %d characters", minPasswordLength)
+	}
+	record, err := s.UserTokens.Consume(ctx, models.TokenPasswordReset, utils.HashToken(token))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInvalidEmailToken
+	}
+	if err != nil {
+		return err
+	}
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	if err := s.Users.UpdatePassword(ctx, record.UserID, hash); err != nil {
+		return err
+	}
+	if err := s.Users.MarkEmailVerified(ctx, record.UserID); err != nil {
+		return err
+	}
+	return s.LogoutAll(ctx, record.UserID)
+}
+
+// sendVerificationAfterSignup mails the first verification link. Failures
+// are logged rather than failing registration; users can ask for a resend.
+func (s *DefaultAuthService) sendVerificationAfterSignup(ctx context.Context, userID uuid.UUID) {
+	if err := s.SendVerification(ctx, userID); err != nil {
+		slog.ErrorContext(ctx, "failed to send verification email", slog.String("user_id", userID.String()), logging.Err(err))
+	}
+}
+
+func (s *DefaultAuthService) issueUserToken(ctx context.Context, userID uuid.UUID, purpose string, ttl time.Duration) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	err := s.UserTokens.Create(ctx, &models.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	return token, err
+}
+
+func (s *DefaultAuthService) link(path, token string) string {
+	return strings.TrimRight(s.Config.FrontendURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
@@ -6,7 +6,9 @@ import (
 	"time"
 
 	"crowdreview/config"
+	"crowdreview/internal/mail"
 	"crowdreview/internal/models"
+	"crowdreview/internal/oidc"
 	"crowdreview/internal/repository"
 	"crowdreview/pkg/utils"
 
@@ -14,17 +16,54 @@ import (
 	"gorm.io/gorm"
 )
 
+// Refresh token errors.
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected; all sessions of this login were revoked")
+)
+
 // AuthService exposes auth flows.
 type AuthService interface {
 	Register(ctx context.Context, email, username, password string) (*models.User, string, string, error)
-	Login(ctx context.Context, email, password string) (*models.User, string, string, error)
-	Refresh(ctx context.Context, userID uuid.UUID) (string, string, error)
-	ValidateRefreshToken(token string) (uuid.UUID, error)
+	Login(ctx context.Context, email, password, ip string) (*models.User, string, string, error)
+	// Refresh rotates a refresh token: it is consumed and a new pair in the
+	// same token family is returned. Presenting a consumed token revokes the
+	// whole family.
+	Refresh(ctx context.Context, refreshToken string) (string, string, error)
+	// Logout revokes the access token and, when given, the refresh token's family.
+	Logout(ctx context.Context, access *utils.TokenClaims, refreshToken string) error
+	// LogoutAll revokes every refresh token and access token of the user.
+	LogoutAll(ctx context.Context, userID uuid.UUID) error
+	// SendVerification mails a new email verification link.
+	SendVerification(ctx context.Context, userID uuid.UUID) error
+	VerifyEmail(ctx context.Context, token string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, password string) error
+	// EnrollTOTP starts (or restarts) authenticator app enrollment.
+	EnrollTOTP(ctx context.Context, userID uuid.UUID) (TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, userID uuid.UUID, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+	CompleteLogin(ctx context.Context, challenge, code, ip string) (*models.User, string, string, error)
+	OIDCProviders() []string
+	// StartOIDC returns the provider authorization URL for a social login.
+	StartOIDC(ctx context.Context, provider string) (string, error)
+	CompleteOIDC(ctx context.Context, provider, code, state, ip string) (*models.User, string, string, error)
 }
 
 type DefaultAuthService struct {
-	Users  repository.UserRepository
-	Config config.Config
+	Users      repository.UserRepository
+	Tokens     repository.RefreshTokenRepository
+	Keys       *utils.KeySet
+	Denylist   *utils.TokenDenylist
+	UserTokens repository.UserTokenRepository
+	Mailer     mail.Mailer
+	Guard      *LoginGuard
+	TwoFactor  repository.TwoFactorRepository
+	Identities repository.IdentityRepository
+	Admins     repository.AdminUserRepository
+	OIDC       oidc.Registry
+	Config     config.Config
 }
 
 func (s *DefaultAuthService) Register(ctx context.Context, email, username, password string) (*models.User, string, string, error) {
@@ -41,46 +80,140 @@ func (s *DefaultAuthService) Register(ctx context.Context, email, username, pass
 	if err := s.Users.Create(ctx, user); err != nil {
 		return nil, "", "", err
 	}
-	access, refresh, err := utils.GenerateTokens(user.ID, user.Role, s.Config)
+	s.sendVerificationAfterSignup(ctx, user.ID)
+	access, refresh, err := s.issueTokens(ctx, user, uuid.New(), false)
 	return user, access, refresh, err
 }
 
-func (s *DefaultAuthService) Login(ctx context.Context, email, password string) (*models.User, string, string, error) {
+// Login checks credentials from the given client IP. Repeated failures
+// are throttled by the login guard, which returns *LoginBlockedError.
+// Accounts with two-factor authentication get a *TwoFactorRequiredError
+// carrying the challenge for CompleteLogin instead of tokens.
+func (s *DefaultAuthService) Login(ctx context.Context, email, password, ip string) (*models.User, string, string, error) {
+	if err := s.Guard.Check(ctx, email, ip); err != nil {
+		return nil, "", "", err
+	}
 	user, err := s.Users.GetByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := s.Guard.Fail(ctx, email, ip, nil); err != nil {
+				return nil, "", "", err
+			}
 			return nil, "", "", errors.New("invalid credentials")
 		}
 		return nil, "", "", err
 	}
 	if err := utils.VerifyPassword(user.PasswordHash, password); err != nil {
+		if err := s.Guard.Fail(ctx, email, ip, &user.ID); err != nil {
+			return nil, "", "", err
+		}
 		return nil, "", "", errors.New("invalid credentials")
 	}
-	access, refresh, err := utils.GenerateTokens(user.ID, user.Role, s.Config)
+	return s.finishLogin(ctx, user, ip)
+}
+
+// finishLogin ends a first-factor login: accounts with two-factor
+// authentication get a challenge, everyone else a fresh token family.
+func (s *DefaultAuthService) finishLogin(ctx context.Context, user *models.User, ip string) (*models.User, string, string, error) {
+	cred, err := s.confirmedTOTP(ctx, user.ID)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if cred != nil {
+		challenge, err := utils.GenerateChallengeToken(user.ID, s.Keys, s.Config)
+		if err != nil {
+			return nil, "", "", err
+		}
+		return nil, "", "", &TwoFactorRequiredError{ChallengeToken: challenge, ExpiresIn: utils.ChallengeTTL}
+	}
+	if err := s.Guard.Succeed(ctx, user, ip); err != nil {
+		return nil, "", "", err
+	}
+	access, refresh, err := s.issueTokens(ctx, user, uuid.New(), false)
 	return user, access, refresh, err
 }
 
-func (s *DefaultAuthService) Refresh(ctx context.Context, userID uuid.UUID) (string, string, error) {
-	user, err := s.Users.GetByID(ctx, userID)
+func (s *DefaultAuthService) Refresh(ctx context.Context, refreshToken string) (string, string, error) {
+	if _, err := utils.ParseToken(refreshToken, s.Keys, s.Config.JWTIssuer, s.Config.JWTIssuer); err != nil {
+		return "", "", ErrInvalidRefreshToken
+	}
+	record, err := s.Tokens.Consume(ctx, utils.HashToken(refreshToken))
+	switch {
+	case errors.Is(err, repository.ErrRefreshTokenReused):
+		return "", "", ErrRefreshTokenReused
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return "", "", ErrInvalidRefreshToken
+	case err != nil:
+		return "", "", err
+	}
+	if record.ExpiresAt.Before(time.Now()) {
+		return "", "", ErrInvalidRefreshToken
+	}
+	user, err := s.Users.GetByID(ctx, record.UserID)
 	if err != nil {
 		return "", "", err
 	}
-	access, refresh, err := utils.GenerateTokens(user.ID, user.Role, s.Config)
-	return access, refresh, err
+	return s.issueTokens(ctx, user, record.FamilyID, record.MFA)
 }
 
-// ValidateRefreshToken ensures refresh token is valid; kept here for convenience.
-func (s *DefaultAuthService) ValidateRefreshToken(token string) (uuid.UUID, error) {
-	claims, err := utils.ParseToken(token, s.Config.RefreshSecret)
+func (s *DefaultAuthService) Logout(ctx context.Context, access *utils.TokenClaims, refreshToken string) error {
+	if refreshToken != "" {
+		record, err := s.Tokens.GetByHash(ctx, utils.HashToken(refreshToken))
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && record.UserID.String() != access.Subject) {
+			return ErrInvalidRefreshToken
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.Tokens.RevokeFamily(ctx, record.FamilyID); err != nil {
+			return err
+		}
+	}
+	return s.Denylist.Revoke(ctx, access)
+}
+
+func (s *DefaultAuthService) LogoutAll(ctx context.Context, userID uuid.UUID) error {
+	if err := s.Tokens.RevokeUser(ctx, userID); err != nil {
+		return err
+	}
+	return s.Denylist.RevokeUser(ctx, userID, s.Config.TokenTTL)
+}
+
+// userPermissions resolves the user's role permissions and admin grants.
+func userPermissions(ctx context.Context, admins repository.AdminUserRepository, user *models.User) ([]string, error) {
+	var grants map[string]interface{}
+	admin, err := admins.GetByUser(ctx, user.ID)
+	switch {
+	case err == nil:
+		grants = admin.Permissions
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+	return models.EffectivePermissions(user.Role, grants), nil
+}
+
+// issueTokens signs a new access token carrying the user's current
+// permissions and a refresh token recorded in the given family; mfa marks
+// logins that passed a second factor.
+func (s *DefaultAuthService) issueTokens(ctx context.Context, user *models.User, familyID uuid.UUID, mfa bool) (string, string, error) {
+	perms, err := userPermissions(ctx, s.Admins, user)
 	if err != nil {
-		return uuid.Nil, err
+		return "", "", err
 	}
-	sub, err := uuid.Parse(claims.Subject)
+	access, err := utils.GenerateAccessToken(user.ID, user.Role, perms, mfa, s.Keys, s.Config)
 	if err != nil {
-		return uuid.Nil, err
+		return "", "", err
 	}
-	if time.Unix(claims.ExpiresAt.Unix(), 0).Before(time.Now()) {
-		return uuid.Nil, errors.New("refresh token expired")
+	record := &models.RefreshToken{UserID: user.ID, FamilyID: familyID, MFA: mfa}
+	record.ID = uuid.New()
+	refresh, expires, err := utils.GenerateRefreshToken(user.ID, record.ID, s.Keys, s.Config)
+	if err != nil {
+		return "", "", err
+	}
+	record.TokenHash = utils.HashToken(refresh)
+	record.ExpiresAt = expires
+	if err := s.Tokens.Create(ctx, record); err != nil {
+		return "", "", err
 	}
-	return sub, nil
+	return access, refresh, nil
 }
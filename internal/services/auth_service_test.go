@@ -1,15 +1,22 @@
 package services
 
 import (
+	"bytes"
 	"context"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
 	"crowdreview/config"
+	"crowdreview/internal/mail"
 	"crowdreview/internal/models"
+	"crowdreview/internal/repository"
+	"crowdreview/pkg/utils"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -38,16 +45,229 @@ func (m *mockUserRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.User,
 	}
 	return nil, gorm.ErrRecordNotFound
 }
+func (m *mockUserRepo) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	for _, u := range m.users {
+		if u.Username == username {
+			return u, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+func (m *mockUserRepo) ListByIDs(ctx context.Context, ids []uuid.UUID) ([]models.User, error) {
+	var users []models.User
+	for _, id := range ids {
+		if u, err := m.GetByID(ctx, id); err == nil {
+			users = append(users, *u)
+		}
+	}
+	return users, nil
+}
+
+func (m *mockUserRepo) MarkEmailVerified(ctx context.Context, id uuid.UUID) error {
+	u, err := m.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	u.EmailVerifiedAt = &now
+	return nil
+}
+func (m *mockUserRepo) UpdatePassword(ctx context.Context, id uuid.UUID, hash string) error {
+	u, err := m.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	u.PasswordHash = hash
+	return nil
+}
+func (m *mockUserRepo) UpdateProfile(ctx context.Context, id uuid.UUID, meta datatypes.JSONMap) error {
+	u, err := m.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	u.ProfileMeta = meta
+	return nil
+}
+
+type mockAdminRepo struct {
+	admins map[uuid.UUID]*models.AdminUser
+}
+
+func (m *mockAdminRepo) GetByUser(ctx context.Context, userID uuid.UUID) (*models.AdminUser, error) {
+	if a, ok := m.admins[userID]; ok {
+		return a, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+func (m *mockAdminRepo) SetAccess(ctx context.Context, userID uuid.UUID, role string, grants datatypes.JSONMap) error {
+	if m.admins == nil {
+		m.admins = make(map[uuid.UUID]*models.AdminUser)
+	}
+	m.admins[userID] = &models.AdminUser{UserID: userID, Permissions: grants}
+	return nil
+}
+
+type mockUserTokenRepo struct {
+	tokens map[string]*models.UserToken
+}
+
+func (m *mockUserTokenRepo) Create(ctx context.Context, token *models.UserToken) error {
+	if m.tokens == nil {
+		m.tokens = make(map[string]*models.UserToken)
+	}
+	m.tokens[token.TokenHash] = token
+	return nil
+}
+func (m *mockUserTokenRepo) Consume(ctx context.Context, purpose, hash string) (*models.UserToken, error) {
+	t, ok := m.tokens[hash]
+	if !ok || t.Purpose != purpose || t.UsedAt != nil || t.ExpiresAt.Before(time.Now()) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	now := time.Now()
+	t.UsedAt = &now
+	return t, nil
+}
+
+type mockTwoFactorRepo struct {
+	creds map[uuid.UUID]*models.TOTPCredential
+	codes map[uuid.UUID]map[string]bool // hash -> used
+}
+
+func (m *mockTwoFactorRepo) GetByUser(ctx context.Context, userID uuid.UUID) (*models.TOTPCredential, error) {
+	if c, ok := m.creds[userID]; ok {
+		return c, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+func (m *mockTwoFactorRepo) Enroll(ctx context.Context, cred *models.TOTPCredential) error {
+	if m.creds == nil {
+		m.creds = make(map[uuid.UUID]*models.TOTPCredential)
+	}
+	m.creds[cred.UserID] = cred
+	return nil
+}
+func (m *mockTwoFactorRepo) Confirm(ctx context.Context, userID uuid.UUID, step int64, codeHashes []string) error {
+	now := time.Now()
+	m.creds[userID].ConfirmedAt = &now
+	m.creds[userID].LastUsedStep = step
+	return m.ReplaceRecoveryCodes(ctx, userID, codeHashes)
+}
+func (m *mockTwoFactorRepo) UseStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	c := m.creds[userID]
+	if c.LastUsedStep >= step {
+		return false, nil
+	}
+	c.LastUsedStep = step
+	return true, nil
+}
+func (m *mockTwoFactorRepo) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	used, ok := m.codes[userID][codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	m.codes[userID][codeHash] = true
+	return true, nil
+}
+func (m *mockTwoFactorRepo) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	if m.codes == nil {
+		m.codes = make(map[uuid.UUID]map[string]bool)
+	}
+	m.codes[userID] = make(map[string]bool)
+	for _, h := range codeHashes {
+		m.codes[userID][h] = false
+	}
+	return nil
+}
+func (m *mockTwoFactorRepo) Disable(ctx context.Context, userID uuid.UUID) error {
+	delete(m.creds, userID)
+	delete(m.codes, userID)
+	return nil
+}
+
+type mockTokenRepo struct {
+	tokens map[string]*models.RefreshToken
+}
+
+func (m *mockTokenRepo) Create(ctx context.Context, token *models.RefreshToken) error {
+	if m.tokens == nil {
+		m.tokens = make(map[string]*models.RefreshToken)
+	}
+	m.tokens[token.TokenHash] = token
+	return nil
+}
+func (m *mockTokenRepo) GetByHash(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	if t, ok := m.tokens[hash]; ok {
+		return t, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+func (m *mockTokenRepo) Consume(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	t, err := m.GetByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if t.UsedAt != nil || t.RevokedAt != nil {
+		_ = m.RevokeFamily(ctx, t.FamilyID)
+		return t, repository.ErrRefreshTokenReused
+	}
+	now := time.Now()
+	t.UsedAt = &now
+	return t, nil
+}
+func (m *mockTokenRepo) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	now := time.Now()
+	for _, t := range m.tokens {
+		if t.FamilyID == familyID && t.RevokedAt == nil {
+			t.RevokedAt = &now
+		}
+	}
+	return nil
+}
+func (m *mockTokenRepo) RevokeUser(ctx context.Context, userID uuid.UUID) error {
+	now := time.Now()
+	for _, t := range m.tokens {
+		if t.UserID == userID && t.RevokedAt == nil {
+			t.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func testAuthConfig() config.Config {
+	return config.Config{
+		JWTIssuer:   "crowdreview",
+		JWTAudience: "crowdreview-api",
+		TokenTTL:    time.Minute,
+		RefreshTTL:  time.Hour,
+	}
+}
+
+func testKeySet(t *testing.T) *utils.KeySet {
+	t.Helper()
+	key, err := utils.GenerateSigningKey(utils.AlgEdDSA)
+	require.NoError(t, err)
+	key.ActivatesAt = time.Now().Add(-time.Minute)
+	key.ExpiresAt = time.Now().Add(time.Hour)
+	return utils.NewKeySet(key)
+}
+
+func newTestAuthService(t *testing.T, users *mockUserRepo, outbox *bytes.Buffer) *DefaultAuthService {
+	t.Helper()
+	return &DefaultAuthService{
+		Users:      users,
+		Tokens:     &mockTokenRepo{},
+		Keys:       testKeySet(t),
+		UserTokens: &mockUserTokenRepo{},
+		Mailer:     &mail.LogMailer{W: outbox},
+		TwoFactor:  &mockTwoFactorRepo{},
+		Admins:     &mockAdminRepo{},
+		Config:     testAuthConfig(),
+	}
+}
 
 func TestAuthServiceRegisterAndLogin(t *testing.T) {
 	repo := &mockUserRepo{users: map[string]*models.User{}}
-	cfg := config.Config{
-		JWTSecret:     "secret",
-		RefreshSecret: "refresh",
-		TokenTTL:      time.Minute,
-		RefreshTTL:    time.Hour,
-	}
-	service := &DefaultAuthService{Users: repo, Config: cfg}
+	service := newTestAuthService(t, repo, &bytes.Buffer{})
 
 	user, access, refresh, err := service.Register(context.Background(), "a@b.com", "testuser", "password123")
 	require.NoError(t, err)
@@ -55,7 +275,151 @@ func TestAuthServiceRegisterAndLogin(t *testing.T) {
 	require.NotEmpty(t, refresh)
 	require.Equal(t, "a@b.com", user.Email)
 
-	_, access2, _, err := service.Login(context.Background(), "a@b.com", "password123")
+	_, access2, _, err := service.Login(context.Background(), "a@b.com", "password123", "127.0.0.1")
 	require.NoError(t, err)
 	require.NotEmpty(t, access2)
 }
+
+func TestAuthServiceRefreshRotationAndReuse(t *testing.T) {
+	ctx := context.Background()
+	service := newTestAuthService(t, &mockUserRepo{}, &bytes.Buffer{})
+
+	_, _, first, err := service.Register(ctx, "a@b.com", "testuser", "password123")
+	require.NoError(t, err)
+
+	_, second, err := service.Refresh(ctx, first)
+	require.NoError(t, err)
+	require.NotEqual(t, first, second)
+
+	// Replaying the rotated token revokes the family, including the new token.
+	_, _, err = service.Refresh(ctx, first)
+	require.ErrorIs(t, err, ErrRefreshTokenReused)
+	_, _, err = service.Refresh(ctx, second)
+	require.ErrorIs(t, err, ErrRefreshTokenReused)
+
+	_, _, err = service.Refresh(ctx, "not-a-token")
+	require.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+// mailedToken extracts the token from the last link written by the log mailer.
+func mailedToken(t *testing.T, outbox *bytes.Buffer) string {
+	t.Helper()
+	match := regexp.MustCompile(`\?token=([A-Za-z0-9_-]+)`).FindAllStringSubmatch(outbox.String(), -1)
+	require.NotEmpty(t, match)
+	return match[len(match)-1][1]
+}
+
+func TestAuthServiceEmailVerification(t *testing.T) {
+	ctx := context.Background()
+	users := &mockUserRepo{}
+	outbox := &bytes.Buffer{}
+	service := newTestAuthService(t, users, outbox)
+
+	user, _, _, err := service.Register(ctx, "a@b.com", "testuser", "password123")
+	require.NoError(t, err)
+	require.Nil(t, user.EmailVerifiedAt)
+	require.Contains(t, outbox.String(), "To: a@b.com")
+
+	token := mailedToken(t, outbox)
+	require.NoError(t, service.VerifyEmail(ctx, token))
+	require.NotNil(t, user.EmailVerifiedAt)
+	require.ErrorIs(t, service.VerifyEmail(ctx, token), ErrInvalidEmailToken)
+	require.ErrorIs(t, service.SendVerification(ctx, user.ID), ErrEmailAlreadyVerified)
+}
+
+func TestAuthServicePasswordReset(t *testing.T) {
+	ctx := context.Background()
+	users := &mockUserRepo{}
+	outbox := &bytes.Buffer{}
+	service := newTestAuthService(t, users, outbox)
+
+	_, _, _, err := service.Register(ctx, "a@b.com", "testuser", "password123")
+	require.NoError(t, err)
+
+	outbox.Reset()
+	require.NoError(t, service.RequestPasswordReset(ctx, "nobody@b.com"))
+	require.Empty(t, outbox.String())
+
+	require.NoError(t, service.RequestPasswordReset(ctx, "a@b.com"))
+	token := mailedToken(t, outbox)
+	require.Error(t, service.ResetPassword(ctx, token, "short"))
+	require.NoError(t, service.ResetPassword(ctx, token, "new-password"))
+	require.ErrorIs(t, service.ResetPassword(ctx, token, "another-password"), ErrInvalidEmailToken)
+
+	_, _, _, err = service.Login(ctx, "a@b.com", "password123", "127.0.0.1")
+	require.Error(t, err)
+	_, _, _, err = service.Login(ctx, "a@b.com", "new-password", "127.0.0.1")
+	require.NoError(t, err)
+}
+
+func TestAuthServiceTwoFactorLogin(t *testing.T) {
+	ctx := context.Background()
+	service := newTestAuthService(t, &mockUserRepo{}, &bytes.Buffer{})
+	user, _, _, err := service.Register(ctx, "a@b.com", "testuser", "password123")
+	require.NoError(t, err)
+
+	enrollment, err := service.EnrollTOTP(ctx, user.ID)
+	require.NoError(t, err)
+	require.Contains(t, enrollment.URI, "secret="+enrollment.Secret)
+
+	// Enrollment alone does not change the login flow.
+	_, _, _, err = service.Login(ctx, "a@b.com", "password123", "127.0.0.1")
+	require.NoError(t, err)
+
+	// Codes for the current and next step stay valid even if the step
+	// rolls over while the test runs.
+	step := utils.TOTPStep(time.Now())
+	current, err := utils.TOTPCode(enrollment.Secret, step)
+	require.NoError(t, err)
+	next, err := utils.TOTPCode(enrollment.Secret, step+1)
+	require.NoError(t, err)
+	codes, err := service.ConfirmTOTP(ctx, user.ID, current)
+	require.NoError(t, err)
+	require.Len(t, codes, recoveryCodeCount)
+
+	_, _, _, err = service.Login(ctx, "a@b.com", "password123", "127.0.0.1")
+	var challenge *TwoFactorRequiredError
+	require.ErrorAs(t, err, &challenge)
+
+	// The code used for confirmation cannot be replayed.
+	_, _, _, err = service.CompleteLogin(ctx, challenge.ChallengeToken, current, "127.0.0.1")
+	require.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+
+	_, access, _, err := service.CompleteLogin(ctx, challenge.ChallengeToken, next, "127.0.0.1")
+	require.NoError(t, err)
+	claims, err := utils.ParseToken(access, service.Keys, service.Config.JWTIssuer, service.Config.JWTAudience)
+	require.NoError(t, err)
+	require.True(t, claims.MFA)
+
+	// Recovery codes work once, in any case and with or without the dash.
+	_, _, _, err = service.CompleteLogin(ctx, challenge.ChallengeToken, strings.ToUpper(codes[0]), "127.0.0.1")
+	require.NoError(t, err)
+	_, _, _, err = service.CompleteLogin(ctx, challenge.ChallengeToken, strings.ReplaceAll(codes[0], "-", ""), "127.0.0.1")
+	require.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+
+	// The challenge is not an access token.
+	_, err = utils.ParseToken(challenge.ChallengeToken, service.Keys, service.Config.JWTIssuer, service.Config.JWTAudience)
+	require.Error(t, err)
+}
+
+func TestAuthServiceTokensCarryPermissions(t *testing.T) {
+	ctx := context.Background()
+	users := &mockUserRepo{}
+	service := newTestAuthService(t, users, &bytes.Buffer{})
+	user, access, _, err := service.Register(ctx, "mod@b.com", "mod", "password123")
+	require.NoError(t, err)
+	claims, err := utils.ParseToken(access, service.Keys, service.Config.JWTIssuer, service.Config.JWTAudience)
+	require.NoError(t, err)
+	require.Empty(t, claims.Permissions)
+
+	user.Role = models.RoleModerator
+	require.NoError(t, service.Admins.SetAccess(ctx, user.ID, user.Role, datatypes.JSONMap{
+		models.PermRulesManage:  true,
+		models.PermInsightsRead: false,
+	}))
+	_, access, _, err = service.Login(ctx, "mod@b.com", "password123", "127.0.0.1")
+	require.NoError(t, err)
+	claims, err = utils.ParseToken(access, service.Keys, service.Config.JWTIssuer, service.Config.JWTAudience)
+	require.NoError(t, err)
+	require.Equal(t, []string{models.PermReviewsModerate, models.PermRulesManage}, claims.Permissions)
+}
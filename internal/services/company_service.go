@@ -2,34 +2,102 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
 	"crowdreview/internal/models"
+	"crowdreview/internal/ratings"
 	"crowdreview/internal/repository"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+// ErrCompanyNotFound is returned by SetOwner for an unknown company.
+var ErrCompanyNotFound = errors.New("company not found")
+
+// CreateCompanyOptions tunes company creation.
+type CreateCompanyOptions struct {
+	// AllowDuplicates creates the company even when it resembles an existing one.
+	AllowDuplicates bool
+	// known, when set, is checked for duplicates instead of loading every
+	// company; bulk imports build it once.
+	known *duplicateIndex
+}
+
+// DuplicateCompanyError is returned by Create when probable duplicates exist.
+type DuplicateCompanyError struct {
+	Matches []DuplicateMatch
+}
+
+func (e *DuplicateCompanyError) Error() string {
+	if len(e.Matches) == 1 {
+		return "company resembles 1 existing company; confirm to create anyway"
+	}
+	return fmt.Sprintf("company resembles %d existing companies; confirm to create anyway", len(e.Matches))
+}
+
+// CompanyMergedError is returned by Get for a company that was merged away.
+type CompanyMergedError struct {
+	Into uuid.UUID
+}
+
+func (e *CompanyMergedError) Error() string {
+	return "company was merged into " + e.Into.String()
+}
+
 // CompanyService handles company CRUD.
 type CompanyService interface {
 	List(ctx context.Context) ([]models.Company, error)
 	Get(ctx context.Context, id uuid.UUID) (*models.Company, error)
-	Create(ctx context.Context, input models.Company) (*models.Company, error)
+	Create(ctx context.Context, input models.Company, opts CreateCompanyOptions) (*models.Company, error)
 	Update(ctx context.Context, id uuid.UUID, input models.Company) (*models.Company, error)
+	Ratings(ctx context.Context, id uuid.UUID) (ratings.Snapshot, error)
+	RebuildRatings(ctx context.Context) error
+	// Check runs the validation of Update (id set) or Create (id nil) without
+	// writing anything.
+	Check(ctx context.Context, id *uuid.UUID, input models.Company, opts CreateCompanyOptions) error
+	FindDuplicates(ctx context.Context, name, domain string) ([]DuplicateMatch, error)
+	Merge(ctx context.Context, sourceID, targetID uuid.UUID) error
+	AddAlias(ctx context.Context, companyID uuid.UUID, name string) (*models.CompanyAlias, error)
+	DeleteAlias(ctx context.Context, companyID, aliasID uuid.UUID) error
+	// SetOwner makes a user the company's owner, who manages its webhooks;
+	// nil removes the owner.
+	SetOwner(ctx context.Context, id uuid.UUID, ownerID *uuid.UUID) error
 }
 
 type DefaultCompanyService struct {
-	Companies repository.CompanyRepository
+	Companies  repository.CompanyRepository
+	Aggregates repository.RatingRepository
+	Users      repository.UserRepository
+	Classifier classifier
 }
 
 func (s *DefaultCompanyService) List(ctx context.Context) ([]models.Company, error) {
 	return s.Companies.List(ctx)
 }
 
+// Get returns the company, or a *CompanyMergedError pointing at the survivor
+// when id belongs to a merged company.
 func (s *DefaultCompanyService) Get(ctx context.Context, id uuid.UUID) (*models.Company, error) {
-	return s.Companies.GetByID(ctx, id)
+	company, err := s.Companies.GetByID(ctx, id)
+	if err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
+		return company, err
+	}
+	into, mergedErr := s.Companies.MergedInto(ctx, id)
+	if mergedErr != nil || into == nil {
+		return nil, err
+	}
+	return nil, &CompanyMergedError{Into: *into}
 }
 
-func (s *DefaultCompanyService) Create(ctx context.Context, input models.Company) (*models.Company, error) {
+// Create stores a new company. Unless opts.AllowDuplicates is set, a
+// *DuplicateCompanyError listing similar companies is returned instead.
+func (s *DefaultCompanyService) Create(ctx context.Context, input models.Company, opts CreateCompanyOptions) (*models.Company, error) {
+	if err := s.prepareCreate(ctx, &input, opts); err != nil {
+		return nil, err
+	}
 	if err := s.Companies.Create(ctx, &input); err != nil {
 		return nil, err
 	}
@@ -37,18 +105,143 @@ func (s *DefaultCompanyService) Create(ctx context.Context, input models.Company
 }
 
 func (s *DefaultCompanyService) Update(ctx context.Context, id uuid.UUID, input models.Company) (*models.Company, error) {
+	company, err := s.prepareUpdate(ctx, id, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Companies.Update(ctx, company); err != nil {
+		return nil, err
+	}
+	return company, nil
+}
+
+func (s *DefaultCompanyService) Check(ctx context.Context, id *uuid.UUID, input models.Company, opts CreateCompanyOptions) error {
+	if id != nil {
+		_, err := s.prepareUpdate(ctx, *id, input)
+		return err
+	}
+	return s.prepareCreate(ctx, &input, opts)
+}
+
+func (s *DefaultCompanyService) prepareCreate(ctx context.Context, company *models.Company, opts CreateCompanyOptions) error {
+	if err := validateCompany(company); err != nil {
+		return err
+	}
+	if err := s.Classifier.classify(ctx, company); err != nil {
+		return err
+	}
+	if opts.AllowDuplicates {
+		return nil
+	}
+	index := opts.known
+	if index == nil {
+		var err error
+		if index, err = loadDuplicateIndex(ctx, s.Companies); err != nil {
+			return err
+		}
+	}
+	if matches := index.find(company.Name, company.Domain); len(matches) > 0 {
+		return &DuplicateCompanyError{Matches: matches}
+	}
+	return nil
+}
+
+func (s *DefaultCompanyService) prepareUpdate(ctx context.Context, id uuid.UUID, input models.Company) (*models.Company, error) {
 	company, err := s.Companies.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 	company.Name = input.Name
 	company.Description = input.Description
+	company.IndustryID = input.IndustryID
 	company.Industry = input.Industry
 	company.Domain = input.Domain
 	company.Website = input.Website
+	company.RegionID = input.RegionID
 	company.Location = input.Location
-	if err := s.Companies.Update(ctx, company); err != nil {
+	if err := validateCompany(company); err != nil {
+		return nil, err
+	}
+	if err := s.Classifier.classify(ctx, company); err != nil {
 		return nil, err
 	}
 	return company, nil
 }
+
+// validateCompany trims the free-text fields and checks the required ones.
+func validateCompany(company *models.Company) error {
+	company.Name = strings.TrimSpace(company.Name)
+	company.Domain = normalizeDomain(company.Domain)
+	company.Website = strings.TrimSpace(company.Website)
+	if company.Name == "" {
+		return errors.New("name is required")
+	}
+	if company.Domain != "" && (!strings.Contains(company.Domain, ".") || strings.ContainsAny(company.Domain, " @")) {
+		return fmt.Errorf("invalid domain %q", company.Domain)
+	}
+	return nil
+}
+
+// Ratings returns the company's current rating aggregates.
+func (s *DefaultCompanyService) Ratings(ctx context.Context, id uuid.UUID) (ratings.Snapshot, error) {
+	return s.Aggregates.Snapshot(ctx, id)
+}
+
+// RebuildRatings recomputes every company's aggregates from scratch, for use
+// when the incremental counters have drifted.
+func (s *DefaultCompanyService) RebuildRatings(ctx context.Context) error {
+	return s.Aggregates.Rebuild(ctx)
+}
+
+// FindDuplicates suggests existing companies resembling name or domain.
+func (s *DefaultCompanyService) FindDuplicates(ctx context.Context, name, domain string) ([]DuplicateMatch, error) {
+	index, err := loadDuplicateIndex(ctx, s.Companies)
+	if err != nil {
+		return nil, err
+	}
+	return index.find(name, domain), nil
+}
+
+// Merge folds source into target; see CompanyRepository.Merge.
+func (s *DefaultCompanyService) Merge(ctx context.Context, sourceID, targetID uuid.UUID) error {
+	if sourceID == targetID {
+		return errors.New("cannot merge a company into itself")
+	}
+	return s.Companies.Merge(ctx, sourceID, targetID)
+}
+
+func (s *DefaultCompanyService) AddAlias(ctx context.Context, companyID uuid.UUID, name string) (*models.CompanyAlias, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("alias name is required")
+	}
+	if _, err := s.Companies.GetByID(ctx, companyID); err != nil {
+		return nil, err
+	}
+	alias := &models.CompanyAlias{CompanyID: companyID, Name: name}
+	if err := s.Companies.AddAlias(ctx, alias); err != nil {
+		return nil, err
+	}
+	return alias, nil
+}
+
+func (s *DefaultCompanyService) DeleteAlias(ctx context.Context, companyID, aliasID uuid.UUID) error {
+	return s.Companies.DeleteAlias(ctx, companyID, aliasID)
+}
+
+func (s *DefaultCompanyService) SetOwner(ctx context.Context, id uuid.UUID, ownerID *uuid.UUID) error {
+	if ownerID != nil {
+		_, err := s.Users.GetByID(ctx, *ownerID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+	}
+	err := s.Companies.SetOwner(ctx, id, ownerID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrCompanyNotFound
+	}
+	return err
+}
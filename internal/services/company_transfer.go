@@ -0,0 +1,353 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"crowdreview/internal/models"
+	"crowdreview/internal/ratings"
+	"crowdreview/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Bulk company file formats.
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+const (
+	maxImportRows   = 10000
+	exportBatchSize = 500
+)
+
+// csvColumns is the column order of exported CSV files. Imports accept the
+// same header in any order; id and the rating columns are ignored.
+var csvColumns = []string{"id", "name", "domain", "industry", "location", "description", "website", "approved_count", "mean_rating", "bayesian_rating"}
+
+// CompanyRecord is one company in an import or export file.
+type CompanyRecord struct {
+	ID          *uuid.UUID        `json:"id,omitempty"`
+	Name        string            `json:"name"`
+	Domain      string            `json:"domain"`
+	Industry    string            `json:"industry"`
+	Location    string            `json:"location"`
+	Description string            `json:"description"`
+	Website     string            `json:"website"`
+	Ratings     *ratings.Snapshot `json:"ratings,omitempty"`
+}
+
+// ImportOptions tunes a bulk import.
+type ImportOptions struct {
+	Format          string
+	DryRun          bool
+	AllowDuplicates bool
+}
+
+// ImportRowError explains why one input row was rejected.
+type ImportRowError struct {
+	Line   int
+	Domain string
+	Error  string
+}
+
+// ImportReport summarizes a bulk import.
+type ImportReport struct {
+	DryRun  bool
+	Rows    int
+	Created int
+	Updated int
+	Failed  int
+	Errors  []ImportRowError
+}
+
+// CompanyTransferService imports and exports companies in bulk.
+type CompanyTransferService interface {
+	// Import upserts companies by domain. Each row is validated and committed
+	// on its own, so one bad row does not reject the file.
+	Import(ctx context.Context, r io.Reader, opts ImportOptions) (ImportReport, error)
+	// Export streams every company with its cached rating aggregates.
+	Export(ctx context.Context, w io.Writer, format string) error
+}
+
+type DefaultCompanyTransferService struct {
+	Companies repository.CompanyRepository
+	Service   CompanyService
+}
+
+func (s *DefaultCompanyTransferService) Import(ctx context.Context, r io.Reader, opts ImportOptions) (ImportReport, error) {
+	report := ImportReport{DryRun: opts.DryRun}
+	seen := make(map[string]bool)
+	var known *duplicateIndex
+	if !opts.AllowDuplicates {
+		var err error
+		if known, err = loadDuplicateIndex(ctx, s.Companies); err != nil {
+			return report, err
+		}
+	}
+	err := readRecords(r, opts.Format, func(line int, rec CompanyRecord, parseErr error) error {
+		report.Rows++
+		if report.Rows > maxImportRows {
+			return fmt.Errorf("import is limited to %d rows", maxImportRows)
+		}
+		isNew, err := false, parseErr
+		if err == nil {
+			isNew, err = s.importRecord(ctx, rec, opts, seen, known)
+		}
+		switch {
+		case err != nil:
+			report.Failed++
+			report.Errors = append(report.Errors, ImportRowError{Line: line, Domain: rec.Domain, Error: rowError(err)})
+		case isNew:
+			report.Created++
+		default:
+			report.Updated++
+		}
+		return nil
+	})
+	return report, err
+}
+
+// importRecord upserts one record by domain and reports whether it created a
+// company. Empty fields keep the current value on update. New companies are
+// added to known, so later rows are checked against them too.
+func (s *DefaultCompanyTransferService) importRecord(ctx context.Context, rec CompanyRecord, opts ImportOptions, seen map[string]bool, known *duplicateIndex) (bool, error) {
+	input := models.Company{
+		Name:        rec.Name,
+		Domain:      rec.Domain,
+		Industry:    rec.Industry,
+		Location:    rec.Location,
+		Description: rec.Description,
+		Website:     rec.Website,
+	}
+	createOpts := CreateCompanyOptions{AllowDuplicates: opts.AllowDuplicates, known: known}
+
+	domain := normalizeDomain(rec.Domain)
+	var existing *models.Company
+	if domain != "" {
+		found, err := s.Companies.GetByDomain(ctx, domain)
+		switch {
+		case err == nil:
+			existing = found
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return false, err
+		}
+	}
+
+	if existing == nil {
+		if opts.DryRun {
+			if domain != "" && seen[domain] {
+				// An earlier row of this file would have created it.
+				return false, validateCompany(&input)
+			}
+			seen[domain] = true
+			if err := s.Service.Check(ctx, nil, input, createOpts); err != nil {
+				return true, err
+			}
+			if known != nil {
+				known.add(identityOf(input))
+			}
+			return true, nil
+		}
+		company, err := s.Service.Create(ctx, input, createOpts)
+		if err != nil {
+			return true, err
+		}
+		if known != nil {
+			known.add(identityOf(*company))
+		}
+		return true, nil
+	}
+
+	mergeRecord(&input, *existing)
+	if opts.DryRun {
+		return false, s.Service.Check(ctx, &existing.ID, input, createOpts)
+	}
+	_, err := s.Service.Update(ctx, existing.ID, input)
+	return false, err
+}
+
+// mergeRecord fills the fields an import row left empty from the existing company.
+func mergeRecord(input *models.Company, existing models.Company) {
+	if strings.TrimSpace(input.Name) == "" {
+		input.Name = existing.Name
+	}
+	if strings.TrimSpace(input.Industry) == "" {
+		input.IndustryID = existing.IndustryID
+		input.Industry = existing.Industry
+	}
+	if strings.TrimSpace(input.Location) == "" {
+		input.RegionID = existing.RegionID
+		input.Location = existing.Location
+	}
+	if input.Description == "" {
+		input.Description = existing.Description
+	}
+	if input.Website == "" {
+		input.Website = existing.Website
+	}
+}
+
+func rowError(err error) string {
+	var duplicate *DuplicateCompanyError
+	if errors.As(err, &duplicate) {
+		names := make([]string, 0, len(duplicate.Matches))
+		for _, m := range duplicate.Matches {
+			names = append(names, m.Name)
+		}
+		return "resembles existing companies: " + strings.Join(names, ", ")
+	}
+	return err.Error()
+}
+
+// readRecords decodes CSV (with a header row) or NDJSON, calling fn for every
+// record with its line number. Malformed rows are passed to fn as parseErr;
+// only errors returned by fn or unreadable input stop the scan.
+func readRecords(r io.Reader, format string, fn func(line int, rec CompanyRecord, parseErr error) error) error {
+	switch format {
+	case FormatCSV:
+		return readCSV(r, fn)
+	case FormatNDJSON:
+		return readNDJSON(r, fn)
+	default:
+		return fmt.Errorf("unsupported format %q (use csv or ndjson)", format)
+	}
+}
+
+func readCSV(r io.Reader, fn func(int, CompanyRecord, error) error) error {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return errors.New("empty csv file")
+		}
+		return err
+	}
+	index := make(map[string]int, len(header))
+	for i, col := range header {
+		index[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(col, "\ufeff")))] = i
+	}
+	if _, ok := index["name"]; !ok {
+		return errors.New(`csv header must include a "name" column`)
+	}
+
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		line, _ := reader.FieldPos(0)
+		var parseErr *csv.ParseError
+		if err != nil && !errors.As(err, &parseErr) {
+			return err
+		}
+		cell := func(col string) string {
+			if i, ok := index[col]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		rec := CompanyRecord{
+			Name:        cell("name"),
+			Domain:      cell("domain"),
+			Industry:    cell("industry"),
+			Location:    cell("location"),
+			Description: cell("description"),
+			Website:     cell("website"),
+		}
+		if parseErr != nil {
+			line = parseErr.Line
+			err = parseErr.Err
+		}
+		if err := fn(line, rec, err); err != nil {
+			return err
+		}
+	}
+}
+
+func readNDJSON(r io.Reader, fn func(int, CompanyRecord, error) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var rec CompanyRecord
+		err := json.Unmarshal([]byte(text), &rec)
+		if err != nil {
+			err = fmt.Errorf("invalid json: %w", err)
+		}
+		if err := fn(line, rec, err); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (s *DefaultCompanyTransferService) Export(ctx context.Context, w io.Writer, format string) error {
+	switch format {
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(csvColumns); err != nil {
+			return err
+		}
+		err := s.Companies.Each(ctx, exportBatchSize, func(batch []models.Company) error {
+			for _, c := range batch {
+				snapshot, _ := ratings.FromCache(c.Metrics)
+				if err := writer.Write([]string{
+					c.ID.String(), c.Name, c.Domain, c.Industry, c.Location, c.Description, c.Website,
+					strconv.FormatInt(snapshot.ApprovedCount, 10),
+					strconv.FormatFloat(snapshot.Mean, 'f', 2, 64),
+					strconv.FormatFloat(snapshot.Bayesian, 'f', 2, 64),
+				}); err != nil {
+					return err
+				}
+			}
+			writer.Flush()
+			return writer.Error()
+		})
+		if err != nil {
+			return err
+		}
+		writer.Flush()
+		return writer.Error()
+	case FormatNDJSON:
+		encoder := json.NewEncoder(w)
+		return s.Companies.Each(ctx, exportBatchSize, func(batch []models.Company) error {
+			for _, c := range batch {
+				id := c.ID
+				rec := CompanyRecord{
+					ID:          &id,
+					Name:        c.Name,
+					Domain:      c.Domain,
+					Industry:    c.Industry,
+					Location:    c.Location,
+					Description: c.Description,
+					Website:     c.Website,
+				}
+				if snapshot, ok := ratings.FromCache(c.Metrics); ok {
+					rec.Ratings = &snapshot
+				}
+				if err := encoder.Encode(rec); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	default:
+		return fmt.Errorf("unsupported format %q (use csv or ndjson)", format)
+	}
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type readRow struct {
+	line int
+	rec  CompanyRecord
+	err  error
+}
+
+func collectRecords(t *testing.T, input, format string) []readRow {
+	t.Helper()
+	var rows []readRow
+	err := readRecords(strings.NewReader(input), format, func(line int, rec CompanyRecord, parseErr error) error {
+		rows = append(rows, readRow{line: line, rec: rec, err: parseErr})
+		return nil
+	})
+	require.NoError(t, err)
+	return rows
+}
+
+func TestReadRecordsCSV(t *testing.T) {
+	input := "Domain,NAME,location\n" +
+		"acme.com.br, ACME ,\"São Paulo, SP\"\n" +
+		"globex.com,Globex,x,extra\n" +
+		"initech.com,Initech,\n"
+	rows := collectRecords(t, input, FormatCSV)
+	require.Len(t, rows, 3)
+
+	require.NoError(t, rows[0].err)
+	require.Equal(t, 2, rows[0].line)
+	require.Equal(t, "ACME", rows[0].rec.Name)
+	require.Equal(t, "acme.com.br", rows[0].rec.Domain)
+	require.Equal(t, "São Paulo, SP", rows[0].rec.Location)
+
+	require.Error(t, rows[1].err)
+	require.Equal(t, 3, rows[1].line)
+
+	require.NoError(t, rows[2].err)
+	require.Equal(t, "Initech", rows[2].rec.Name)
+}
+
+func TestReadRecordsCSVRequiresName(t *testing.T) {
+	err := readRecords(strings.NewReader("domain\nacme.com\n"), FormatCSV, func(int, CompanyRecord, error) error { return nil })
+	require.Error(t, err)
+}
+
+func TestReadRecordsNDJSON(t *testing.T) {
+	input := `{"name":"ACME","domain":"acme.com.br"}` + "\n\n" + `{"name":` + "\n"
+	rows := collectRecords(t, input, FormatNDJSON)
+	require.Len(t, rows, 2)
+	require.NoError(t, rows[0].err)
+	require.Equal(t, "ACME", rows[0].rec.Name)
+	require.Error(t, rows[1].err)
+	require.Equal(t, 3, rows[1].line)
+}
+
+func TestReadRecordsUnknownFormat(t *testing.T) {
+	err := readRecords(strings.NewReader(""), "xml", func(int, CompanyRecord, error) error { return nil })
+	require.Error(t, err)
+}
@@ -1,38 +1,185 @@
 package services
 
 import (
+	"context"
+	"errors"
+
 	"crowdreview/config"
+	"crowdreview/internal/activity"
+	"crowdreview/internal/mail"
+	"crowdreview/internal/models"
+	"crowdreview/internal/oidc"
 	"crowdreview/internal/repository"
 	"crowdreview/internal/validation"
+	"crowdreview/pkg/utils"
 
 	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
 )
 
 // Services aggregates service layer dependencies.
 type Services struct {
-	Auth    AuthService
-	Company CompanyService
-	Review  ReviewService
-	Admin   AdminService
+	Auth          AuthService
+	Company       CompanyService
+	Review        ReviewService
+	Admin         AdminService
+	Criteria      CriteriaService
+	Search        SearchService
+	Directory     DirectoryService
+	Transfer      CompanyTransferService
+	Keys          KeyService
+	Profile       ProfileService
+	Privacy       PrivacyService
+	Achievements  AchievementService
+	Leaderboard   LeaderboardService
+	Points        PointsService
+	Notifications NotificationService
+	Webhooks      WebhookService
+	Outbox        OutboxService
+	Activity      ActivityService
 }
 
 // NewServices wires concrete service implementations.
-func NewServices(cfg config.Config, repos repository.Repositories, rdb *redis.Client, worker *validation.FraudWorker) Services {
-	auth := &DefaultAuthService{Users: repos.User, Config: cfg}
-	company := &DefaultCompanyService{Companies: repos.Company}
+func NewServices(cfg config.Config, repos repository.Repositories, rdb *redis.Client, worker *validation.FraudWorker, mailer mail.Mailer) Services {
+	keys := &DefaultKeyService{Keys: repos.Keys, Set: utils.NewKeySet(), Config: cfg}
+	denylist := utils.NewTokenDenylist(rdb)
+	activityFeed := &DefaultActivityService{
+		Bus:      activity.NewBus(rdb),
+		Reviews:  repos.Review,
+		Users:    repos.User,
+		Admins:   repos.Admins,
+		Denylist: denylist,
+	}
+	auth := &DefaultAuthService{
+		Users:      repos.User,
+		Keys:       keys.Set,
+		Tokens:     repos.Tokens,
+		Denylist:   denylist,
+		UserTokens: repos.UserTokens,
+		Mailer:     mailer,
+		Guard:      &LoginGuard{Redis: rdb, Events: repos.Security, Activity: activityFeed, Config: cfg},
+		TwoFactor:  repos.TwoFactor,
+		Identities: repos.Identities,
+		Admins:     repos.Admins,
+		OIDC:       oidc.NewRegistry(cfg),
+		Config:     cfg,
+	}
+	classify := classifier{Taxonomy: repos.Taxonomy, DefaultCountry: cfg.DefaultCountry}
+	company := &DefaultCompanyService{Companies: repos.Company, Aggregates: repos.Rating, Users: repos.User, Classifier: classify}
+	webhooks := &DefaultWebhookService{
+		Webhooks:  repos.Webhooks,
+		Companies: repos.Company,
+		Reviews:   repos.Review,
+		Config:    cfg,
+	}
 	review := &DefaultReviewService{
 		Reviews:     repos.Review,
+		Users:       repos.User,
 		Companies:   repos.Company,
-		Worker:      worker,
+		Criteria:    repos.Criteria,
+		Classifier:  classify,
 		RateLimiter: rdb,
 		Config:      cfg,
 	}
-	admin := &DefaultAdminService{Reviews: repos.Review, Validation: repos.Validation, DB: repos.DB}
+	criteria := &DefaultCriteriaService{Criteria: repos.Criteria, Companies: repos.Company, Classifier: classify}
+	directory := &DefaultDirectoryService{
+		Taxonomy:       repos.Taxonomy,
+		Companies:      repos.Company,
+		Criteria:       repos.Criteria,
+		DefaultCountry: cfg.DefaultCountry,
+	}
+	notifications := &DefaultNotificationService{
+		Notifications: repos.Notifications,
+		Users:         repos.User,
+		Reviews:       repos.Review,
+		Channels:      []NotificationChannel{&EmailDigestChannel{Mailer: mailer, FrontendURL: cfg.FrontendURL}},
+		Config:        cfg,
+	}
+	leaderboard := &DefaultLeaderboardService{Redis: rdb, Users: repos.User, Tiers: cfg.ReputationTiers}
+	achievements := &DefaultAchievementService{
+		Achievements:  repos.Achievement,
+		Points:        repos.Points,
+		Users:         repos.User,
+		Leaderboard:   leaderboard,
+		Notifications: notifications,
+		Classifier:    classify,
+	}
+	admin := &DefaultAdminService{
+		Reviews:    repos.Review,
+		Validation: repos.Validation,
+		Security:   repos.Security,
+		Users:      repos.User,
+		Admins:     repos.Admins,
+		Denylist:   auth.Denylist,
+		Activity:   activityFeed,
+		Config:     cfg,
+		DB:         repos.DB,
+	}
+
+	outbox := &DefaultOutboxService{Outbox: repos.Outbox, Config: cfg}
+	if worker != nil {
+		// The author's score, preloaded with the review, feeds the trust signal.
+		outbox.Subscribe(models.OutboxReviewCreated, func(ctx context.Context, e models.OutboxEvent) error {
+			review, err := repos.Review.GetByID(ctx, e.AggregateID)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			return worker.Submit(ctx, *review)
+		})
+	}
+	outbox.Subscribe(models.OutboxReviewCreated, func(ctx context.Context, e models.OutboxEvent) error {
+		return webhooks.ReviewCreated(ctx, e.ID, e.AggregateID)
+	})
+	outbox.Subscribe(models.OutboxReviewStatusChanged, func(ctx context.Context, e models.OutboxEvent) error {
+		return achievements.ReviewStatusChanged(ctx, e.AggregateID, e.Status())
+	})
+	outbox.Subscribe(models.OutboxReviewStatusChanged, func(ctx context.Context, e models.OutboxEvent) error {
+		return notifications.ReviewStatusChanged(ctx, e.ID, e.AggregateID, e.Status())
+	})
+	outbox.Subscribe(models.OutboxReviewStatusChanged, func(ctx context.Context, e models.OutboxEvent) error {
+		return webhooks.ReviewStatusChanged(ctx, e.ID, e.AggregateID, e.Status())
+	})
+	outbox.Subscribe(models.OutboxReviewStatusChanged, func(ctx context.Context, e models.OutboxEvent) error {
+		return activityFeed.ReviewStatusChanged(ctx, e.ID, e.AggregateID, e.Status())
+	})
 
 	return Services{
-		Auth:    auth,
-		Company: company,
-		Review:  review,
-		Admin:   admin,
+		Auth:      auth,
+		Company:   company,
+		Review:    review,
+		Admin:     admin,
+		Criteria:  criteria,
+		Search:    &DefaultSearchService{Index: repos.Search},
+		Directory: directory,
+		Transfer:  &DefaultCompanyTransferService{Companies: repos.Company, Service: company},
+		Keys:      keys,
+		Profile: &DefaultProfileService{
+			Users:        repos.User,
+			Reviews:      repos.Review,
+			Achievements: repos.Achievement,
+			Tiers:        cfg.ReputationTiers,
+		},
+		Privacy: &DefaultPrivacyService{
+			Privacy:     repos.Privacy,
+			Users:       repos.User,
+			Denylist:    auth.Denylist,
+			Leaderboard: leaderboard,
+			Config:      cfg,
+		},
+		Achievements: achievements,
+		Leaderboard:  leaderboard,
+		Points: &DefaultPointsService{
+			Points:      repos.Points,
+			Users:       repos.User,
+			Leaderboard: leaderboard,
+			Config:      cfg,
+		},
+		Notifications: notifications,
+		Webhooks:      webhooks,
+		Outbox:        outbox,
+		Activity:      activityFeed,
 	}
 }
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"crowdreview/internal/models"
+	"crowdreview/internal/repository"
+	"crowdreview/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+var criterionKeyPattern = regexp.MustCompile(`^[a-z0-9_]{1,40}$`)
+
+// CriteriaService manages the rating criteria offered per industry.
+type CriteriaService interface {
+	List(ctx context.Context) ([]models.RatingCriterion, error)
+	ForCompany(ctx context.Context, companyID uuid.UUID) ([]models.RatingCriterion, error)
+	Create(ctx context.Context, input models.RatingCriterion) (*models.RatingCriterion, error)
+	Update(ctx context.Context, id uuid.UUID, input models.RatingCriterion) (*models.RatingCriterion, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type DefaultCriteriaService struct {
+	Criteria   repository.CriteriaRepository
+	Companies  repository.CompanyRepository
+	Classifier classifier
+}
+
+func (s *DefaultCriteriaService) List(ctx context.Context) ([]models.RatingCriterion, error) {
+	return s.Criteria.List(ctx)
+}
+
+// ForCompany returns the criteria reviewers of the company may score.
+func (s *DefaultCriteriaService) ForCompany(ctx context.Context, companyID uuid.UUID) ([]models.RatingCriterion, error) {
+	company, err := s.Companies.GetByID(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+	industry, err := s.Classifier.industryKey(ctx, company)
+	if err != nil {
+		return nil, err
+	}
+	criteria, err := s.Criteria.ListForIndustry(ctx, industry)
+	if err != nil {
+		return nil, err
+	}
+	return effectiveCriteria(criteria), nil
+}
+
+func (s *DefaultCriteriaService) Create(ctx context.Context, input models.RatingCriterion) (*models.RatingCriterion, error) {
+	criterion := models.RatingCriterion{
+		Industry: utils.Slugify(input.Industry),
+		Key:      input.Key,
+		Label:    strings.TrimSpace(input.Label),
+		Position: input.Position,
+	}
+	if err := validateCriterion(criterion); err != nil {
+		return nil, err
+	}
+	if criterion.Industry != "" {
+		if _, err := s.Classifier.Taxonomy.GetIndustryBySlug(ctx, criterion.Industry); err != nil {
+			return nil, fmt.Errorf("unknown industry %q", criterion.Industry)
+		}
+	}
+	if err := s.Criteria.Create(ctx, &criterion); err != nil {
+		return nil, err
+	}
+	return &criterion, nil
+}
+
+// Update changes the label and position. Industry and key are immutable so
+// scores already given keep their meaning.
+func (s *DefaultCriteriaService) Update(ctx context.Context, id uuid.UUID, input models.RatingCriterion) (*models.RatingCriterion, error) {
+	criterion, err := s.Criteria.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if label := strings.TrimSpace(input.Label); label != "" {
+		criterion.Label = label
+	}
+	criterion.Position = input.Position
+	if err := s.Criteria.Update(ctx, criterion); err != nil {
+		return nil, err
+	}
+	return criterion, nil
+}
+
+func (s *DefaultCriteriaService) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.Criteria.Delete(ctx, id)
+}
+
+func validateCriterion(c models.RatingCriterion) error {
+	if !criterionKeyPattern.MatchString(c.Key) {
+		return errors.New("key must be 1-40 lowercase letters, digits or underscores")
+	}
+	if c.Label == "" {
+		return errors.New("label is required")
+	}
+	return nil
+}
+
+// effectiveCriteria drops global criteria shadowed by an industry-specific
+// criterion with the same key, keeping the repository's ordering.
+func effectiveCriteria(criteria []models.RatingCriterion) []models.RatingCriterion {
+	specific := make(map[string]bool)
+	for _, c := range criteria {
+		if c.Industry != "" {
+			specific[c.Key] = true
+		}
+	}
+	out := make([]models.RatingCriterion, 0, len(criteria))
+	for _, c := range criteria {
+		if c.Industry == "" && specific[c.Key] {
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// scoresForCriteria validates per-criterion scores keyed by criterion key.
+// Every criterion is optional, but unknown keys and out-of-range scores are
+// rejected.
+func scoresForCriteria(criteria []models.RatingCriterion, scores map[string]int) ([]models.ReviewScore, error) {
+	if len(scores) == 0 {
+		return nil, nil
+	}
+	byKey := make(map[string]models.RatingCriterion)
+	for _, c := range effectiveCriteria(criteria) {
+		byKey[c.Key] = c
+	}
+	out := make([]models.ReviewScore, 0, len(scores))
+	for key, score := range scores {
+		criterion, ok := byKey[key]
+		if !ok {
+			return nil, fmt.Errorf("unknown rating criterion %q", key)
+		}
+		if score < 1 || score > 5 {
+			return nil, fmt.Errorf("score for %q must be between 1 and 5", key)
+		}
+		out = append(out, models.ReviewScore{CriterionID: criterion.ID, Score: score})
+	}
+	return out, nil
+}
+
+// attachCriteria fills in the Criterion of freshly created scores so callers
+// can render them without reloading the review.
+func attachCriteria(scores []models.ReviewScore, criteria []models.RatingCriterion) {
+	byID := make(map[uuid.UUID]models.RatingCriterion, len(criteria))
+	for _, c := range criteria {
+		byID[c.ID] = c
+	}
+	for i := range scores {
+		scores[i].Criterion = byID[scores[i].CriterionID]
+	}
+}
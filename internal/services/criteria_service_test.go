@@ -0,0 +1,35 @@
+package services
+
+import (
+	"testing"
+
+	"crowdreview/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScoresForCriteria(t *testing.T) {
+	global := models.RatingCriterion{Key: "service", Label: "Service"}
+	global.ID = uuid.New()
+	retail := models.RatingCriterion{Industry: "retail", Key: "service", Label: "Store service"}
+	retail.ID = uuid.New()
+	price := models.RatingCriterion{Industry: "retail", Key: "price", Label: "Price"}
+	price.ID = uuid.New()
+	criteria := []models.RatingCriterion{global, retail, price}
+
+	scores, err := scoresForCriteria(criteria, map[string]int{"service": 4})
+	require.NoError(t, err)
+	require.Len(t, scores, 1)
+	require.Equal(t, retail.ID, scores[0].CriterionID, "industry criterion shadows the global one")
+
+	scores, err = scoresForCriteria(criteria, nil)
+	require.NoError(t, err)
+	require.Empty(t, scores)
+
+	_, err = scoresForCriteria(criteria, map[string]int{"delivery": 3})
+	require.Error(t, err)
+
+	_, err = scoresForCriteria(criteria, map[string]int{"price": 6})
+	require.Error(t, err)
+}
@@ -0,0 +1,451 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"crowdreview/internal/models"
+	"crowdreview/internal/repository"
+	"crowdreview/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultDirectoryLimit = 20
+	maxDirectoryLimit     = 100
+)
+
+var (
+	ErrIndustryNotFound = errors.New("industry not found")
+	ErrRegionNotFound   = errors.New("region not found")
+)
+
+// BrowseInput selects a directory page by industry slug and/or region.
+type BrowseInput struct {
+	Industry string
+	RegionID *uuid.UUID
+	Limit    int
+	Offset   int
+}
+
+// IndustryUpdate renames an industry and, when Move is set, moves it under
+// ParentID, or to the root when ParentID is nil.
+type IndustryUpdate struct {
+	Name     string
+	Move     bool
+	ParentID *uuid.UUID
+}
+
+// MappingReport summarizes a legacy taxonomy mapping run.
+type MappingReport struct {
+	Companies         int
+	IndustriesCreated []string
+	RegionsCreated    []string
+	CriteriaUpdated   int
+}
+
+// DirectoryService manages the industry taxonomy and region hierarchy and
+// browses companies through them.
+type DirectoryService interface {
+	IndustryTree(ctx context.Context) ([]*TaxonomyNode, error)
+	RegionTree(ctx context.Context) ([]*TaxonomyNode, error)
+	Browse(ctx context.Context, input BrowseInput) ([]models.Company, int64, error)
+
+	CreateIndustry(ctx context.Context, input models.Industry) (*models.Industry, error)
+	UpdateIndustry(ctx context.Context, id uuid.UUID, input IndustryUpdate) (*models.Industry, error)
+	DeleteIndustry(ctx context.Context, id uuid.UUID) error
+	CreateRegion(ctx context.Context, input models.Region) (*models.Region, error)
+	UpdateRegion(ctx context.Context, id uuid.UUID, input models.Region) (*models.Region, error)
+	DeleteRegion(ctx context.Context, id uuid.UUID) error
+
+	// MapLegacy links free-text company industries and locations to the
+	// taxonomy, creating missing nodes, and rewrites criteria to industry slugs.
+	MapLegacy(ctx context.Context, dryRun bool) (MappingReport, error)
+}
+
+type DefaultDirectoryService struct {
+	Taxonomy       repository.TaxonomyRepository
+	Companies      repository.CompanyRepository
+	Criteria       repository.CriteriaRepository
+	DefaultCountry string
+}
+
+func (s *DefaultDirectoryService) IndustryTree(ctx context.Context) ([]*TaxonomyNode, error) {
+	industries, err := s.Taxonomy.ListIndustries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byIndustry, _, err := s.Taxonomy.CountCompanies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return buildTree(industryNodes(industries), byIndustry), nil
+}
+
+func (s *DefaultDirectoryService) RegionTree(ctx context.Context) ([]*TaxonomyNode, error) {
+	regions, err := s.Taxonomy.ListRegions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, byRegion, err := s.Taxonomy.CountCompanies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return buildTree(regionNodes(regions), byRegion), nil
+}
+
+// Browse lists companies in an industry or region, including sub-categories
+// and sub-regions.
+func (s *DefaultDirectoryService) Browse(ctx context.Context, input BrowseInput) ([]models.Company, int64, error) {
+	filter := repository.CompanyFilter{Limit: input.Limit, Offset: input.Offset}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultDirectoryLimit
+	}
+	if filter.Limit > maxDirectoryLimit {
+		filter.Limit = maxDirectoryLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	if input.Industry != "" {
+		industry, err := s.Taxonomy.GetIndustryBySlug(ctx, input.Industry)
+		if err != nil {
+			return nil, 0, err
+		}
+		industries, err := s.Taxonomy.ListIndustries(ctx)
+		if err != nil {
+			return nil, 0, err
+		}
+		filter.IndustryIDs = subtreeIDs(industryNodes(industries), industry.ID)
+	}
+	if input.RegionID != nil {
+		regions, err := s.Taxonomy.ListRegions(ctx)
+		if err != nil {
+			return nil, 0, err
+		}
+		filter.RegionIDs = subtreeIDs(regionNodes(regions), *input.RegionID)
+	}
+	return s.Companies.Browse(ctx, filter)
+}
+
+func (s *DefaultDirectoryService) CreateIndustry(ctx context.Context, input models.Industry) (*models.Industry, error) {
+	industry := models.Industry{
+		ParentID: input.ParentID,
+		Name:     strings.TrimSpace(input.Name),
+		Slug:     utils.Slugify(input.Slug),
+	}
+	if industry.Slug == "" {
+		industry.Slug = utils.Slugify(industry.Name)
+	}
+	if industry.Name == "" || industry.Slug == "" {
+		return nil, errors.New("name is required")
+	}
+	if industry.ParentID != nil {
+		if _, err := s.Taxonomy.GetIndustry(ctx, *industry.ParentID); err != nil {
+			return nil, fmt.Errorf("unknown parent industry: %w", err)
+		}
+	}
+	if err := s.Taxonomy.CreateIndustry(ctx, &industry); err != nil {
+		return nil, err
+	}
+	return &industry, nil
+}
+
+// UpdateIndustry renames or moves an industry. The slug stays fixed because
+// criteria and links refer to it.
+func (s *DefaultDirectoryService) UpdateIndustry(ctx context.Context, id uuid.UUID, input IndustryUpdate) (*models.Industry, error) {
+	industry, err := s.Taxonomy.GetIndustry(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrIndustryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if input.Move && input.ParentID != nil {
+		industries, err := s.Taxonomy.ListIndustries(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, descendant := range subtreeIDs(industryNodes(industries), id) {
+			if descendant == *input.ParentID {
+				return nil, errors.New("an industry cannot be moved under itself")
+			}
+		}
+		if _, err := s.Taxonomy.GetIndustry(ctx, *input.ParentID); err != nil {
+			return nil, fmt.Errorf("unknown parent industry: %w", err)
+		}
+	}
+	if input.Move {
+		industry.ParentID = input.ParentID
+	}
+	renamed := false
+	if name := strings.TrimSpace(input.Name); name != "" && name != industry.Name {
+		industry.Name = name
+		renamed = true
+	}
+	if err := s.Taxonomy.UpdateIndustry(ctx, industry); err != nil {
+		return nil, err
+	}
+	if renamed {
+		if err := s.Taxonomy.RenameIndustry(ctx, industry.ID, industry.Name); err != nil {
+			return nil, err
+		}
+	}
+	return industry, nil
+}
+
+func (s *DefaultDirectoryService) DeleteIndustry(ctx context.Context, id uuid.UUID) error {
+	tree, err := s.IndustryTree(ctx)
+	if err != nil {
+		return err
+	}
+	if node := findNode(tree, id); node != nil && (node.Companies > 0 || len(node.Children) > 0) {
+		return errors.New("industry still has sub-industries or companies")
+	}
+	return s.Taxonomy.DeleteIndustry(ctx, id)
+}
+
+func (s *DefaultDirectoryService) CreateRegion(ctx context.Context, input models.Region) (*models.Region, error) {
+	region := models.Region{
+		ParentID: input.ParentID,
+		Kind:     input.Kind,
+		Name:     strings.TrimSpace(input.Name),
+		Slug:     utils.Slugify(input.Name),
+	}
+	if region.Slug == "" {
+		return nil, errors.New("name is required")
+	}
+	switch region.Kind {
+	case models.RegionCountry:
+		if region.ParentID != nil {
+			return nil, errors.New("countries cannot have a parent")
+		}
+	case models.RegionState, models.RegionCity:
+		if region.ParentID == nil {
+			return nil, errors.New("states and cities need a parent region")
+		}
+		parent, err := s.Taxonomy.GetRegion(ctx, *region.ParentID)
+		if err != nil {
+			return nil, fmt.Errorf("unknown parent region: %w", err)
+		}
+		if parent.Kind == models.RegionCity || (region.Kind == models.RegionState && parent.Kind != models.RegionCountry) {
+			return nil, fmt.Errorf("a %s cannot be placed under a %s", region.Kind, parent.Kind)
+		}
+	default:
+		return nil, errors.New("kind must be country, state or city")
+	}
+	if err := s.ensureUniqueRegion(ctx, region.ParentID, region.Slug); err != nil {
+		return nil, err
+	}
+	if err := s.Taxonomy.CreateRegion(ctx, &region); err != nil {
+		return nil, err
+	}
+	return &region, nil
+}
+
+// UpdateRegion renames a region and refreshes the location of every company
+// below it.
+func (s *DefaultDirectoryService) UpdateRegion(ctx context.Context, id uuid.UUID, input models.Region) (*models.Region, error) {
+	region, err := s.Taxonomy.GetRegion(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRegionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(input.Name)
+	if name == "" || name == region.Name {
+		return region, nil
+	}
+	slug := utils.Slugify(name)
+	if slug != region.Slug {
+		if err := s.ensureUniqueRegion(ctx, region.ParentID, slug); err != nil {
+			return nil, err
+		}
+	}
+	region.Name = name
+	region.Slug = slug
+	if err := s.Taxonomy.UpdateRegion(ctx, region); err != nil {
+		return nil, err
+	}
+
+	regions, err := s.Taxonomy.ListRegions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, rid := range subtreeIDs(regionNodes(regions), id) {
+		path, err := regionPath(ctx, s.Taxonomy, rid)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.Taxonomy.SetLocation(ctx, rid, locationLabel(path)); err != nil {
+			return nil, err
+		}
+	}
+	return region, nil
+}
+
+func (s *DefaultDirectoryService) DeleteRegion(ctx context.Context, id uuid.UUID) error {
+	tree, err := s.RegionTree(ctx)
+	if err != nil {
+		return err
+	}
+	if node := findNode(tree, id); node != nil && (node.Companies > 0 || len(node.Children) > 0) {
+		return errors.New("region still has sub-regions or companies")
+	}
+	return s.Taxonomy.DeleteRegion(ctx, id)
+}
+
+func (s *DefaultDirectoryService) MapLegacy(ctx context.Context, dryRun bool) (MappingReport, error) {
+	var report MappingReport
+	companies, err := s.Companies.ListUnclassified(ctx)
+	if err != nil {
+		return report, err
+	}
+	run := &mappingRun{dryRun: dryRun, industries: map[string]*models.Industry{}, regions: map[string]*models.Region{}}
+	for _, company := range companies {
+		report.Companies++
+		if company.IndustryID == nil && strings.TrimSpace(company.Industry) != "" {
+			industry, created, err := s.ensureIndustry(ctx, run, company.Industry)
+			if err != nil {
+				return report, err
+			}
+			if created {
+				report.IndustriesCreated = append(report.IndustriesCreated, company.Industry)
+			}
+			company.IndustryID = &industry.ID
+			company.Industry = industry.Name
+		}
+		if company.RegionID == nil && strings.TrimSpace(company.Location) != "" {
+			path, created, err := s.ensureRegionPath(ctx, run, company.Location)
+			if err != nil {
+				return report, err
+			}
+			report.RegionsCreated = append(report.RegionsCreated, created...)
+			company.RegionID = &path[0].ID
+			company.Location = locationLabel(path)
+		}
+		if !dryRun {
+			if err := s.Companies.Update(ctx, &company); err != nil {
+				return report, err
+			}
+		}
+	}
+
+	criteria, err := s.Criteria.List(ctx)
+	if err != nil {
+		return report, err
+	}
+	for _, criterion := range criteria {
+		slug := utils.Slugify(criterion.Industry)
+		if slug == criterion.Industry {
+			continue
+		}
+		report.CriteriaUpdated++
+		if !dryRun {
+			criterion.Industry = slug
+			if err := s.Criteria.Update(ctx, &criterion); err != nil {
+				return report, err
+			}
+		}
+	}
+	return report, nil
+}
+
+// mappingRun remembers nodes resolved during one MapLegacy call, so dry runs
+// (which write nothing) still report each missing node once.
+type mappingRun struct {
+	dryRun     bool
+	industries map[string]*models.Industry // by slug
+	regions    map[string]*models.Region   // by parent ID + "/" + slug
+}
+
+// ensureIndustry finds the industry for a free-text name, creating it when
+// missing.
+func (s *DefaultDirectoryService) ensureIndustry(ctx context.Context, run *mappingRun, name string) (*models.Industry, bool, error) {
+	slug := utils.Slugify(name)
+	if industry, ok := run.industries[slug]; ok {
+		return industry, false, nil
+	}
+	industry, err := s.Taxonomy.GetIndustryBySlug(ctx, slug)
+	created := false
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		industry = &models.Industry{Slug: slug, Name: strings.TrimSpace(name)}
+		created = true
+		if run.dryRun {
+			industry.ID = uuid.New()
+		} else if err := s.Taxonomy.CreateIndustry(ctx, industry); err != nil {
+			return nil, false, err
+		}
+	case err != nil:
+		return nil, false, err
+	}
+	run.industries[slug] = industry
+	return industry, created, nil
+}
+
+// ensureRegionPath resolves a free-text location, creating missing regions.
+// The returned path is most specific first.
+func (s *DefaultDirectoryService) ensureRegionPath(ctx context.Context, run *mappingRun, text string) ([]models.Region, []string, error) {
+	parts := locationParts(text, s.DefaultCountry)
+	kinds := regionKinds(len(parts))
+	var path []models.Region
+	var created []string
+	var parent *uuid.UUID
+	for i, name := range parts {
+		slug := utils.Slugify(name)
+		key := "/" + slug
+		if parent != nil {
+			key = parent.String() + key
+		}
+		region, ok := run.regions[key]
+		if !ok {
+			var err error
+			region, err = s.Taxonomy.FindRegion(ctx, parent, slug)
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				region = &models.Region{ParentID: parent, Kind: kinds[i], Slug: slug, Name: name}
+				if run.dryRun {
+					region.ID = uuid.New()
+				} else if err := s.Taxonomy.CreateRegion(ctx, region); err != nil {
+					return nil, nil, err
+				}
+				created = append(created, name)
+			case err != nil:
+				return nil, nil, err
+			}
+			run.regions[key] = region
+		}
+		path = append([]models.Region{*region}, path...)
+		parent = &region.ID
+	}
+	return path, created, nil
+}
+
+func (s *DefaultDirectoryService) ensureUniqueRegion(ctx context.Context, parentID *uuid.UUID, slug string) error {
+	_, err := s.Taxonomy.FindRegion(ctx, parentID, slug)
+	if err == nil {
+		return errors.New("a region with this name already exists here")
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return err
+}
+
+func findNode(nodes []*TaxonomyNode, id uuid.UUID) *TaxonomyNode {
+	for _, n := range nodes {
+		if n.ID == id {
+			return n
+		}
+		if found := findNode(n.Children, id); found != nil {
+			return found
+		}
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+
+	"crowdreview/internal/models"
+	"crowdreview/internal/repository"
+	"crowdreview/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+// duplicateThreshold is the minimum similarity for a company to be suggested
+// as a probable duplicate.
+const duplicateThreshold = 0.85
+
+// legalSuffixes are dropped before comparing names, so "Acme Ltda" and
+// "ACME S.A." both compare as "acme".
+var legalSuffixes = map[string]bool{
+	"ltda": true, "sa": true, "s": true, "a": true, "me": true, "mei": true, "epp": true,
+	"eireli": true, "inc": true, "llc": true, "ltd": true, "corp": true, "co": true,
+	"company": true, "gmbh": true, "plc": true, "cia": true,
+}
+
+// DuplicateMatch is an existing company that resembles a new one.
+type DuplicateMatch struct {
+	CompanyID  uuid.UUID
+	Name       string
+	MatchedOn  string // "name", "alias" or "domain"
+	Similarity float64
+}
+
+// companyIdentity is the subset of a company used for duplicate detection.
+type companyIdentity struct {
+	ID      uuid.UUID
+	Name    string
+	Domain  string
+	Aliases []string
+}
+
+// normalizeCompanyName folds case and accents, drops punctuation and legal
+// suffixes.
+func normalizeCompanyName(name string) string {
+	tokens := strings.Split(utils.Slugify(name), "-")
+	for len(tokens) > 1 && legalSuffixes[tokens[len(tokens)-1]] {
+		tokens = tokens[:len(tokens)-1]
+	}
+	return strings.Join(tokens, " ")
+}
+
+// normalizeDomain reduces a domain or URL to its bare lowercase host.
+func normalizeDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if domain == "" {
+		return ""
+	}
+	if strings.Contains(domain, "://") {
+		if u, err := url.Parse(domain); err == nil {
+			domain = u.Host
+		}
+	}
+	domain = strings.SplitN(domain, "/", 2)[0]
+	return strings.TrimPrefix(domain, "www.")
+}
+
+// nameSimilarity returns 1 - normalized Levenshtein distance of the two
+// normalized names, ignoring spaces.
+func nameSimilarity(a, b string) float64 {
+	a = strings.ReplaceAll(a, " ", "")
+	b = strings.ReplaceAll(b, " ", "")
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// duplicateIndex holds the known companies with their names, aliases and
+// domains already normalized, so bulk imports normalize the company table
+// once instead of once per row.
+type duplicateIndex struct {
+	entries []indexedIdentity
+}
+
+type indexedIdentity struct {
+	id      uuid.UUID
+	name    string // as stored, for display
+	norm    string
+	domain  string
+	aliases []string
+}
+
+func newDuplicateIndex(known []companyIdentity) *duplicateIndex {
+	index := &duplicateIndex{entries: make([]indexedIdentity, 0, len(known))}
+	for _, k := range known {
+		index.add(k)
+	}
+	return index
+}
+
+// add indexes a company, e.g. one created earlier in the same import.
+func (x *duplicateIndex) add(k companyIdentity) {
+	entry := indexedIdentity{id: k.ID, name: k.Name, norm: normalizeCompanyName(k.Name), domain: normalizeDomain(k.Domain)}
+	for _, alias := range k.Aliases {
+		entry.aliases = append(entry.aliases, normalizeCompanyName(alias))
+	}
+	x.entries = append(x.entries, entry)
+}
+
+// find scores every indexed company against a candidate name and domain and
+// returns those above the threshold, best first.
+func (x *duplicateIndex) find(name, domain string) []DuplicateMatch {
+	normName := normalizeCompanyName(name)
+	normDomain := normalizeDomain(domain)
+
+	var matches []DuplicateMatch
+	for _, k := range x.entries {
+		best := DuplicateMatch{CompanyID: k.id, Name: k.name}
+		consider := func(on string, score float64) {
+			if score > best.Similarity {
+				best.Similarity = score
+				best.MatchedOn = on
+			}
+		}
+		if normDomain != "" && normDomain == k.domain {
+			consider("domain", 1)
+		}
+		consider("name", nameSimilarity(normName, k.norm))
+		for _, alias := range k.aliases {
+			consider("alias", nameSimilarity(normName, alias))
+		}
+		if best.Similarity >= duplicateThreshold {
+			matches = append(matches, best)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Similarity > matches[j].Similarity })
+	return matches
+}
+
+// findDuplicates matches a candidate against known companies.
+func findDuplicates(name, domain string, known []companyIdentity) []DuplicateMatch {
+	return newDuplicateIndex(known).find(name, domain)
+}
+
+// identityOf extracts the fields duplicate detection compares.
+func identityOf(c models.Company) companyIdentity {
+	identity := companyIdentity{ID: c.ID, Name: c.Name, Domain: c.Domain}
+	for _, a := range c.Aliases {
+		identity.Aliases = append(identity.Aliases, a.Name)
+	}
+	return identity
+}
+
+// loadDuplicateIndex indexes every live company. It holds the whole table in
+// memory, which is fine for directory-sized tables.
+func loadDuplicateIndex(ctx context.Context, companies repository.CompanyRepository) (*duplicateIndex, error) {
+	list, err := companies.ListIdentities(ctx)
+	if err != nil {
+		return nil, err
+	}
+	known := make([]companyIdentity, 0, len(list))
+	for _, c := range list {
+		known = append(known, identityOf(c))
+	}
+	return newDuplicateIndex(known), nil
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeCompanyName(t *testing.T) {
+	require.Equal(t, "acme", normalizeCompanyName("Acme Ltda"))
+	require.Equal(t, "acme", normalizeCompanyName("ACME S.A."))
+	require.Equal(t, "padaria sao joao", normalizeCompanyName("Padaria São João ME"))
+	require.Equal(t, "", normalizeCompanyName("  "))
+}
+
+func TestFindDuplicates(t *testing.T) {
+	acme := companyIdentity{ID: uuid.New(), Name: "ACME", Domain: "acme.com.br"}
+	globex := companyIdentity{ID: uuid.New(), Name: "Globex Corporation", Domain: "globex.com", Aliases: []string{"Globex Brasil"}}
+	known := []companyIdentity{acme, globex}
+
+	matches := findDuplicates("Acme Ltda", "", known)
+	require.Len(t, matches, 1)
+	require.Equal(t, acme.ID, matches[0].CompanyID)
+	require.Equal(t, 1.0, matches[0].Similarity)
+
+	matches = findDuplicates("Something Else", "https://www.acme.com.br/contato", known)
+	require.Len(t, matches, 1)
+	require.Equal(t, "domain", matches[0].MatchedOn)
+
+	matches = findDuplicates("Globex Brazil", "", known)
+	require.Len(t, matches, 1)
+	require.Equal(t, "alias", matches[0].MatchedOn)
+
+	require.Empty(t, findDuplicates("Initech", "initech.com", known))
+}
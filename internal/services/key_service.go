@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"crowdreview/config"
+	"crowdreview/internal/models"
+	"crowdreview/internal/repository"
+	"crowdreview/pkg/logging"
+	"crowdreview/pkg/utils"
+)
+
+// keySyncInterval is how often instances rotate (when due) and reload keys.
+// It must stay well below the publish lead so every instance knows a key
+// before any instance signs with it.
+const keySyncInterval = 5 * time.Minute
+
+// KeyService manages the JWT signing keys shared by all API instances.
+type KeyService interface {
+	// KeySet is the live set used to sign and verify tokens.
+	KeySet() *utils.KeySet
+	// Sync rotates keys when due, prunes expired keys and reloads the set.
+	Sync(ctx context.Context) error
+	// Rotate creates a new key now if rotation is due or force is set.
+	Rotate(ctx context.Context, force bool) (*models.SigningKey, error)
+	// Start runs Sync periodically until ctx is done.
+	Start(ctx context.Context)
+}
+
+type DefaultKeyService struct {
+	Keys   repository.SigningKeyRepository
+	Set    *utils.KeySet
+	Config config.Config
+}
+
+func (s *DefaultKeyService) KeySet() *utils.KeySet {
+	return s.Set
+}
+
+func (s *DefaultKeyService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(keySyncInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.Sync(ctx); err != nil {
+					slog.ErrorContext(ctx, "signing key sync failed", logging.Err(err))
+				}
+			}
+		}
+	}()
+}
+
+func (s *DefaultKeyService) Sync(ctx context.Context) error {
+	if _, err := s.Rotate(ctx, false); err != nil {
+		return err
+	}
+	now := time.Now()
+	if _, err := s.Keys.DeleteExpired(ctx, now); err != nil {
+		return err
+	}
+	stored, err := s.Keys.List(ctx, now)
+	if err != nil {
+		return err
+	}
+	keys := make([]utils.SigningKey, 0, len(stored))
+	for _, k := range stored {
+		private, err := utils.DecodePrivateKey(k.PrivateKeyPEM)
+		if err != nil {
+			slog.WarnContext(ctx, "skipping unreadable signing key", slog.String("kid", k.Kid), logging.Err(err))
+			continue
+		}
+		keys = append(keys, utils.SigningKey{
+			ID:          k.Kid,
+			Algorithm:   k.Algorithm,
+			Private:     private,
+			ActivatesAt: k.ActivatesAt,
+			ExpiresAt:   k.ExpiresAt,
+		})
+	}
+	s.Set.Replace(keys)
+	return nil
+}
+
+func (s *DefaultKeyService) Rotate(ctx context.Context, force bool) (*models.SigningKey, error) {
+	now := time.Now()
+	return s.Keys.Rotate(ctx, now, func(current []models.SigningKey) (*models.SigningKey, error) {
+		if !force && !s.rotationDue(current, now) {
+			return nil, nil
+		}
+		return s.newKey(current, now)
+	})
+}
+
+// rotationDue reports whether the newest key (current is newest first) is
+// close enough to the end of its term that its successor should be published.
+func (s *DefaultKeyService) rotationDue(current []models.SigningKey, now time.Time) bool {
+	if !s.hasUsableKey(current, now) {
+		return true
+	}
+	latest := current[0]
+	if latest.ActivatesAt.After(now) {
+		return false // successor already published
+	}
+	return !now.Before(latest.ActivatesAt.Add(s.Config.KeyRotation - s.Config.KeyPublishLead))
+}
+
+// hasUsableKey reports whether some active key will still verify the
+// longest-lived token it could sign now.
+func (s *DefaultKeyService) hasUsableKey(current []models.SigningKey, now time.Time) bool {
+	for _, k := range current {
+		if !k.ActivatesAt.After(now) && k.ExpiresAt.After(now.Add(s.Config.RefreshTTL)) {
+			return true
+		}
+	}
+	return false
+}
+
+// newKey generates a key that activates after the publish lead, or at once
+// when no current key can sign.
+func (s *DefaultKeyService) newKey(current []models.SigningKey, now time.Time) (*models.SigningKey, error) {
+	generated, err := utils.GenerateSigningKey(s.Config.JWTAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := utils.EncodePrivateKey(generated.Private)
+	if err != nil {
+		return nil, err
+	}
+	activates := now.Add(s.Config.KeyPublishLead)
+	if !s.hasUsableKey(current, now) {
+		activates = now
+	}
+	// A key signs for about one rotation term; its tokens live at most
+	// RefreshTTL after that. The publish lead covers late rotations.
+	expires := activates.Add(s.Config.KeyRotation + s.Config.KeyPublishLead + s.Config.RefreshTTL)
+	return &models.SigningKey{
+		Kid:           generated.ID,
+		Algorithm:     generated.Algorithm,
+		PrivateKeyPEM: encoded,
+		ActivatesAt:   activates,
+		ExpiresAt:     expires,
+	}, nil
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"crowdreview/config"
+	"crowdreview/internal/models"
+	"crowdreview/pkg/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyRotationSchedule(t *testing.T) {
+	cfg := config.Config{
+		JWTAlgorithm:   utils.AlgEdDSA,
+		RefreshTTL:     24 * time.Hour,
+		KeyRotation:    30 * 24 * time.Hour,
+		KeyPublishLead: time.Hour,
+	}
+	s := &DefaultKeyService{Config: cfg}
+	now := time.Now()
+
+	// No keys: the first one signs immediately.
+	require.True(t, s.rotationDue(nil, now))
+	first, err := s.newKey(nil, now)
+	require.NoError(t, err)
+	require.Equal(t, now, first.ActivatesAt)
+	current := []models.SigningKey{*first}
+
+	require.False(t, s.rotationDue(current, now.Add(cfg.KeyRotation-2*cfg.KeyPublishLead)))
+
+	// Near the end of its term the successor is published ahead of activation.
+	later := now.Add(cfg.KeyRotation - cfg.KeyPublishLead)
+	require.True(t, s.rotationDue(current, later))
+	next, err := s.newKey(current, later)
+	require.NoError(t, err)
+	require.Equal(t, later.Add(cfg.KeyPublishLead), next.ActivatesAt)
+	require.False(t, s.rotationDue([]models.SigningKey{*next, *first}, later))
+}
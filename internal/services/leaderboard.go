@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"crowdreview/internal/models"
+	"crowdreview/internal/repository"
+	"crowdreview/internal/reputation"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Leaderboard scopes.
+const (
+	LeaderboardGlobal   = "global"
+	LeaderboardIndustry = "industry"
+	LeaderboardMonth    = "month"
+)
+
+const (
+	defaultLeaderboardLimit = 20
+	maxLeaderboardLimit     = 100
+	leaderboardMonthLayout  = "2006-01"
+	// leaderboardMonthTTL keeps a bit over a year of monthly boards.
+	leaderboardMonthTTL = 400 * 24 * time.Hour
+)
+
+var (
+	// ErrInvalidLeaderboard wraps invalid scope, industry or month queries.
+	ErrInvalidLeaderboard = errors.New("invalid leaderboard")
+	// ErrLeaderboardUnavailable is returned when Redis is not configured.
+	ErrLeaderboardUnavailable = errors.New("leaderboards are unavailable")
+)
+
+// LeaderboardInput selects a leaderboard page. Industry is a slug and is
+// required for the industry scope; Month ("2006-01") defaults to the
+// current month.
+type LeaderboardInput struct {
+	Scope    string
+	Industry string
+	Month    string
+	Limit    int
+	Offset   int
+}
+
+// LeaderboardEntry is a ranked user. Score is the points of the board's
+// scope; Tier derives from the user's overall score.
+type LeaderboardEntry struct {
+	Rank  int64
+	User  models.User
+	Score int
+	Tier  reputation.Tier
+}
+
+// LeaderboardPage is a page of a leaderboard.
+type LeaderboardPage struct {
+	Scope   string
+	Key     string // industry slug or month, when scoped
+	Total   int64
+	Entries []LeaderboardEntry
+}
+
+// LeaderboardService ranks users by points in Redis sorted sets: overall,
+// per industry and per month.
+type LeaderboardService interface {
+	Page(ctx context.Context, input LeaderboardInput) (LeaderboardPage, error)
+	// Record updates the boards after a user earned points (negative to take
+	// them back). total is the user's new overall score and industry the slug
+	// the points are attributed to, if any.
+	Record(ctx context.Context, userID uuid.UUID, total, points int, industry string, at time.Time) error
+	// Remove drops the user from every board.
+	Remove(ctx context.Context, userID uuid.UUID) error
+	// Tier is the reputation tier of an overall score.
+	Tier(score int) reputation.Tier
+}
+
+// DefaultLeaderboardService keeps the boards in Redis; without Redis
+// Record and Remove do nothing and Page fails.
+type DefaultLeaderboardService struct {
+	Redis *redis.Client
+	Users repository.UserRepository
+	Tiers reputation.Tiers
+}
+
+func leaderboardGlobalKey() string { return "leaderboard:global" }
+
+func leaderboardIndustryKey(slug string) string { return "leaderboard:industry:" + slug }
+
+func leaderboardMonthKey(month string) string { return "leaderboard:month:" + month }
+
+func (s *DefaultLeaderboardService) Tier(score int) reputation.Tier {
+	return s.Tiers.For(score)
+}
+
+func (s *DefaultLeaderboardService) Record(ctx context.Context, userID uuid.UUID, total, points int, industry string, at time.Time) error {
+	if s.Redis == nil {
+		return nil
+	}
+	member := userID.String()
+	month := leaderboardMonthKey(at.UTC().Format(leaderboardMonthLayout))
+	pipe := s.Redis.TxPipeline()
+	pipe.ZAdd(ctx, leaderboardGlobalKey(), redis.Z{Score: float64(total), Member: member})
+	if points != 0 {
+		pipe.ZIncrBy(ctx, month, float64(points), member)
+		pipe.Expire(ctx, month, leaderboardMonthTTL)
+		if industry != "" {
+			pipe.ZIncrBy(ctx, leaderboardIndustryKey(industry), float64(points), member)
+		}
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (s *DefaultLeaderboardService) Remove(ctx context.Context, userID uuid.UUID) error {
+	if s.Redis == nil {
+		return nil
+	}
+	iter := s.Redis.Scan(ctx, 0, "leaderboard:*", 100).Iterator()
+	for iter.Next(ctx) {
+		if err := s.Redis.ZRem(ctx, iter.Val(), userID.String()).Err(); err != nil {
+			return err
+		}
+	}
+	return iter.Err()
+}
+
+func (s *DefaultLeaderboardService) Page(ctx context.Context, input LeaderboardInput) (LeaderboardPage, error) {
+	if s.Redis == nil {
+		return LeaderboardPage{}, ErrLeaderboardUnavailable
+	}
+	page, key, err := leaderboardKey(input, time.Now())
+	if err != nil {
+		return LeaderboardPage{}, err
+	}
+	limit, offset := input.Limit, input.Offset
+	if limit <= 0 {
+		limit = defaultLeaderboardLimit
+	}
+	if limit > maxLeaderboardLimit {
+		limit = maxLeaderboardLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	pipe := s.Redis.Pipeline()
+	total := pipe.ZCard(ctx, key)
+	ranked := pipe.ZRevRangeWithScores(ctx, key, int64(offset), int64(offset+limit-1))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return LeaderboardPage{}, err
+	}
+	page.Total = total.Val()
+
+	ids := make([]uuid.UUID, 0, len(ranked.Val()))
+	for _, z := range ranked.Val() {
+		if id, err := uuid.Parse(fmt.Sprint(z.Member)); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	users, err := s.Users.ListByIDs(ctx, ids)
+	if err != nil {
+		return LeaderboardPage{}, err
+	}
+	byID := make(map[uuid.UUID]models.User, len(users))
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+	page.Entries = make([]LeaderboardEntry, 0, len(ranked.Val()))
+	for i, z := range ranked.Val() {
+		id, _ := uuid.Parse(fmt.Sprint(z.Member))
+		user, ok := byID[id]
+		if !ok {
+			// Deleted since it was ranked.
+			continue
+		}
+		page.Entries = append(page.Entries, LeaderboardEntry{
+			Rank:  int64(offset + i + 1),
+			User:  user,
+			Score: int(z.Score),
+			Tier:  s.Tiers.For(user.GamificationScore),
+		})
+	}
+	return page, nil
+}
+
+// leaderboardKey validates input and returns the page header and Redis key
+// of the board it selects.
+func leaderboardKey(input LeaderboardInput, now time.Time) (LeaderboardPage, string, error) {
+	switch scope := strings.ToLower(strings.TrimSpace(input.Scope)); scope {
+	case "", LeaderboardGlobal:
+		return LeaderboardPage{Scope: LeaderboardGlobal}, leaderboardGlobalKey(), nil
+	case LeaderboardIndustry:
+		slug := strings.TrimSpace(input.Industry)
+		if slug == "" {
+			return LeaderboardPage{}, "", fmt.Errorf("%w: industry is required", ErrInvalidLeaderboard)
+		}
+		return LeaderboardPage{Scope: scope, Key: slug}, leaderboardIndustryKey(slug), nil
+	case LeaderboardMonth:
+		month := strings.TrimSpace(input.Month)
+		if month == "" {
+			month = now.UTC().Format(leaderboardMonthLayout)
+		}
+		if _, err := time.Parse(leaderboardMonthLayout, month); err != nil {
+			return LeaderboardPage{}, "", fmt.Errorf("%w: month must be YYYY-MM", ErrInvalidLeaderboard)
+		}
+		return LeaderboardPage{Scope: scope, Key: month}, leaderboardMonthKey(month), nil
+	default:
+		return LeaderboardPage{}, "", fmt.Errorf("%w: unknown scope %q", ErrInvalidLeaderboard, input.Scope)
+	}
+}
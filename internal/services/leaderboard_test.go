@@ -0,0 +1,35 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeaderboardKey(t *testing.T) {
+	now := time.Date(2026, 3, 31, 23, 0, 0, 0, time.UTC)
+
+	page, key, err := leaderboardKey(LeaderboardInput{}, now)
+	require.NoError(t, err)
+	require.Equal(t, LeaderboardGlobal, page.Scope)
+	require.Equal(t, "leaderboard:global", key)
+
+	page, key, err = leaderboardKey(LeaderboardInput{Scope: "month"}, now)
+	require.NoError(t, err)
+	require.Equal(t, "2026-03", page.Key)
+	require.Equal(t, "leaderboard:month:2026-03", key)
+
+	_, key, err = leaderboardKey(LeaderboardInput{Scope: "industry", Industry: "varejo"}, now)
+	require.NoError(t, err)
+	require.Equal(t, "leaderboard:industry:varejo", key)
+
+	for _, bad := range []LeaderboardInput{
+		{Scope: "industry"},
+		{Scope: "month", Month: "03/2026"},
+		{Scope: "weekly"},
+	} {
+		_, _, err := leaderboardKey(bad, now)
+		require.ErrorIs(t, err, ErrInvalidLeaderboard)
+	}
+}
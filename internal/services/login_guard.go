@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"crowdreview/config"
+	"crowdreview/internal/activity"
+	"crowdreview/internal/models"
+	"crowdreview/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/datatypes"
+)
+
+const (
+	// loginFreeAttempts is how many consecutive failures an account gets
+	// before each further attempt must wait.
+	loginFreeAttempts = 3
+	loginMaxDelay     = time.Minute
+)
+
+// LoginBlockedError rejects a login attempt before the password is checked.
+type LoginBlockedError struct {
+	Locked     bool // temporary lockout rather than a progressive delay
+	RetryAfter time.Duration
+}
+
+func (e *LoginBlockedError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("too many failed logins; try again in %s", e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("wait %s before trying again", e.RetryAfter.Round(time.Second))
+}
+
+// LoginGuard counts failed logins per account and per IP in Redis, imposes
+// progressive delays and temporary lockouts, and records security events.
+// Without Redis only the events are recorded. A nil guard does nothing.
+// Lockouts are also raised as incidents on the admin activity stream.
+type LoginGuard struct {
+	Redis    *redis.Client
+	Events   repository.SecurityEventRepository
+	Activity ActivityService // optional
+	Config   config.Config
+}
+
+func loginAccountKey(kind, email string) string { return "login:" + kind + ":acct:" + email }
+
+func loginIPKey(kind, ip string) string { return "login:" + kind + ":ip:" + ip }
+
+// loginDelay is the wait imposed after the given number of consecutive
+// failures: none for the first few, then doubling from one second.
+func loginDelay(failures int64) time.Duration {
+	extra := failures - loginFreeAttempts
+	if extra <= 0 {
+		return 0
+	}
+	if extra > 7 {
+		return loginMaxDelay
+	}
+	delay := time.Second << (extra - 1)
+	if delay > loginMaxDelay {
+		return loginMaxDelay
+	}
+	return delay
+}
+
+// Check returns a *LoginBlockedError while the account or IP is locked out
+// or the account's progressive delay has not elapsed.
+func (g *LoginGuard) Check(ctx context.Context, email, ip string) error {
+	if g == nil || g.Redis == nil {
+		return nil
+	}
+	email = normalizeEmail(email)
+	pipe := g.Redis.Pipeline()
+	accountLock := pipe.PTTL(ctx, loginAccountKey("lock", email))
+	ipLock := pipe.PTTL(ctx, loginIPKey("lock", ip))
+	wait := pipe.PTTL(ctx, loginAccountKey("wait", email))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	for _, lock := range []time.Duration{accountLock.Val(), ipLock.Val()} {
+		if lock > 0 {
+			return &LoginBlockedError{Locked: true, RetryAfter: lock}
+		}
+	}
+	if d := wait.Val(); d > 0 {
+		return &LoginBlockedError{RetryAfter: d}
+	}
+	return nil
+}
+
+// Fail records a failed attempt; userID is nil when the email is unknown.
+func (g *LoginGuard) Fail(ctx context.Context, email, ip string, userID *uuid.UUID) error {
+	if g == nil {
+		return nil
+	}
+	email = normalizeEmail(email)
+	if err := g.record(ctx, models.SecurityLoginFailed, userID, email, ip, nil); err != nil {
+		return err
+	}
+	if g.Redis == nil {
+		return nil
+	}
+
+	window := g.Config.LoginFailureWindow
+	pipe := g.Redis.TxPipeline()
+	accountFails := pipe.Incr(ctx, loginAccountKey("fails", email))
+	pipe.Expire(ctx, loginAccountKey("fails", email), window)
+	ipFails := pipe.Incr(ctx, loginIPKey("fails", ip))
+	pipe.Expire(ctx, loginIPKey("fails", ip), window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	if delay := loginDelay(accountFails.Val()); delay > 0 {
+		if err := g.Redis.Set(ctx, loginAccountKey("wait", email), 1, delay).Err(); err != nil {
+			return err
+		}
+	}
+	if accountFails.Val() >= int64(g.Config.LoginMaxAccountFailures) {
+		if err := g.lock(ctx, loginAccountKey("lock", email), loginAccountKey("fails", email)); err != nil {
+			return err
+		}
+		meta := datatypes.JSONMap{"failures": accountFails.Val(), "minutes": g.Config.LoginLockout.Minutes()}
+		if err := g.record(ctx, models.SecurityAccountLocked, userID, email, ip, meta); err != nil {
+			return err
+		}
+	}
+	if ipFails.Val() >= int64(g.Config.LoginMaxIPFailures) {
+		if err := g.lock(ctx, loginIPKey("lock", ip), loginIPKey("fails", ip)); err != nil {
+			return err
+		}
+		meta := datatypes.JSONMap{"failures": ipFails.Val(), "minutes": g.Config.LoginLockout.Minutes()}
+		if err := g.record(ctx, models.SecurityIPLocked, nil, "", ip, meta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Succeed clears the account's failure streak and records the first login
+// from a new IP.
+func (g *LoginGuard) Succeed(ctx context.Context, user *models.User, ip string) error {
+	if g == nil {
+		return nil
+	}
+	email := normalizeEmail(user.Email)
+	if g.Redis != nil {
+		if err := g.Redis.Del(ctx, loginAccountKey("fails", email), loginAccountKey("wait", email)).Err(); err != nil {
+			return err
+		}
+	}
+	known, err := g.Events.KnownIP(ctx, user.ID, ip)
+	if err != nil || known {
+		return err
+	}
+	return g.record(ctx, models.SecurityLoginNewIP, &user.ID, email, ip, nil)
+}
+
+func (g *LoginGuard) lock(ctx context.Context, lockKey, counterKey string) error {
+	pipe := g.Redis.TxPipeline()
+	pipe.Set(ctx, lockKey, 1, g.Config.LoginLockout)
+	pipe.Del(ctx, counterKey)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (g *LoginGuard) record(ctx context.Context, kind string, userID *uuid.UUID, email, ip string, meta datatypes.JSONMap) error {
+	if meta == nil {
+		meta = datatypes.JSONMap{}
+	}
+	event := &models.SecurityEvent{
+		Type:      kind,
+		UserID:    userID,
+		Email:     email,
+		IPAddress: ip,
+		Meta:      meta,
+	}
+	if err := g.Events.Record(ctx, event); err != nil {
+		return err
+	}
+	if g.Activity != nil && (kind == models.SecurityAccountLocked || kind == models.SecurityIPLocked) {
+		g.Activity.Publish(ctx, activity.Event{Type: activity.EventIncident, Data: map[string]interface{}{
+			"kind":              kind,
+			"security_event_id": event.ID,
+			"user_id":           userID,
+			"email":             email,
+			"ip":                ip,
+			"meta":              meta,
+		}})
+	}
+	return nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"crowdreview/config"
+	"crowdreview/internal/models"
+	"crowdreview/internal/repository"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSecurityEventRepo struct {
+	events []models.SecurityEvent
+}
+
+func (m *mockSecurityEventRepo) Record(ctx context.Context, event *models.SecurityEvent) error {
+	event.ID = uuid.New()
+	m.events = append(m.events, *event)
+	return nil
+}
+func (m *mockSecurityEventRepo) List(ctx context.Context, filter repository.SecurityEventFilter) ([]models.SecurityEvent, int64, error) {
+	return m.events, int64(len(m.events)), nil
+}
+func (m *mockSecurityEventRepo) KnownIP(ctx context.Context, userID uuid.UUID, ip string) (bool, error) {
+	return true, nil
+}
+func (m *mockSecurityEventRepo) count(kind string) int {
+	n := 0
+	for _, e := range m.events {
+		if e.Type == kind {
+			n++
+		}
+	}
+	return n
+}
+
+func newTestLoginGuard(t *testing.T) (*LoginGuard, *miniredis.Miniredis, *mockSecurityEventRepo) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	events := &mockSecurityEventRepo{}
+	guard := &LoginGuard{
+		Redis:  redis.NewClient(&redis.Options{Addr: server.Addr()}),
+		Events: events,
+		Config: config.Config{
+			LoginMaxAccountFailures: 5,
+			LoginMaxIPFailures:      8,
+			LoginFailureWindow:      15 * time.Minute,
+			LoginLockout:            15 * time.Minute,
+		},
+	}
+	return guard, server, events
+}
+
+func requireBlocked(t *testing.T, err error, locked bool) {
+	t.Helper()
+	var blocked *LoginBlockedError
+	require.True(t, errors.As(err, &blocked), "expected a blocked login, got %v", err)
+	require.Equal(t, locked, blocked.Locked)
+	require.Positive(t, blocked.RetryAfter)
+}
+
+func TestLoginDelay(t *testing.T) {
+	require.Zero(t, loginDelay(1))
+	require.Zero(t, loginDelay(loginFreeAttempts))
+	require.Equal(t, time.Second, loginDelay(loginFreeAttempts+1))
+	require.Equal(t, 4*time.Second, loginDelay(loginFreeAttempts+3))
+	require.Equal(t, loginMaxDelay, loginDelay(loginFreeAttempts+20))
+	require.Equal(t, loginMaxDelay, loginDelay(1000))
+}
+
+func TestNilLoginGuardAllowsEverything(t *testing.T) {
+	var guard *LoginGuard
+	require.NoError(t, guard.Check(context.Background(), "a@b.com", "127.0.0.1"))
+	require.NoError(t, guard.Fail(context.Background(), "a@b.com", "127.0.0.1", nil))
+}
+
+func TestLoginGuardLocksAccountAfterMaxFailures(t *testing.T) {
+	ctx := context.Background()
+	guard, server, events := newTestLoginGuard(t)
+	const email, ip = "Ana@Example.com", "203.0.113.7"
+
+	for i := 0; i < loginFreeAttempts; i++ {
+		require.NoError(t, guard.Check(ctx, email, ip))
+		require.NoError(t, guard.Fail(ctx, email, ip, nil))
+	}
+	require.NoError(t, guard.Check(ctx, email, ip), "the first failures are free")
+
+	require.NoError(t, guard.Fail(ctx, email, ip, nil))
+	requireBlocked(t, guard.Check(ctx, "ana@example.com", ip), false)
+
+	server.FastForward(loginMaxDelay)
+	require.NoError(t, guard.Fail(ctx, email, ip, nil))
+	requireBlocked(t, guard.Check(ctx, email, "198.51.100.1"), true)
+	require.Equal(t, 1, events.count(models.SecurityAccountLocked))
+	require.Equal(t, 5, events.count(models.SecurityLoginFailed))
+
+	server.FastForward(guard.Config.LoginLockout)
+	require.NoError(t, guard.Check(ctx, email, ip), "the lockout expires")
+}
+
+func TestLoginGuardLocksIPAcrossAccounts(t *testing.T) {
+	ctx := context.Background()
+	guard, _, events := newTestLoginGuard(t)
+	const ip = "203.0.113.9"
+
+	for i := 0; i < guard.Config.LoginMaxIPFailures; i++ {
+		require.NoError(t, guard.Fail(ctx, uuid.NewString()+"@example.com", ip, nil))
+	}
+	requireBlocked(t, guard.Check(ctx, "new@example.com", ip), true)
+	require.NoError(t, guard.Check(ctx, "new@example.com", "198.51.100.1"))
+	require.Equal(t, 1, events.count(models.SecurityIPLocked))
+}
+
+func TestLoginGuardSuccessResetsFailures(t *testing.T) {
+	ctx := context.Background()
+	guard, _, events := newTestLoginGuard(t)
+	user := &models.User{Email: "bia@example.com"}
+	user.ID = uuid.New()
+	const ip = "203.0.113.10"
+
+	for i := 0; i < guard.Config.LoginMaxAccountFailures-1; i++ {
+		require.NoError(t, guard.Fail(ctx, user.Email, ip, &user.ID))
+	}
+	requireBlocked(t, guard.Check(ctx, user.Email, ip), false)
+
+	require.NoError(t, guard.Succeed(ctx, user, ip))
+	require.NoError(t, guard.Check(ctx, user.Email, ip))
+
+	// The streak starts over, so one more failure neither delays nor locks.
+	require.NoError(t, guard.Fail(ctx, user.Email, ip, &user.ID))
+	require.NoError(t, guard.Check(ctx, user.Email, ip))
+	require.Zero(t, events.count(models.SecurityAccountLocked))
+}
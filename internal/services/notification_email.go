@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"crowdreview/internal/mail"
+	"crowdreview/internal/models"
+)
+
+// EmailDigestChannel mails a user's pending notifications as one message.
+type EmailDigestChannel struct {
+	Mailer      mail.Mailer
+	FrontendURL string
+}
+
+func (c *EmailDigestChannel) Name() string { return models.ChannelEmail }
+
+func (c *EmailDigestChannel) Deliver(ctx context.Context, user models.User, notifications []models.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	subject := "Você tem 1 nova notificação no CrowdReview"
+	if len(notifications) > 1 {
+		subject = fmt.Sprintf("Você tem %d novas notificações no CrowdReview", len(notifications))
+	}
+	var body strings.Builder
+	fmt.Fprintf(&body, "Olá, %s!\n\n", user.Username)
+	for _, n := range notifications {
+		fmt.Fprintf(&body, "- %s\n", n.Title)
+		if n.Body != "" {
+			fmt.Fprintf(&body, "  %s\n", n.Body)
+		}
+	}
+	fmt.Fprintf(&body, "\nVeja todas em %s\nPara mudar o que recebe por e-mail, ajuste suas preferências de notificação.",
+		strings.TrimRight(c.FrontendURL, "/")+"/notifications")
+	return c.Mailer.Send(ctx, mail.Message{To: user.Email, Subject: subject, Body: body.String()})
+}
@@ -0,0 +1,367 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"crowdreview/config"
+	"crowdreview/internal/models"
+	"crowdreview/internal/repository"
+	"crowdreview/pkg/logging"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultNotificationLimit = 20
+	maxNotificationLimit     = 100
+	// deliveryBatchSize caps how many deliveries one channel sends per run.
+	deliveryBatchSize = 500
+	// maxDeliveryAttempts stops retrying deliveries that keep failing.
+	maxDeliveryAttempts = 5
+	// deliveryLease hides claimed deliveries from other replicas while a
+	// batch is sent.
+	deliveryLease = 5 * time.Minute
+)
+
+// Notification errors.
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrInvalidPreferences   = errors.New("invalid notification preferences")
+)
+
+// NotificationChannel delivers notifications outside the in-app inbox.
+// Deliveries are batched per user, so a channel can send them as a digest.
+type NotificationChannel interface {
+	Name() string
+	Deliver(ctx context.Context, user models.User, notifications []models.Notification) error
+}
+
+// NotificationInput is a notification addressed to one user. Sending a
+// second notification with the same EventID does nothing.
+type NotificationInput struct {
+	Type    string
+	Title   string
+	Body    string
+	Data    map[string]interface{}
+	EventID *uuid.UUID
+}
+
+// Inbox is a page of a user's in-app notifications.
+type Inbox struct {
+	Total         int64
+	Unread        int64
+	Notifications []models.Notification
+}
+
+// NotificationService keeps users informed about their reviews and
+// achievements through the inbox and the registered channels.
+type NotificationService interface {
+	// Notify routes the notification to the channels the user enabled for
+	// its type; nothing is stored if every channel is off.
+	Notify(ctx context.Context, userID uuid.UUID, input NotificationInput) error
+	Inbox(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit, offset int) (Inbox, error)
+	MarkRead(ctx context.Context, userID, id uuid.UUID, read bool) error
+	MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error)
+	Preferences(ctx context.Context, userID uuid.UUID) (models.NotificationPrefs, error)
+	// UpdatePreferences merges changes into the user's preferences.
+	UpdatePreferences(ctx context.Context, userID uuid.UUID, changes models.NotificationPrefs) (models.NotificationPrefs, error)
+	// ReviewStatusChanged tells the author about the moderation decision of
+	// an outbox event, once per event.
+	ReviewStatusChanged(ctx context.Context, eventID, reviewID uuid.UUID, status string) error
+	// AchievementsEarned tells the user about newly granted achievements,
+	// logging failures instead of returning them.
+	AchievementsEarned(ctx context.Context, userID uuid.UUID, earned []models.Achievement)
+	// Deliver sends pending deliveries through every channel, returning how
+	// many were sent.
+	Deliver(ctx context.Context) (int, error)
+	// Start runs Deliver every NOTIFICATION_DIGEST_HOURS until ctx is done.
+	Start(ctx context.Context)
+}
+
+type DefaultNotificationService struct {
+	Notifications repository.NotificationRepository
+	Users         repository.UserRepository
+	Reviews       repository.ReviewRepository
+	Channels      []NotificationChannel
+	Config        config.Config
+}
+
+func (s *DefaultNotificationService) Notify(ctx context.Context, userID uuid.UUID, input NotificationInput) error {
+	user, err := s.Users.GetByID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+	prefs := user.NotificationPrefs()
+	var channels []string
+	for _, c := range s.Channels {
+		if prefs.Enabled(input.Type, c.Name()) {
+			channels = append(channels, c.Name())
+		}
+	}
+	inApp := prefs.Enabled(input.Type, models.ChannelInApp)
+	if !inApp && len(channels) == 0 {
+		return nil
+	}
+	data := datatypes.JSONMap{}
+	for k, v := range input.Data {
+		data[k] = v
+	}
+	return s.Notifications.Create(ctx, &models.Notification{
+		UserID:  userID,
+		Type:    input.Type,
+		Title:   input.Title,
+		Body:    input.Body,
+		Data:    data,
+		EventID: input.EventID,
+		InApp:   inApp,
+	}, channels)
+}
+
+func (s *DefaultNotificationService) Inbox(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit, offset int) (Inbox, error) {
+	if limit <= 0 {
+		limit = defaultNotificationLimit
+	}
+	if limit > maxNotificationLimit {
+		limit = maxNotificationLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	notifications, total, err := s.Notifications.ListByUser(ctx, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return Inbox{}, err
+	}
+	unread, err := s.Notifications.CountUnread(ctx, userID)
+	if err != nil {
+		return Inbox{}, err
+	}
+	return Inbox{Total: total, Unread: unread, Notifications: notifications}, nil
+}
+
+func (s *DefaultNotificationService) MarkRead(ctx context.Context, userID, id uuid.UUID, read bool) error {
+	err := s.Notifications.SetRead(ctx, userID, id, read)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotificationNotFound
+	}
+	return err
+}
+
+func (s *DefaultNotificationService) MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return s.Notifications.MarkAllRead(ctx, userID)
+}
+
+func (s *DefaultNotificationService) Preferences(ctx context.Context, userID uuid.UUID) (models.NotificationPrefs, error) {
+	user, err := s.Users.GetByID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.visible(user.NotificationPrefs()), nil
+}
+
+func (s *DefaultNotificationService) UpdatePreferences(ctx context.Context, userID uuid.UUID, changes models.NotificationPrefs) (models.NotificationPrefs, error) {
+	user, err := s.Users.GetByID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	prefs := user.NotificationPrefs()
+	for t, channels := range changes {
+		if _, ok := prefs[t]; !ok {
+			return nil, fmt.Errorf("%w: unknown notification type %q", ErrInvalidPreferences, t)
+		}
+		for channel, enabled := range channels {
+			if !s.hasChannel(channel) {
+				return nil, fmt.Errorf("%w: unknown channel %q", ErrInvalidPreferences, channel)
+			}
+			prefs[t][channel] = enabled
+		}
+	}
+	user.SetNotificationPrefs(prefs)
+	if err := s.Users.UpdateProfile(ctx, user.ID, user.ProfileMeta); err != nil {
+		return nil, err
+	}
+	return s.visible(prefs), nil
+}
+
+// visible keeps only the known types and the registered channels.
+func (s *DefaultNotificationService) visible(prefs models.NotificationPrefs) models.NotificationPrefs {
+	out := models.NotificationPrefs{}
+	for _, t := range models.NotificationTypes {
+		out[t] = map[string]bool{models.ChannelInApp: prefs.Enabled(t, models.ChannelInApp)}
+		for _, c := range s.Channels {
+			out[t][c.Name()] = prefs.Enabled(t, c.Name())
+		}
+	}
+	return out
+}
+
+func (s *DefaultNotificationService) hasChannel(name string) bool {
+	if name == models.ChannelInApp {
+		return true
+	}
+	for _, c := range s.Channels {
+		if c.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *DefaultNotificationService) ReviewStatusChanged(ctx context.Context, eventID, reviewID uuid.UUID, status string) error {
+	review, err := s.Reviews.GetByID(ctx, reviewID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	input := NotificationInput{
+		Data: map[string]interface{}{
+			"review_id":  review.ID.String(),
+			"company_id": review.CompanyID.String(),
+		},
+		EventID: &eventID,
+	}
+	switch status {
+	case models.ReviewStatusApproved:
+		input.Type = models.NotificationReviewApproved
+		input.Title = fmt.Sprintf("Sua avaliação de %s foi publicada", review.Company.Name)
+		input.Body = "Ela já aparece na página da empresa."
+	case models.ReviewStatusFlagged:
+		input.Type = models.NotificationReviewFlagged
+		input.Title = fmt.Sprintf("Sua avaliação de %s está em análise", review.Company.Name)
+		input.Body = "Nossa moderação vai revisá-la antes da publicação."
+	case models.ReviewStatusRejected:
+		input.Type = models.NotificationReviewRejected
+		input.Title = fmt.Sprintf("Sua avaliação de %s foi recusada", review.Company.Name)
+		input.Body = "Ela não segue as regras da comunidade e não será publicada."
+	default:
+		return nil
+	}
+	err = s.Notify(ctx, review.UserID, input)
+	if errors.Is(err, ErrUserNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (s *DefaultNotificationService) AchievementsEarned(ctx context.Context, userID uuid.UUID, earned []models.Achievement) {
+	for _, a := range earned {
+		err := s.Notify(ctx, userID, NotificationInput{
+			Type:  models.NotificationAchievementEarned,
+			Title: fmt.Sprintf("Você conquistou %q", a.Name),
+			Body:  fmt.Sprintf("%s (+%d pontos)", a.Description, a.Points),
+			Data: map[string]interface{}{
+				"achievement_id": a.ID.String(),
+				"points":         a.Points,
+			},
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "achievement notification failed", slog.String("user_id", userID.String()), logging.Err(err))
+		}
+	}
+}
+
+func (s *DefaultNotificationService) Deliver(ctx context.Context) (int, error) {
+	sent := 0
+	for _, channel := range s.Channels {
+		n, err := s.deliver(ctx, channel)
+		sent += n
+		if err != nil {
+			return sent, fmt.Errorf("%s: %w", channel.Name(), err)
+		}
+	}
+	return sent, nil
+}
+
+// deliver claims and sends one batch of a channel's pending deliveries,
+// grouped by user. A failed user does not hold back the others.
+func (s *DefaultNotificationService) deliver(ctx context.Context, channel NotificationChannel) (int, error) {
+	pending, err := s.Notifications.Claim(ctx, channel.Name(), maxDeliveryAttempts, deliveryBatchSize, time.Now(), deliveryLease)
+	if err != nil || len(pending) == 0 {
+		return 0, err
+	}
+	var order []uuid.UUID
+	byUser := map[uuid.UUID][]models.NotificationDelivery{}
+	for _, d := range pending {
+		if _, ok := byUser[d.UserID]; !ok {
+			order = append(order, d.UserID)
+		}
+		byUser[d.UserID] = append(byUser[d.UserID], d)
+	}
+	users, err := s.Users.ListByIDs(ctx, order)
+	if err != nil {
+		return 0, err
+	}
+	found := make(map[uuid.UUID]models.User, len(users))
+	for _, u := range users {
+		found[u.ID] = u
+	}
+	sent := 0
+	for _, userID := range order {
+		deliveries := byUser[userID]
+		ids := make([]uuid.UUID, 0, len(deliveries))
+		notifications := make([]models.Notification, 0, len(deliveries))
+		for _, d := range deliveries {
+			ids = append(ids, d.ID)
+			notifications = append(notifications, d.Notification)
+		}
+		user, ok := found[userID]
+		if !ok {
+			// The account is gone; there is nobody to deliver to.
+			if err := s.Notifications.MarkSent(ctx, ids); err != nil {
+				return sent, err
+			}
+			continue
+		}
+		if err := channel.Deliver(ctx, user, notifications); err != nil {
+			slog.WarnContext(ctx, "notification delivery failed",
+				slog.String("channel", channel.Name()), slog.String("user_id", userID.String()), logging.Err(err))
+			if err := s.Notifications.MarkFailed(ctx, ids, err.Error()); err != nil {
+				return sent, err
+			}
+			continue
+		}
+		if err := s.Notifications.MarkSent(ctx, ids); err != nil {
+			return sent, err
+		}
+		sent += len(ids)
+	}
+	return sent, nil
+}
+
+func (s *DefaultNotificationService) Start(ctx context.Context) {
+	if s.Config.NotificationDigest <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(s.Config.NotificationDigest)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if n, err := s.Deliver(ctx); err != nil {
+				slog.ErrorContext(ctx, "notification delivery failed", logging.Err(err))
+			} else if n > 0 {
+				slog.InfoContext(ctx, "delivered notifications", slog.Int("count", n))
+			}
+		}
+	}()
+}
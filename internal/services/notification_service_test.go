@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"crowdreview/internal/models"
+	"crowdreview/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type mockNotificationRepo struct {
+	notifications []models.Notification
+	deliveries    []models.NotificationDelivery
+	failed        []uuid.UUID
+}
+
+func (m *mockNotificationRepo) Create(ctx context.Context, n *models.Notification, channels []string) error {
+	for _, existing := range m.notifications {
+		if n.EventID != nil && existing.EventID != nil && *existing.EventID == *n.EventID {
+			return nil
+		}
+	}
+	n.ID = uuid.New()
+	m.notifications = append(m.notifications, *n)
+	for _, channel := range channels {
+		m.deliveries = append(m.deliveries, models.NotificationDelivery{
+			Base:         models.Base{ID: uuid.New()},
+			Notification: *n, NotificationID: n.ID, UserID: n.UserID, Channel: channel,
+		})
+	}
+	return nil
+}
+func (m *mockNotificationRepo) ListByUser(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]models.Notification, int64, error) {
+	return m.notifications, int64(len(m.notifications)), nil
+}
+func (m *mockNotificationRepo) CountUnread(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return 0, nil
+}
+func (m *mockNotificationRepo) SetRead(ctx context.Context, userID, id uuid.UUID, read bool) error {
+	return gorm.ErrRecordNotFound
+}
+func (m *mockNotificationRepo) MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return 0, nil
+}
+func (m *mockNotificationRepo) Claim(ctx context.Context, channel string, maxAttempts, limit int, now time.Time, lease time.Duration) ([]models.NotificationDelivery, error) {
+	var pending []models.NotificationDelivery
+	for i := range m.deliveries {
+		d := &m.deliveries[i]
+		if d.Channel == channel && d.SentAt == nil && (d.ClaimedUntil == nil || !d.ClaimedUntil.After(now)) {
+			until := now.Add(lease)
+			d.ClaimedUntil = &until
+			pending = append(pending, *d)
+		}
+	}
+	return pending, nil
+}
+func (m *mockNotificationRepo) MarkSent(ctx context.Context, ids []uuid.UUID) error {
+	for _, id := range ids {
+		for i := range m.deliveries {
+			if m.deliveries[i].ID == id {
+				now := m.deliveries[i].CreatedAt
+				m.deliveries[i].SentAt = &now
+			}
+		}
+	}
+	return nil
+}
+func (m *mockNotificationRepo) MarkFailed(ctx context.Context, ids []uuid.UUID, reason string) error {
+	m.failed = append(m.failed, ids...)
+	for _, id := range ids {
+		for i := range m.deliveries {
+			if m.deliveries[i].ID == id {
+				m.deliveries[i].ClaimedUntil = nil
+			}
+		}
+	}
+	return nil
+}
+
+type mockReviewRepo struct {
+	repository.ReviewRepository
+	reviews map[uuid.UUID]*models.Review
+}
+
+func (m *mockReviewRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Review, error) {
+	if r, ok := m.reviews[id]; ok {
+		return r, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type fakeChannel struct {
+	fail      bool
+	delivered map[uuid.UUID]int
+}
+
+func (c *fakeChannel) Name() string { return models.ChannelEmail }
+func (c *fakeChannel) Deliver(ctx context.Context, user models.User, notifications []models.Notification) error {
+	if c.fail {
+		return errors.New("smtp down")
+	}
+	c.delivered[user.ID] += len(notifications)
+	return nil
+}
+
+func TestNotificationsFollowPreferences(t *testing.T) {
+	ctx := context.Background()
+	user := &models.User{Email: "a@b.com", Username: "ana"}
+	user.ID = uuid.New()
+	review := &models.Review{UserID: user.ID, Company: models.Company{Name: "Acme"}}
+	review.ID = uuid.New()
+	repo := &mockNotificationRepo{}
+	channel := &fakeChannel{delivered: map[uuid.UUID]int{}}
+	service := &DefaultNotificationService{
+		Notifications: repo,
+		Users:         &mockUserRepo{users: map[string]*models.User{user.Email: user}},
+		Reviews:       &mockReviewRepo{reviews: map[uuid.UUID]*models.Review{review.ID: review}},
+		Channels:      []NotificationChannel{channel},
+	}
+
+	// By default approvals only go to the inbox and flags are also emailed.
+	approved, flagged := uuid.New(), uuid.New()
+	require.NoError(t, service.ReviewStatusChanged(ctx, approved, review.ID, models.ReviewStatusApproved))
+	require.NoError(t, service.ReviewStatusChanged(ctx, flagged, review.ID, models.ReviewStatusFlagged))
+	require.NoError(t, service.ReviewStatusChanged(ctx, uuid.New(), review.ID, models.ReviewStatusPending))
+	require.NoError(t, service.ReviewStatusChanged(ctx, flagged, review.ID, models.ReviewStatusFlagged), "relayed twice")
+	require.Len(t, repo.notifications, 2)
+	require.Len(t, repo.deliveries, 1)
+	require.Equal(t, models.NotificationReviewFlagged, repo.deliveries[0].Notification.Type)
+	require.Contains(t, repo.notifications[0].Title, "Acme")
+
+	prefs, err := service.UpdatePreferences(ctx, user.ID, models.NotificationPrefs{
+		models.NotificationAchievementEarned: {models.ChannelInApp: false, models.ChannelEmail: true},
+	})
+	require.NoError(t, err)
+	require.False(t, prefs.Enabled(models.NotificationAchievementEarned, models.ChannelInApp))
+	require.True(t, prefs.Enabled(models.NotificationReviewRejected, models.ChannelEmail), "other types keep their defaults")
+
+	service.AchievementsEarned(ctx, user.ID, []models.Achievement{{Name: "Veterano", Points: 200}})
+	require.Len(t, repo.notifications, 3)
+	require.False(t, repo.notifications[2].InApp)
+	require.Len(t, repo.deliveries, 2)
+
+	_, err = service.UpdatePreferences(ctx, user.ID, models.NotificationPrefs{"unknown": {models.ChannelEmail: true}})
+	require.ErrorIs(t, err, ErrInvalidPreferences)
+	_, err = service.UpdatePreferences(ctx, user.ID, models.NotificationPrefs{models.NotificationReviewApproved: {"sms": true}})
+	require.ErrorIs(t, err, ErrInvalidPreferences)
+
+	require.ErrorIs(t, service.MarkRead(ctx, user.ID, uuid.New(), true), ErrNotificationNotFound)
+}
+
+func TestNotificationDigestDelivery(t *testing.T) {
+	ctx := context.Background()
+	user := &models.User{Email: "a@b.com", Username: "ana"}
+	user.ID = uuid.New()
+	repo := &mockNotificationRepo{}
+	channel := &fakeChannel{fail: true, delivered: map[uuid.UUID]int{}}
+	service := &DefaultNotificationService{
+		Notifications: repo,
+		Users:         &mockUserRepo{users: map[string]*models.User{user.Email: user}},
+		Channels:      []NotificationChannel{channel},
+	}
+	for i := 0; i < 2; i++ {
+		require.NoError(t, service.Notify(ctx, user.ID, NotificationInput{Type: models.NotificationReviewRejected, Title: "recusada"}))
+	}
+	// A deleted account's deliveries are dropped rather than retried.
+	require.NoError(t, repo.Create(ctx, &models.Notification{UserID: uuid.New()}, []string{models.ChannelEmail}))
+
+	n, err := service.Deliver(ctx)
+	require.NoError(t, err)
+	require.Zero(t, n)
+	require.Len(t, repo.failed, 2)
+
+	// Deliveries another replica is sending are not claimed again.
+	claimed, err := repo.Claim(ctx, models.ChannelEmail, maxDeliveryAttempts, deliveryBatchSize, time.Now(), deliveryLease)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	n, err = service.Deliver(ctx)
+	require.NoError(t, err)
+	require.Zero(t, n)
+	for i := range repo.deliveries {
+		repo.deliveries[i].ClaimedUntil = nil
+	}
+
+	channel.fail = false
+	n, err = service.Deliver(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, 2, channel.delivered[user.ID], "one digest per user")
+
+	n, err = service.Deliver(ctx)
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"crowdreview/config"
+	"crowdreview/internal/models"
+	"crowdreview/internal/repository"
+	"crowdreview/pkg/logging"
+)
+
+const (
+	// outboxBatchSize caps the events one relay run publishes.
+	outboxBatchSize = 100
+	// outboxLease is how long a claimed event is hidden from other relays
+	// while its handlers run.
+	outboxLease = time.Minute
+	// outboxHandlerTimeout bounds the handlers of one event.
+	outboxHandlerTimeout = 15 * time.Second
+	// outboxMaxBackoff caps the delay between attempts of a failing event.
+	outboxMaxBackoff = 10 * time.Minute
+	// outboxRetention is how long published events are kept for debugging.
+	outboxRetention     = 7 * 24 * time.Hour
+	outboxPurgeInterval = time.Hour
+	maxOutboxError      = 500
+)
+
+// OutboxHandler consumes a relayed event. Events are delivered at least
+// once, so handlers must be idempotent by event ID.
+type OutboxHandler func(ctx context.Context, event models.OutboxEvent) error
+
+// OutboxService relays the domain events recorded in the outbox to the
+// fraud queue, notifications and webhooks.
+type OutboxService interface {
+	// Subscribe adds a handler for events of the type. Subscribe before
+	// Start.
+	Subscribe(eventType string, handler OutboxHandler)
+	// Relay runs the handlers of the due events, returning how many were
+	// published. An event is published once all its handlers succeed;
+	// otherwise every handler sees it again after a backoff.
+	Relay(ctx context.Context) (int, error)
+	// Start runs Relay every OUTBOX_POLL_MS until ctx is done and purges
+	// old published events.
+	Start(ctx context.Context)
+}
+
+type DefaultOutboxService struct {
+	Outbox   repository.OutboxRepository
+	Handlers map[string][]OutboxHandler
+	Config   config.Config
+}
+
+func (s *DefaultOutboxService) Subscribe(eventType string, handler OutboxHandler) {
+	if s.Handlers == nil {
+		s.Handlers = map[string][]OutboxHandler{}
+	}
+	s.Handlers[eventType] = append(s.Handlers[eventType], handler)
+}
+
+func (s *DefaultOutboxService) Relay(ctx context.Context) (int, error) {
+	events, err := s.Outbox.Claim(ctx, time.Now(), outboxLease, outboxBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	published := 0
+	for _, event := range events {
+		if err := s.handle(ctx, event); err != nil {
+			slog.WarnContext(eventContext(ctx, event), "outbox event failed",
+				slog.Int("attempts", event.Attempts+1), logging.Err(err))
+			reason := err.Error()
+			if len(reason) > maxOutboxError {
+				reason = reason[:maxOutboxError]
+			}
+			next := time.Now().Add(outboxBackoff(event.Attempts + 1))
+			if err := s.Outbox.MarkFailed(ctx, event.ID, reason, next); err != nil {
+				return published, err
+			}
+			continue
+		}
+		if err := s.Outbox.MarkPublished(ctx, event.ID); err != nil {
+			return published, err
+		}
+		published++
+	}
+	return published, nil
+}
+
+// eventContext tags ctx with the event and the request that recorded it,
+// so the consumers' logs trace back to that request.
+func eventContext(ctx context.Context, event models.OutboxEvent) context.Context {
+	ctx = logging.WithRequestID(ctx, event.RequestID)
+	return logging.With(ctx,
+		slog.String("outbox_event_id", event.ID.String()),
+		slog.String("event_type", event.Type),
+		slog.String("review_id", event.AggregateID.String()))
+}
+
+// handle runs every handler of the event, so one failing consumer does
+// not hold the others back.
+func (s *DefaultOutboxService) handle(ctx context.Context, event models.OutboxEvent) error {
+	ctx, cancel := context.WithTimeout(eventContext(ctx, event), outboxHandlerTimeout)
+	defer cancel()
+	var errs []error
+	for _, handler := range s.Handlers[event.Type] {
+		if err := handler(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// outboxBackoff is the delay before the next attempt of an event that
+// failed attempts times: 1s doubling up to outboxMaxBackoff.
+func outboxBackoff(attempts int) time.Duration {
+	delay := time.Second
+	for i := 1; i < attempts && delay < outboxMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > outboxMaxBackoff {
+		delay = outboxMaxBackoff
+	}
+	return delay
+}
+
+func (s *DefaultOutboxService) Start(ctx context.Context) {
+	if s.Config.OutboxPollInterval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(s.Config.OutboxPollInterval)
+		defer ticker.Stop()
+		var purged time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if _, err := s.Relay(ctx); err != nil {
+				slog.ErrorContext(ctx, "outbox relay failed", logging.Err(err))
+			}
+			if time.Since(purged) < outboxPurgeInterval {
+				continue
+			}
+			purged = time.Now()
+			if n, err := s.Outbox.Purge(ctx, purged.Add(-outboxRetention)); err != nil {
+				slog.ErrorContext(ctx, "outbox purge failed", logging.Err(err))
+			} else if n > 0 {
+				slog.InfoContext(ctx, "purged published outbox events", slog.Int64("count", n))
+			}
+		}
+	}()
+}
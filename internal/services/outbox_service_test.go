@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"crowdreview/internal/models"
+	"crowdreview/pkg/logging"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type mockOutboxRepo struct {
+	events []models.OutboxEvent
+}
+
+func (m *mockOutboxRepo) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.OutboxEvent, error) {
+	var due []models.OutboxEvent
+	for i := range m.events {
+		e := &m.events[i]
+		if e.PublishedAt == nil && !e.NextAttemptAt.After(now) {
+			next := now.Add(lease)
+			e.NextAttemptAt = &next
+			due = append(due, *e)
+		}
+	}
+	return due, nil
+}
+func (m *mockOutboxRepo) MarkPublished(ctx context.Context, id uuid.UUID) error {
+	e := m.event(id)
+	now := time.Now()
+	e.PublishedAt, e.NextAttemptAt = &now, nil
+	return nil
+}
+func (m *mockOutboxRepo) MarkFailed(ctx context.Context, id uuid.UUID, reason string, next time.Time) error {
+	e := m.event(id)
+	e.Attempts++
+	e.LastError, e.NextAttemptAt = reason, &next
+	return nil
+}
+func (m *mockOutboxRepo) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+func (m *mockOutboxRepo) event(id uuid.UUID) *models.OutboxEvent {
+	for i := range m.events {
+		if m.events[i].ID == id {
+			return &m.events[i]
+		}
+	}
+	return nil
+}
+
+func outboxEvent(eventType string) models.OutboxEvent {
+	past := time.Now().Add(-time.Second)
+	e := models.OutboxEvent{Type: eventType, AggregateID: uuid.New(), NextAttemptAt: &past}
+	e.ID = uuid.New()
+	return e
+}
+
+func TestOutboxRelayRetriesUntilEveryHandlerSucceeds(t *testing.T) {
+	ctx := context.Background()
+	created, changed := outboxEvent(models.OutboxReviewCreated), outboxEvent(models.OutboxReviewStatusChanged)
+	repo := &mockOutboxRepo{events: []models.OutboxEvent{created, changed}}
+	service := &DefaultOutboxService{Outbox: repo}
+
+	seen := map[uuid.UUID]int{}
+	failing := true
+	service.Subscribe(models.OutboxReviewCreated, func(ctx context.Context, e models.OutboxEvent) error {
+		seen[e.ID]++
+		return nil
+	})
+	service.Subscribe(models.OutboxReviewCreated, func(ctx context.Context, e models.OutboxEvent) error {
+		if failing {
+			return errors.New("queue full")
+		}
+		return nil
+	})
+	service.Subscribe(models.OutboxReviewStatusChanged, func(ctx context.Context, e models.OutboxEvent) error {
+		seen[e.ID]++
+		return nil
+	})
+
+	published, err := service.Relay(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, published)
+	require.NotNil(t, repo.event(changed.ID).PublishedAt)
+	failed := repo.event(created.ID)
+	require.Nil(t, failed.PublishedAt)
+	require.Equal(t, 1, failed.Attempts)
+	require.Contains(t, failed.LastError, "queue full")
+	require.WithinDuration(t, time.Now().Add(outboxBackoff(1)), *failed.NextAttemptAt, time.Second)
+
+	// Not due yet.
+	published, err = service.Relay(ctx)
+	require.NoError(t, err)
+	require.Zero(t, published)
+
+	failing = false
+	past := time.Now().Add(-time.Second)
+	failed.NextAttemptAt = &past
+	published, err = service.Relay(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, published)
+	require.Equal(t, 2, seen[created.ID], "handlers see retried events again")
+	require.Equal(t, 1, seen[changed.ID])
+}
+
+func TestOutboxHandlersKeepTheRequestID(t *testing.T) {
+	event := outboxEvent(models.OutboxReviewCreated)
+	event.RequestID = "req-42"
+	service := &DefaultOutboxService{Outbox: &mockOutboxRepo{events: []models.OutboxEvent{event}}}
+	var requestID string
+	service.Subscribe(models.OutboxReviewCreated, func(ctx context.Context, e models.OutboxEvent) error {
+		requestID = logging.RequestID(ctx)
+		return nil
+	})
+
+	_, err := service.Relay(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "req-42", requestID)
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	require.Equal(t, time.Second, outboxBackoff(1))
+	require.Equal(t, 8*time.Second, outboxBackoff(4))
+	require.Equal(t, outboxMaxBackoff, outboxBackoff(30))
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"crowdreview/config"
+	"crowdreview/internal/models"
+	"crowdreview/internal/repository"
+	"crowdreview/pkg/logging"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// pointsDecayInterval is how often aged achievement points are decayed.
+const pointsDecayInterval = 24 * time.Hour
+
+const (
+	defaultPointsLimit = 50
+	maxPointsLimit     = 200
+)
+
+// PointsHistory is a page of a user's points ledger. Score is the stored
+// GamificationScore and Balance the ledger sum; they differ only if the
+// score needs recomputing.
+type PointsHistory struct {
+	UserID  uuid.UUID
+	Score   int
+	Balance int
+	Total   int64
+	Entries []models.PointsEntry
+}
+
+// PointsService audits and maintains the points ledger behind
+// User.GamificationScore.
+type PointsService interface {
+	History(ctx context.Context, userID uuid.UUID, limit, offset int) (PointsHistory, error)
+	// Recompute resets scores to their ledger sums for one user or (nil)
+	// everyone, returning how many were wrong.
+	Recompute(ctx context.Context, userID *uuid.UUID) (int64, error)
+	// Decay takes POINTS_DECAY_PERCENT of every achievement grant older than
+	// POINTS_DECAY_AFTER_DAYS back, once, returning how many users lost points.
+	Decay(ctx context.Context) (int, error)
+	// Start runs Decay now and then daily until ctx is done.
+	Start(ctx context.Context)
+}
+
+type DefaultPointsService struct {
+	Points      repository.PointsRepository
+	Users       repository.UserRepository
+	Leaderboard LeaderboardService // optional
+	Config      config.Config
+}
+
+func (s *DefaultPointsService) History(ctx context.Context, userID uuid.UUID, limit, offset int) (PointsHistory, error) {
+	user, err := s.Users.GetByID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return PointsHistory{}, ErrUserNotFound
+	}
+	if err != nil {
+		return PointsHistory{}, err
+	}
+	if limit <= 0 {
+		limit = defaultPointsLimit
+	}
+	if limit > maxPointsLimit {
+		limit = maxPointsLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	entries, total, err := s.Points.ListByUser(ctx, userID, limit, offset)
+	if err != nil {
+		return PointsHistory{}, err
+	}
+	balance, err := s.Points.Balance(ctx, userID)
+	if err != nil {
+		return PointsHistory{}, err
+	}
+	return PointsHistory{
+		UserID:  userID,
+		Score:   user.GamificationScore,
+		Balance: balance,
+		Total:   total,
+		Entries: entries,
+	}, nil
+}
+
+func (s *DefaultPointsService) Recompute(ctx context.Context, userID *uuid.UUID) (int64, error) {
+	changed, err := s.Points.Recompute(ctx, userID)
+	if err != nil || userID == nil {
+		return changed, err
+	}
+	s.syncLeaderboard(ctx, []uuid.UUID{*userID})
+	return changed, nil
+}
+
+func (s *DefaultPointsService) Decay(ctx context.Context) (int, error) {
+	if s.Config.PointsDecayPercent <= 0 {
+		return 0, nil
+	}
+	users, err := s.Points.Decay(ctx, time.Now().Add(-s.Config.PointsDecayAfter), s.Config.PointsDecayPercent)
+	if err != nil {
+		return 0, err
+	}
+	s.syncLeaderboard(ctx, users)
+	return len(users), nil
+}
+
+func (s *DefaultPointsService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(pointsDecayInterval)
+		defer ticker.Stop()
+		for {
+			if n, err := s.Decay(ctx); err != nil {
+				slog.ErrorContext(ctx, "points decay failed", logging.Err(err))
+			} else if n > 0 {
+				slog.InfoContext(ctx, "decayed aged points", slog.Int("users", n))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// syncLeaderboard re-ranks users on the global board after their score
+// changed without new points being earned.
+func (s *DefaultPointsService) syncLeaderboard(ctx context.Context, ids []uuid.UUID) {
+	if s.Leaderboard == nil || len(ids) == 0 {
+		return
+	}
+	users, err := s.Users.ListByIDs(ctx, ids)
+	if err != nil {
+		slog.ErrorContext(ctx, "leaderboard sync failed", logging.Err(err))
+		return
+	}
+	now := time.Now()
+	for _, u := range users {
+		if err := s.Leaderboard.Record(ctx, u.ID, u.GamificationScore, 0, "", now); err != nil {
+			slog.ErrorContext(ctx, "leaderboard sync failed", slog.String("user_id", u.ID.String()), logging.Err(err))
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"crowdreview/config"
+	"crowdreview/internal/models"
+	"crowdreview/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPointsRepo struct {
+	entries   []models.PointsEntry
+	clawbacks []uuid.UUID
+	decayedAt time.Time
+	percent   int
+}
+
+func (m *mockPointsRepo) ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]models.PointsEntry, int64, error) {
+	return m.entries, int64(len(m.entries)), nil
+}
+func (m *mockPointsRepo) Balance(ctx context.Context, userID uuid.UUID) (int, error) {
+	balance := 0
+	for _, e := range m.entries {
+		balance += e.Delta
+	}
+	return balance, nil
+}
+func (m *mockPointsRepo) Clawback(ctx context.Context, reviewID uuid.UUID) ([]repository.PointsReversal, error) {
+	m.clawbacks = append(m.clawbacks, reviewID)
+	return nil, nil
+}
+func (m *mockPointsRepo) Decay(ctx context.Context, before time.Time, percent int) ([]uuid.UUID, error) {
+	m.decayedAt, m.percent = before, percent
+	return []uuid.UUID{uuid.New()}, nil
+}
+func (m *mockPointsRepo) Recompute(ctx context.Context, userID *uuid.UUID) (int64, error) {
+	return 0, nil
+}
+
+func TestPointsServiceHistory(t *testing.T) {
+	ctx := context.Background()
+	user := &models.User{Email: "a@b.com", Username: "ana", GamificationScore: 40}
+	user.ID = uuid.New()
+	repo := &mockPointsRepo{entries: []models.PointsEntry{
+		{UserID: user.ID, Delta: 50, Reason: models.PointsAchievement},
+		{UserID: user.ID, Delta: -25, Reason: models.PointsDecay},
+	}}
+	service := &DefaultPointsService{Points: repo, Users: &mockUserRepo{users: map[string]*models.User{user.Email: user}}}
+
+	history, err := service.History(ctx, user.ID, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 40, history.Score)
+	require.Equal(t, 25, history.Balance)
+	require.Len(t, history.Entries, 2)
+
+	_, err = service.History(ctx, uuid.New(), 0, 0)
+	require.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestPointsServiceDecay(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockPointsRepo{}
+	service := &DefaultPointsService{Points: repo, Users: &mockUserRepo{}, Config: config.Config{PointsDecayAfter: 24 * time.Hour}}
+
+	n, err := service.Decay(ctx)
+	require.NoError(t, err)
+	require.Zero(t, n, "decay is disabled without a percentage")
+	require.True(t, repo.decayedAt.IsZero())
+
+	service.Config.PointsDecayPercent = 50
+	n, err = service.Decay(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, 50, repo.percent)
+	require.WithinDuration(t, time.Now().Add(-24*time.Hour), repo.decayedAt, time.Minute)
+}
+
+func TestRejectionClawsBackPoints(t *testing.T) {
+	points := &mockPointsRepo{}
+	service := &DefaultAchievementService{Achievements: &mockAchievementRepo{}, Points: points}
+	review := uuid.New()
+
+	service.ReviewStatusChanged(context.Background(), review, models.ReviewStatusFlagged)
+	require.Empty(t, points.clawbacks)
+	service.ReviewStatusChanged(context.Background(), review, models.ReviewStatusRejected)
+	require.Equal(t, []uuid.UUID{review}, points.clawbacks)
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"crowdreview/config"
+	"crowdreview/internal/repository"
+	"crowdreview/pkg/logging"
+	"crowdreview/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// piiScrubInterval is how often reviews past the retention period are scrubbed.
+const piiScrubInterval = 24 * time.Hour
+
+// ErrDeletionNotConfirmed is returned when the confirmation does not match
+// the account's username.
+var ErrDeletionNotConfirmed = errors.New("confirm the deletion with your username")
+
+// UserExport is everything stored about a user (LGPD art. 18, V).
+type UserExport repository.UserData
+
+// PrivacyService handles data subject requests and PII retention.
+type PrivacyService interface {
+	Export(ctx context.Context, userID uuid.UUID) (*UserExport, error)
+	// DeleteAccount erases the user's personal data and ends every session.
+	// Their reviews stay, anonymized, so rating aggregates do not change.
+	DeleteAccount(ctx context.Context, userID uuid.UUID, confirm string) error
+	// ScrubExpired clears IP, geolocation and metadata of reviews older than
+	// the retention period.
+	ScrubExpired(ctx context.Context) (int64, error)
+	// Start runs ScrubExpired now and then daily until ctx is done.
+	Start(ctx context.Context)
+}
+
+type DefaultPrivacyService struct {
+	Privacy     repository.PrivacyRepository
+	Users       repository.UserRepository
+	Denylist    *utils.TokenDenylist
+	Leaderboard LeaderboardService // optional
+	Config      config.Config
+}
+
+func (s *DefaultPrivacyService) Export(ctx context.Context, userID uuid.UUID) (*UserExport, error) {
+	data, err := s.Privacy.Export(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	export := UserExport(*data)
+	return &export, nil
+}
+
+func (s *DefaultPrivacyService) DeleteAccount(ctx context.Context, userID uuid.UUID, confirm string) error {
+	user, err := s.Users.GetByID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if confirm != user.Username {
+		return ErrDeletionNotConfirmed
+	}
+	if err := s.Privacy.Erase(ctx, userID); err != nil {
+		return err
+	}
+	if s.Leaderboard != nil {
+		if err := s.Leaderboard.Remove(ctx, userID); err != nil {
+			slog.ErrorContext(ctx, "failed to remove user from leaderboards", slog.String("user_id", userID.String()), logging.Err(err))
+		}
+	}
+	// Refresh tokens were deleted with the account; outstanding access
+	// tokens are revoked until they expire.
+	return s.Denylist.RevokeUser(ctx, userID, s.Config.TokenTTL)
+}
+
+func (s *DefaultPrivacyService) ScrubExpired(ctx context.Context) (int64, error) {
+	return s.Privacy.ScrubReviews(ctx, time.Now().Add(-s.Config.PIIRetention))
+}
+
+func (s *DefaultPrivacyService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(piiScrubInterval)
+		defer ticker.Stop()
+		for {
+			if n, err := s.ScrubExpired(ctx); err != nil {
+				slog.ErrorContext(ctx, "review PII scrub failed", logging.Err(err))
+			} else if n > 0 {
+				slog.InfoContext(ctx, "scrubbed review PII", slog.Int64("count", n), slog.Duration("retention", s.Config.PIIRetention))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
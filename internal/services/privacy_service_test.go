@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"crowdreview/config"
+	"crowdreview/internal/models"
+	"crowdreview/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPrivacyRepo struct {
+	erased []uuid.UUID
+	cutoff time.Time
+}
+
+func (m *mockPrivacyRepo) Export(ctx context.Context, userID uuid.UUID) (*repository.UserData, error) {
+	return &repository.UserData{}, nil
+}
+func (m *mockPrivacyRepo) Erase(ctx context.Context, userID uuid.UUID) error {
+	m.erased = append(m.erased, userID)
+	return nil
+}
+func (m *mockPrivacyRepo) ScrubReviews(ctx context.Context, before time.Time) (int64, error) {
+	m.cutoff = before
+	return 0, nil
+}
+
+func TestPrivacyServiceDeleteAccount(t *testing.T) {
+	ctx := context.Background()
+	user := &models.User{Email: "a@b.com", Username: "ana"}
+	user.ID = uuid.New()
+	repo := &mockPrivacyRepo{}
+	service := &DefaultPrivacyService{
+		Privacy: repo,
+		Users:   &mockUserRepo{users: map[string]*models.User{user.Email: user}},
+		Config:  config.Config{PIIRetention: 30 * 24 * time.Hour},
+	}
+
+	require.ErrorIs(t, service.DeleteAccount(ctx, user.ID, "someone"), ErrDeletionNotConfirmed)
+	require.Empty(t, repo.erased)
+	require.NoError(t, service.DeleteAccount(ctx, user.ID, "ana"))
+	require.Equal(t, []uuid.UUID{user.ID}, repo.erased)
+
+	_, err := service.ScrubExpired(ctx)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(-30*24*time.Hour), repo.cutoff, time.Minute)
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"crowdreview/internal/models"
+	"crowdreview/internal/repository"
+	"crowdreview/internal/reputation"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/language"
+	"gorm.io/gorm"
+)
+
+const (
+	maxDisplayName       = 50
+	maxBio               = 500
+	maxAvatarURL         = 2048
+	publicProfileReviews = 50
+)
+
+// ErrInvalidProfile wraps profile validation failures.
+var ErrInvalidProfile = errors.New("invalid profile")
+
+// ProfileService manages the caller's profile and public reviewer pages.
+type ProfileService interface {
+	Me(ctx context.Context, userID uuid.UUID) (*models.User, error)
+	UpdateMe(ctx context.Context, userID uuid.UUID, input ProfileInput) (*models.User, error)
+	// PublicProfile is what anyone sees at /users/:username. Anonymous
+	// reviews are never listed there.
+	PublicProfile(ctx context.Context, username string) (PublicProfile, error)
+}
+
+// ProfileInput is a partial profile update; nil fields are left unchanged.
+type ProfileInput struct {
+	DisplayName      *string
+	AvatarURL        *string
+	Bio              *string
+	Locale           *string
+	AnonymousReviews *bool
+}
+
+// PublicProfile bundles a reviewer's public page.
+type PublicProfile struct {
+	User         models.User
+	Tier         reputation.Tier
+	Reviews      []models.Review
+	Achievements []models.UserAchievement
+}
+
+type DefaultProfileService struct {
+	Users        repository.UserRepository
+	Reviews      repository.ReviewRepository
+	Achievements repository.AchievementRepository
+	Tiers        reputation.Tiers
+}
+
+func (s *DefaultProfileService) Me(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	user, err := s.Users.GetByID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	return user, err
+}
+
+func (s *DefaultProfileService) UpdateMe(ctx context.Context, userID uuid.UUID, input ProfileInput) (*models.User, error) {
+	user, err := s.Me(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	profile := user.Profile()
+	if input.DisplayName != nil {
+		profile.DisplayName = strings.TrimSpace(*input.DisplayName)
+		if utf8.RuneCountInString(profile.DisplayName) > maxDisplayName {
+			return nil, fmt.Errorf("%w: display_name is longer than %d characters", ErrInvalidProfile, maxDisplayName)
+		}
+	}
+	if input.Bio != nil {
+		profile.Bio = strings.TrimSpace(*input.Bio)
+		if utf8.RuneCountInString(profile.Bio) > maxBio {
+			return nil, fmt.Errorf("%w: bio is longer than %d characters", ErrInvalidProfile, maxBio)
+		}
+	}
+	if input.AvatarURL != nil {
+		profile.AvatarURL = strings.TrimSpace(*input.AvatarURL)
+		if err := validateAvatarURL(profile.AvatarURL); err != nil {
+			return nil, err
+		}
+	}
+	if input.Locale != nil {
+		profile.Locale = ""
+		if raw := strings.TrimSpace(*input.Locale); raw != "" {
+			tag, err := language.Parse(raw)
+			if err != nil {
+				return nil, fmt.Errorf("%w: unknown locale %q", ErrInvalidProfile, raw)
+			}
+			profile.Locale = tag.String()
+		}
+	}
+	if input.AnonymousReviews != nil {
+		profile.AnonymousReviews = *input.AnonymousReviews
+	}
+	user.SetProfile(profile)
+	if err := s.Users.UpdateProfile(ctx, user.ID, user.ProfileMeta); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *DefaultProfileService) PublicProfile(ctx context.Context, username string) (PublicProfile, error) {
+	user, err := s.Users.GetByUsername(ctx, username)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return PublicProfile{}, ErrUserNotFound
+	}
+	if err != nil {
+		return PublicProfile{}, err
+	}
+	reviews, err := s.Reviews.ListPublicByUser(ctx, user.ID, publicProfileReviews)
+	if err != nil {
+		return PublicProfile{}, err
+	}
+	achievements, err := s.Achievements.ListByUser(ctx, user.ID)
+	if err != nil {
+		return PublicProfile{}, err
+	}
+	return PublicProfile{
+		User:         *user,
+		Tier:         s.Tiers.For(user.GamificationScore),
+		Reviews:      reviews,
+		Achievements: achievements,
+	}, nil
+}
+
+// validateAvatarURL accepts an empty value or an absolute http(s) URL.
+func validateAvatarURL(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	if len(raw) > maxAvatarURL {
+		return fmt.Errorf("%w: avatar_url is too long", ErrInvalidProfile)
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: avatar_url must be an http(s) URL", ErrInvalidProfile)
+	}
+	return nil
+}
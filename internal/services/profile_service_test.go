@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"crowdreview/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func TestProfileServiceUpdateMe(t *testing.T) {
+	ctx := context.Background()
+	user := &models.User{Email: "a@b.com", Username: "ana", ProfileMeta: datatypes.JSONMap{"theme": "dark"}}
+	user.ID = uuid.New()
+	service := &DefaultProfileService{Users: &mockUserRepo{users: map[string]*models.User{user.Email: user}}}
+
+	name, locale, anonymous := "  Ana  ", "pt-br", true
+	updated, err := service.UpdateMe(ctx, user.ID, ProfileInput{DisplayName: &name, Locale: &locale, AnonymousReviews: &anonymous})
+	require.NoError(t, err)
+	require.Equal(t, models.Profile{DisplayName: "Ana", Locale: "pt-BR", AnonymousReviews: true}, updated.Profile())
+	require.Equal(t, "dark", updated.ProfileMeta["theme"])
+
+	// Omitted fields are kept.
+	bio := "Reviewer"
+	updated, err = service.UpdateMe(ctx, user.ID, ProfileInput{Bio: &bio})
+	require.NoError(t, err)
+	require.Equal(t, "Ana", updated.Profile().DisplayName)
+
+	for _, bad := range []ProfileInput{
+		{AvatarURL: ptr("javascript:alert(1)")},
+		{Locale: ptr("not a locale")},
+		{DisplayName: ptr(string(make([]rune, maxDisplayName+1)))},
+	} {
+		_, err := service.UpdateMe(ctx, user.ID, bad)
+		require.ErrorIs(t, err, ErrInvalidProfile)
+	}
+}
+
+func TestHideAnonymousAuthors(t *testing.T) {
+	author := uuid.New()
+	reviews := func() []models.Review {
+		r := models.Review{UserID: author, Anonymous: true, User: models.User{Username: "ana"}}
+		r.User.ID = author
+		return []models.Review{r, {UserID: uuid.New()}}
+	}
+
+	public := reviews()
+	hideAnonymousAuthors(public, Viewer{})
+	require.Equal(t, uuid.Nil, public[0].UserID)
+	require.Empty(t, public[0].User.Username)
+	require.NotEqual(t, uuid.Nil, public[1].UserID)
+
+	own := reviews()
+	hideAnonymousAuthors(own, Viewer{UserID: author, Role: models.RoleUser})
+	require.Equal(t, author, own[0].UserID)
+
+	moderated := reviews()
+	hideAnonymousAuthors(moderated, Viewer{UserID: uuid.New(), Permissions: []string{models.PermReviewsModerate}})
+	require.Equal(t, "ana", moderated[0].User.Username)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
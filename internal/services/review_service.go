@@ -3,12 +3,12 @@ package services
 import (
 	"context"
 	"errors"
+	"log/slog"
 	_ "time"
 
 	"crowdreview/config"
 	"crowdreview/internal/models"
 	"crowdreview/internal/repository"
-	"crowdreview/internal/validation"
 
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
@@ -17,7 +17,7 @@ import (
 // ReviewService orchestrates review creation and retrieval.
 type ReviewService interface {
 	Create(ctx context.Context, userID uuid.UUID, companyID uuid.UUID, input CreateReviewInput) (*models.Review, error)
-	ListByCompany(ctx context.Context, companyID uuid.UUID) ([]models.Review, error)
+	ListByCompany(ctx context.Context, companyID uuid.UUID, viewer Viewer) ([]models.Review, error)
 }
 
 // CreateReviewInput is DTO for new reviews.
@@ -27,12 +27,16 @@ type CreateReviewInput struct {
 	Content     string
 	IPAddress   string
 	GeoLocation string
+	Scores      map[string]int // optional per-criterion scores keyed by criterion key
+	Anonymous   *bool          // nil uses the author's privacy setting
 }
 
 type DefaultReviewService struct {
 	Reviews     repository.ReviewRepository
+	Users       repository.UserRepository
 	Companies   repository.CompanyRepository
-	Worker      *validation.FraudWorker
+	Criteria    repository.CriteriaRepository
+	Classifier  classifier
 	RateLimiter *redis.Client
 	Config      config.Config
 }
@@ -42,8 +46,30 @@ func (s *DefaultReviewService) Create(ctx context.Context, userID uuid.UUID, com
 		return nil, errors.New("rating must be between 1 and 5")
 	}
 
+	author, err := s.Users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if author.EmailVerifiedAt == nil {
+		return nil, ErrEmailNotVerified
+	}
+
 	// Ensure company exists
-	if _, err := s.Companies.GetByID(ctx, companyID); err != nil {
+	company, err := s.Companies.GetByID(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	industry, err := s.Classifier.industryKey(ctx, company)
+	if err != nil {
+		return nil, err
+	}
+	criteria, err := s.Criteria.ListForIndustry(ctx, industry)
+	if err != nil {
+		return nil, err
+	}
+	scores, err := scoresForCriteria(criteria, input.Scores)
+	if err != nil {
 		return nil, err
 	}
 
@@ -55,19 +81,63 @@ func (s *DefaultReviewService) Create(ctx context.Context, userID uuid.UUID, com
 		Content:     input.Content,
 		IPAddress:   input.IPAddress,
 		GeoLocation: input.GeoLocation,
-		Status:      "pending",
+		Status:      models.ReviewStatusPending,
+		Scores:      scores,
+		Anonymous:   author.Profile().AnonymousReviews,
+	}
+	if input.Anonymous != nil {
+		review.Anonymous = *input.Anonymous
 	}
 
+	// The review.created event recorded with the review queues background
+	// validation and webhooks once the outbox relays it.
 	if err := s.Reviews.Create(ctx, review); err != nil {
 		return nil, err
 	}
-
-	// Enqueue background validation
-	s.Worker.Enqueue(*review)
+	slog.InfoContext(ctx, "review submitted",
+		slog.String("review_id", review.ID.String()),
+		slog.String("company_id", companyID.String()))
+	attachCriteria(review.Scores, criteria)
 
 	return review, nil
 }
 
-func (s *DefaultReviewService) ListByCompany(ctx context.Context, companyID uuid.UUID) ([]models.Review, error) {
-	return s.Reviews.ListByCompany(ctx, companyID)
+// ListByCompany returns the company's reviews visible to viewer.
+func (s *DefaultReviewService) ListByCompany(ctx context.Context, companyID uuid.UUID, viewer Viewer) ([]models.Review, error) {
+	reviews, err := s.Reviews.ListByCompany(ctx, companyID, reviewVisibility(viewer))
+	if err != nil {
+		return nil, err
+	}
+	hideAnonymousAuthors(reviews, viewer)
+	return reviews, nil
+}
+
+// hideAnonymousAuthors clears the author of anonymous reviews unless the
+// viewer wrote them or moderates reviews.
+func hideAnonymousAuthors(reviews []models.Review, viewer Viewer) {
+	if viewer.Can(models.PermReviewsModerate) {
+		return
+	}
+	for i := range reviews {
+		r := &reviews[i]
+		if r.Anonymous && (viewer.Anonymous() || r.UserID != viewer.UserID) {
+			r.UserID = uuid.Nil
+			r.User = models.User{}
+		}
+	}
+}
+
+// reviewVisibility is the public visibility policy: everyone sees approved
+// reviews, authors additionally see their own reviews in any state and
+// moderators see everything.
+func reviewVisibility(viewer Viewer) repository.ReviewVisibility {
+	if viewer.Can(models.PermReviewsModerate) {
+		return repository.ReviewVisibility{}
+	}
+	visibility := repository.ReviewVisibility{Statuses: []string{models.ReviewStatusApproved}}
+	if !viewer.Anonymous() {
+		id := viewer.UserID
+		visibility.AuthorID = &id
+	}
+	return visibility
 }
@@ -0,0 +1,26 @@
+package services
+
+import (
+	"testing"
+
+	"crowdreview/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReviewVisibilityPolicy(t *testing.T) {
+	anonymous := reviewVisibility(Viewer{})
+	require.Equal(t, []string{models.ReviewStatusApproved}, anonymous.Statuses)
+	require.Nil(t, anonymous.AuthorID)
+
+	userID := uuid.New()
+	author := reviewVisibility(Viewer{UserID: userID, Role: models.RoleUser})
+	require.Equal(t, []string{models.ReviewStatusApproved}, author.Statuses)
+	require.NotNil(t, author.AuthorID)
+	require.Equal(t, userID, *author.AuthorID)
+
+	admin := reviewVisibility(Viewer{UserID: uuid.New(), Role: models.RoleAdmin})
+	require.Empty(t, admin.Statuses)
+	require.Nil(t, admin.AuthorID)
+}
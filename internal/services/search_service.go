@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"html"
+	"strings"
+
+	"crowdreview/internal/repository"
+)
+
+// Search scopes.
+const (
+	SearchAll       = "all"
+	SearchCompanies = "companies"
+	SearchReviews   = "reviews"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 50
+	maxSearchLength    = 200
+)
+
+// SearchInput is the DTO for a search request.
+type SearchInput struct {
+	Query    string
+	Type     string
+	Industry string
+	Location string
+	Limit    int
+	Offset   int
+}
+
+// SearchResults groups ranked hits and facets. Snippets are HTML-escaped with
+// matches wrapped in <mark>.
+type SearchResults struct {
+	Companies      []repository.CompanyHit
+	CompaniesTotal int64
+	Reviews        []repository.ReviewHit
+	ReviewsTotal   int64
+	Industries     []repository.Facet
+	Locations      []repository.Facet
+}
+
+// SearchService runs full-text search over companies and approved reviews.
+type SearchService interface {
+	Search(ctx context.Context, input SearchInput) (SearchResults, error)
+}
+
+type DefaultSearchService struct {
+	Index repository.SearchRepository
+}
+
+func (s *DefaultSearchService) Search(ctx context.Context, input SearchInput) (SearchResults, error) {
+	query, err := normalizeSearch(input)
+	if err != nil {
+		return SearchResults{}, err
+	}
+	scope := input.Type
+	if scope == "" {
+		scope = SearchAll
+	}
+
+	var results SearchResults
+	switch scope {
+	case SearchAll, SearchCompanies, SearchReviews:
+	default:
+		return SearchResults{}, errors.New("type must be all, companies or reviews")
+	}
+	if scope != SearchReviews {
+		results.Companies, results.CompaniesTotal, err = s.Index.SearchCompanies(ctx, query)
+		if err != nil {
+			return SearchResults{}, err
+		}
+		results.Industries, results.Locations, err = s.Index.CompanyFacets(ctx, query)
+		if err != nil {
+			return SearchResults{}, err
+		}
+		for i := range results.Companies {
+			results.Companies[i].Snippet = highlight(results.Companies[i].Snippet)
+		}
+	}
+	if scope != SearchCompanies {
+		results.Reviews, results.ReviewsTotal, err = s.Index.SearchReviews(ctx, query)
+		if err != nil {
+			return SearchResults{}, err
+		}
+		for i := range results.Reviews {
+			results.Reviews[i].Snippet = highlight(results.Reviews[i].Snippet)
+		}
+	}
+	return results, nil
+}
+
+func normalizeSearch(input SearchInput) (repository.SearchQuery, error) {
+	text := strings.TrimSpace(input.Query)
+	if text == "" {
+		return repository.SearchQuery{}, errors.New("query is required")
+	}
+	if len(text) > maxSearchLength {
+		return repository.SearchQuery{}, errors.New("query is too long")
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	offset := input.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	return repository.SearchQuery{
+		Text:     text,
+		Industry: strings.TrimSpace(input.Industry),
+		Location: strings.TrimSpace(input.Location),
+		Limit:    limit,
+		Offset:   offset,
+	}, nil
+}
+
+// highlight escapes user content and turns the repository's match markers
+// into <mark> tags.
+func highlight(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	escaped = strings.ReplaceAll(escaped, repository.HighlightStart, "<mark>")
+	return strings.ReplaceAll(escaped, repository.HighlightStop, "</mark>")
+}
@@ -0,0 +1,25 @@
+package services
+
+import (
+	"testing"
+
+	"crowdreview/internal/repository"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHighlightEscapesContent(t *testing.T) {
+	raw := "<b>great</b> " + repository.HighlightStart + "delivery" + repository.HighlightStop
+	require.Equal(t, "&lt;b&gt;great&lt;/b&gt; <mark>delivery</mark>", highlight(raw))
+}
+
+func TestNormalizeSearchClampsPaging(t *testing.T) {
+	q, err := normalizeSearch(SearchInput{Query: "  entrega  ", Limit: 500, Offset: -3})
+	require.NoError(t, err)
+	require.Equal(t, "entrega", q.Text)
+	require.Equal(t, maxSearchLimit, q.Limit)
+	require.Equal(t, 0, q.Offset)
+
+	_, err = normalizeSearch(SearchInput{Query: "   "})
+	require.Error(t, err)
+}
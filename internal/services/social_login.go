@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"crowdreview/internal/models"
+	"crowdreview/internal/oidc"
+	"crowdreview/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	oidcStateTTL      = 10 * time.Minute
+	maxUsernameLength = 30
+)
+
+// Social login errors.
+var (
+	ErrUnknownProvider   = errors.New("unknown login provider")
+	ErrInvalidOIDCState  = errors.New("invalid or expired login state")
+	ErrOIDCEmailMissing  = errors.New("the provider did not share an email address")
+	ErrOIDCEmailConflict = errors.New("an account with this email already exists; sign in with your password to continue")
+	// ErrOIDCAccountUnverified keeps a social login from claiming an account
+	// whose email was never verified: whoever registered it may know its
+	// password. A password reset verifies the email and allows linking.
+	ErrOIDCAccountUnverified = errors.New("an account with this email exists but its email is not verified; reset its password to continue")
+)
+
+var usernameInvalid = regexp.MustCompile(`[^a-z0-9_.-]+`)
+
+// OIDCProviders lists the configured social login providers.
+func (s *DefaultAuthService) OIDCProviders() []string {
+	return s.OIDC.Names()
+}
+
+// StartOIDC begins an authorization code flow and returns the URL to send
+// the browser to. The state, PKCE verifier and nonce stay server side.
+func (s *DefaultAuthService) StartOIDC(ctx context.Context, provider string) (string, error) {
+	p, ok := s.OIDC[provider]
+	if !ok {
+		return "", ErrUnknownProvider
+	}
+	state, err := oidc.RandomString(32)
+	if err != nil {
+		return "", err
+	}
+	verifier, err := oidc.RandomString(32)
+	if err != nil {
+		return "", err
+	}
+	nonce, err := oidc.RandomString(16)
+	if err != nil {
+		return "", err
+	}
+	authURL, err := p.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return "", err
+	}
+	record := &models.OIDCState{
+		Provider:     provider,
+		StateHash:    utils.HashToken(state),
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(oidcStateTTL),
+	}
+	if err := s.Identities.SaveState(ctx, record); err != nil {
+		return "", err
+	}
+	return authURL, nil
+}
+
+// CompleteOIDC handles the provider callback. Known identities log in;
+// otherwise an account with the same provider-verified email is linked, or
+// a new account is created. Unverified emails never link to an existing
+// account, and no email links to an account that never verified its own. Two-factor accounts get a *TwoFactorRequiredError as in Login.
+func (s *DefaultAuthService) CompleteOIDC(ctx context.Context, provider, code, state, ip string) (*models.User, string, string, error) {
+	p, ok := s.OIDC[provider]
+	if !ok {
+		return nil, "", "", ErrUnknownProvider
+	}
+	pending, err := s.Identities.ConsumeState(ctx, provider, utils.HashToken(state))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, "", "", ErrInvalidOIDCState
+	}
+	if err != nil {
+		return nil, "", "", err
+	}
+	token, err := p.Exchange(ctx, code, pending.CodeVerifier)
+	if err != nil {
+		return nil, "", "", err
+	}
+	identity, err := p.Identity(ctx, token, pending.Nonce)
+	if err != nil {
+		return nil, "", "", err
+	}
+	user, err := s.userForIdentity(ctx, provider, identity)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return s.finishLogin(ctx, user, ip)
+}
+
+func (s *DefaultAuthService) userForIdentity(ctx context.Context, provider string, identity oidc.Identity) (*models.User, error) {
+	linked, err := s.Identities.GetByProviderSubject(ctx, provider, identity.Subject)
+	if err == nil {
+		return s.Users.GetByID(ctx, linked.UserID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if identity.Email == "" {
+		return nil, ErrOIDCEmailMissing
+	}
+	record := &models.Identity{Provider: provider, Subject: identity.Subject, Email: identity.Email}
+
+	existing, err := s.Users.GetByEmail(ctx, identity.Email)
+	switch {
+	case err == nil:
+		if !identity.EmailVerified {
+			return nil, ErrOIDCEmailConflict
+		}
+		if existing.EmailVerifiedAt == nil {
+			return nil, ErrOIDCAccountUnverified
+		}
+		record.UserID = existing.ID
+		if err := s.Identities.Create(ctx, record); err != nil {
+			return nil, err
+		}
+		return existing, nil
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+
+	// Social accounts get an unusable random password; a password reset
+	// sets a real one.
+	secret, err := oidc.RandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := utils.HashPassword(secret)
+	if err != nil {
+		return nil, err
+	}
+	username, err := s.availableUsername(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+	user := &models.User{Email: identity.Email, Username: username, PasswordHash: hash, Role: models.RoleUser}
+	if identity.EmailVerified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	if err := s.Identities.CreateUser(ctx, user, record); err != nil {
+		return nil, err
+	}
+	if !identity.EmailVerified {
+		s.sendVerificationAfterSignup(ctx, user.ID)
+	}
+	return user, nil
+}
+
+// availableUsername derives a username from the identity, adding a random
+// suffix when it is taken.
+func (s *DefaultAuthService) availableUsername(ctx context.Context, identity oidc.Identity) (string, error) {
+	base := identity.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(identity.Email, "@")
+	}
+	base = strings.Trim(usernameInvalid.ReplaceAllString(strings.ToLower(base), ""), "._-")
+	if base == "" {
+		base = "user"
+	}
+	if len(base) > maxUsernameLength-7 {
+		base = base[:maxUsernameLength-7]
+	}
+	candidate := base
+	for i := 0; i < 5; i++ {
+		_, err := s.Users.GetByUsername(ctx, candidate)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		candidate = base + "-" + uuid.NewString()[:6]
+	}
+	return "", errors.New("could not find a free username")
+}
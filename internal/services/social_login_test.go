@@ -0,0 +1,153 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"crowdreview/config"
+	"crowdreview/internal/models"
+	"crowdreview/internal/oidc"
+	"crowdreview/internal/oidc/oidctest"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type mockIdentityRepo struct {
+	users      *mockUserRepo
+	identities []*models.Identity
+	states     map[string]*models.OIDCState
+}
+
+func (m *mockIdentityRepo) GetByProviderSubject(ctx context.Context, provider, subject string) (*models.Identity, error) {
+	for _, i := range m.identities {
+		if i.Provider == provider && i.Subject == subject {
+			return i, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+func (m *mockIdentityRepo) Create(ctx context.Context, identity *models.Identity) error {
+	m.identities = append(m.identities, identity)
+	return nil
+}
+func (m *mockIdentityRepo) CreateUser(ctx context.Context, user *models.User, identity *models.Identity) error {
+	user.ID = uuid.New()
+	if err := m.users.Create(ctx, user); err != nil {
+		return err
+	}
+	identity.UserID = user.ID
+	return m.Create(ctx, identity)
+}
+func (m *mockIdentityRepo) SaveState(ctx context.Context, state *models.OIDCState) error {
+	if m.states == nil {
+		m.states = make(map[string]*models.OIDCState)
+	}
+	m.states[state.StateHash] = state
+	return nil
+}
+func (m *mockIdentityRepo) ConsumeState(ctx context.Context, provider, hash string) (*models.OIDCState, error) {
+	s, ok := m.states[hash]
+	if !ok || s.Provider != provider || s.UsedAt != nil || s.ExpiresAt.Before(time.Now()) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	now := time.Now()
+	s.UsedAt = &now
+	return s, nil
+}
+
+func TestAuthServiceOIDCLogin(t *testing.T) {
+	ctx := context.Background()
+	idp := oidctest.NewServer("crowdreview")
+	defer idp.Close()
+
+	verifiedAt := time.Now().Add(-time.Hour)
+	existing := &models.User{Email: "ana@example.com", Username: "ana", PasswordHash: "x", Role: models.RoleUser, EmailVerifiedAt: &verifiedAt}
+	existing.ID = uuid.New()
+	users := &mockUserRepo{users: map[string]*models.User{existing.Email: existing}}
+	service, identities, login := newTestOIDCLogin(t, idp, users)
+
+	// An unverified email must not take over an existing account.
+	_, err := login(oidctest.User{Subject: "s1", Email: "ana@example.com"})
+	require.ErrorIs(t, err, ErrOIDCEmailConflict)
+
+	// A verified email links the identity to the existing account.
+	user, err := login(oidctest.User{Subject: "s1", Email: "ana@example.com", EmailVerified: true})
+	require.NoError(t, err)
+	require.Equal(t, existing.ID, user.ID)
+	require.Len(t, identities.identities, 1)
+
+	// The linked identity keeps working even if the provider email changes.
+	user, err = login(oidctest.User{Subject: "s1", Email: "ana@new.example.com", EmailVerified: true})
+	require.NoError(t, err)
+	require.Equal(t, existing.ID, user.ID)
+
+	// Unknown emails create an account with a free username.
+	user, err = login(oidctest.User{Subject: "s2", Email: "ana@other.example.com", EmailVerified: true})
+	require.NoError(t, err)
+	require.NotEqual(t, existing.ID, user.ID)
+	require.NotEqual(t, "ana", user.Username)
+	require.Contains(t, user.Username, "ana-")
+
+	// States are single use.
+	authURL, err := service.StartOIDC(ctx, "mock")
+	require.NoError(t, err)
+	code, state, err := idp.Authorize(authURL, oidctest.User{Subject: "s2"})
+	require.NoError(t, err)
+	_, _, _, err = service.CompleteOIDC(ctx, "mock", code, state, "127.0.0.1")
+	require.NoError(t, err)
+	_, _, _, err = service.CompleteOIDC(ctx, "mock", code, state, "127.0.0.1")
+	require.ErrorIs(t, err, ErrInvalidOIDCState)
+}
+
+func TestAuthServiceOIDCDoesNotClaimUnverifiedAccount(t *testing.T) {
+	ctx := context.Background()
+	idp := oidctest.NewServer("crowdreview")
+	defer idp.Close()
+
+	// Someone registered the address with a password of their choosing and
+	// never verified it.
+	squatter := &models.User{Email: "bia@example.com", Username: "bia", PasswordHash: "x", Role: models.RoleUser}
+	squatter.ID = uuid.New()
+	users := &mockUserRepo{users: map[string]*models.User{squatter.Email: squatter}}
+	_, identities, login := newTestOIDCLogin(t, idp, users)
+
+	_, err := login(oidctest.User{Subject: "s1", Email: "bia@example.com", EmailVerified: true})
+	require.ErrorIs(t, err, ErrOIDCAccountUnverified)
+	require.Empty(t, identities.identities)
+	require.Nil(t, squatter.EmailVerifiedAt)
+
+	// Once the owner proves the address, e.g. by resetting the password,
+	// the identity links.
+	require.NoError(t, users.MarkEmailVerified(ctx, squatter.ID))
+	user, err := login(oidctest.User{Subject: "s1", Email: "bia@example.com", EmailVerified: true})
+	require.NoError(t, err)
+	require.Equal(t, squatter.ID, user.ID)
+}
+
+// newTestOIDCLogin wires an auth service to idp and returns a helper that
+// runs a full login for the given provider user.
+func newTestOIDCLogin(t *testing.T, idp *oidctest.Server, users *mockUserRepo) (*DefaultAuthService, *mockIdentityRepo, func(oidctest.User) (*models.User, error)) {
+	ctx := context.Background()
+	identities := &mockIdentityRepo{users: users}
+	service := newTestAuthService(t, users, &bytes.Buffer{})
+	service.Identities = identities
+	service.OIDC = oidc.Registry{"mock": oidc.NewProvider(
+		config.OIDCProvider{Name: "mock", Issuer: idp.Issuer(), ClientID: "crowdreview"}, "http://app/oauth/callback/mock", nil)}
+
+	login := func(user oidctest.User) (*models.User, error) {
+		authURL, err := service.StartOIDC(ctx, "mock")
+		require.NoError(t, err)
+		code, state, err := idp.Authorize(authURL, user)
+		require.NoError(t, err)
+		u, access, _, err := service.CompleteOIDC(ctx, "mock", code, state, "127.0.0.1")
+		if err == nil {
+			require.NotEmpty(t, access)
+		}
+		return u, err
+	}
+	return service, identities, login
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"crowdreview/internal/models"
+	"crowdreview/internal/repository"
+	"crowdreview/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TaxonomyNode is a node of the industry or region tree. Companies counts the
+// companies linked to the node or any of its descendants.
+type TaxonomyNode struct {
+	ID        uuid.UUID
+	ParentID  *uuid.UUID
+	Kind      string // region kind; empty for industries
+	Slug      string
+	Name      string
+	Companies int64
+	Children  []*TaxonomyNode
+}
+
+func industryNodes(industries []models.Industry) []*TaxonomyNode {
+	nodes := make([]*TaxonomyNode, 0, len(industries))
+	for _, i := range industries {
+		nodes = append(nodes, &TaxonomyNode{ID: i.ID, ParentID: i.ParentID, Slug: i.Slug, Name: i.Name})
+	}
+	return nodes
+}
+
+func regionNodes(regions []models.Region) []*TaxonomyNode {
+	nodes := make([]*TaxonomyNode, 0, len(regions))
+	for _, r := range regions {
+		nodes = append(nodes, &TaxonomyNode{ID: r.ID, ParentID: r.ParentID, Kind: r.Kind, Slug: r.Slug, Name: r.Name})
+	}
+	return nodes
+}
+
+// buildTree links nodes to their parents, rolls company counts up the tree and
+// returns the roots sorted by name. Nodes whose parent is missing become roots.
+func buildTree(nodes []*TaxonomyNode, counts map[uuid.UUID]int64) []*TaxonomyNode {
+	byID := make(map[uuid.UUID]*TaxonomyNode, len(nodes))
+	for _, n := range nodes {
+		byID[n.ID] = n
+	}
+	var roots []*TaxonomyNode
+	for _, n := range nodes {
+		if n.ParentID != nil {
+			if parent, ok := byID[*n.ParentID]; ok {
+				parent.Children = append(parent.Children, n)
+				continue
+			}
+		}
+		roots = append(roots, n)
+	}
+	var rollup func(n *TaxonomyNode) int64
+	rollup = func(n *TaxonomyNode) int64 {
+		sort.Slice(n.Children, func(i, j int) bool { return n.Children[i].Name < n.Children[j].Name })
+		n.Companies = counts[n.ID]
+		for _, child := range n.Children {
+			n.Companies += rollup(child)
+		}
+		return n.Companies
+	}
+	sort.Slice(roots, func(i, j int) bool { return roots[i].Name < roots[j].Name })
+	for _, root := range roots {
+		rollup(root)
+	}
+	return roots
+}
+
+// subtreeIDs returns id plus the IDs of all its descendants.
+func subtreeIDs(nodes []*TaxonomyNode, id uuid.UUID) []uuid.UUID {
+	children := make(map[uuid.UUID][]uuid.UUID)
+	for _, n := range nodes {
+		if n.ParentID != nil {
+			children[*n.ParentID] = append(children[*n.ParentID], n.ID)
+		}
+	}
+	ids := []uuid.UUID{id}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids
+}
+
+// regionPath returns the region and its ancestors, most specific first.
+func regionPath(ctx context.Context, repo repository.TaxonomyRepository, id uuid.UUID) ([]models.Region, error) {
+	var path []models.Region
+	next := &id
+	for next != nil {
+		if len(path) > 3 {
+			return nil, errors.New("region hierarchy is too deep")
+		}
+		region, err := repo.GetRegion(ctx, *next)
+		if err != nil {
+			return nil, err
+		}
+		path = append(path, *region)
+		next = region.ParentID
+	}
+	return path, nil
+}
+
+// locationLabel renders a region path as "City, State, Country".
+func locationLabel(path []models.Region) string {
+	names := make([]string, 0, len(path))
+	for _, r := range path {
+		names = append(names, r.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// locationParts splits free text such as "São Paulo, SP, Brasil" or
+// "Curitiba - PR" into names ordered country first. Without an explicit
+// country the default country is assumed; a single name is read as a city.
+func locationParts(text, defaultCountry string) []string {
+	text = strings.ReplaceAll(text, " - ", ",")
+	var parts []string
+	for _, p := range strings.Split(text, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	switch len(parts) {
+	case 0:
+		return nil
+	case 1, 2:
+		parts = append(parts, defaultCountry)
+	}
+	if len(parts) > 3 {
+		parts = parts[len(parts)-3:]
+	}
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return parts
+}
+
+// regionKinds gives the kinds of a parsed location path of the given length.
+func regionKinds(n int) []string {
+	if n == 2 {
+		return []string{models.RegionCountry, models.RegionCity}
+	}
+	return []string{models.RegionCountry, models.RegionState, models.RegionCity}[:n]
+}
+
+// classifier links a company's industry and location to the taxonomy.
+type classifier struct {
+	Taxonomy       repository.TaxonomyRepository
+	DefaultCountry string
+}
+
+// classify resolves IndustryID/RegionID, falling back to matching the
+// free-text Industry and Location, and refreshes the denormalized text.
+func (c classifier) classify(ctx context.Context, company *models.Company) error {
+	switch {
+	case company.IndustryID != nil:
+		industry, err := c.Taxonomy.GetIndustry(ctx, *company.IndustryID)
+		if err != nil {
+			return fmt.Errorf("unknown industry: %w", err)
+		}
+		company.Industry = industry.Name
+	case strings.TrimSpace(company.Industry) != "":
+		industry, err := c.Taxonomy.GetIndustryBySlug(ctx, utils.Slugify(company.Industry))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("unknown industry %q", company.Industry)
+			}
+			return err
+		}
+		company.IndustryID = &industry.ID
+		company.Industry = industry.Name
+	}
+
+	switch {
+	case company.RegionID != nil:
+		path, err := regionPath(ctx, c.Taxonomy, *company.RegionID)
+		if err != nil {
+			return fmt.Errorf("unknown region: %w", err)
+		}
+		company.Location = locationLabel(path)
+	case strings.TrimSpace(company.Location) != "":
+		var parent *uuid.UUID
+		var path []models.Region
+		for _, name := range locationParts(company.Location, c.DefaultCountry) {
+			region, err := c.Taxonomy.FindRegion(ctx, parent, utils.Slugify(name))
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fmt.Errorf("unknown location %q", company.Location)
+				}
+				return err
+			}
+			path = append([]models.Region{*region}, path...)
+			parent = &region.ID
+		}
+		company.RegionID = parent
+		company.Location = locationLabel(path)
+	}
+	return nil
+}
+
+// industryKey is the slug criteria are matched against for a company.
+func (c classifier) industryKey(ctx context.Context, company *models.Company) (string, error) {
+	if company.IndustryID == nil {
+		return utils.Slugify(company.Industry), nil
+	}
+	industry, err := c.Taxonomy.GetIndustry(ctx, *company.IndustryID)
+	if err != nil {
+		return "", err
+	}
+	return industry.Slug, nil
+}
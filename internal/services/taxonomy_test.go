@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"crowdreview/internal/models"
+	"crowdreview/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type mockTaxonomyRepo struct {
+	repository.TaxonomyRepository
+	industries map[uuid.UUID]*models.Industry
+}
+
+func (m *mockTaxonomyRepo) ListIndustries(ctx context.Context) ([]models.Industry, error) {
+	var out []models.Industry
+	for _, i := range m.industries {
+		out = append(out, *i)
+	}
+	return out, nil
+}
+func (m *mockTaxonomyRepo) GetIndustry(ctx context.Context, id uuid.UUID) (*models.Industry, error) {
+	i, ok := m.industries[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	industry := *i
+	return &industry, nil
+}
+func (m *mockTaxonomyRepo) UpdateIndustry(ctx context.Context, industry *models.Industry) error {
+	stored := *industry
+	m.industries[industry.ID] = &stored
+	return nil
+}
+func (m *mockTaxonomyRepo) RenameIndustry(ctx context.Context, id uuid.UUID, name string) error {
+	return nil
+}
+
+func TestLocationParts(t *testing.T) {
+	require.Equal(t, []string{"Brasil", "SP", "São Paulo"}, locationParts("São Paulo, SP, Brasil", "Portugal"))
+	require.Equal(t, []string{"Brasil", "PR", "Curitiba"}, locationParts("Curitiba - PR", "Brasil"))
+	require.Equal(t, []string{"Brasil", "Recife"}, locationParts(" Recife ", "Brasil"))
+	require.Nil(t, locationParts(" , ", "Brasil"))
+}
+
+func TestBuildTreeRollsUpCounts(t *testing.T) {
+	root := &TaxonomyNode{ID: uuid.New(), Name: "Varejo"}
+	child := &TaxonomyNode{ID: uuid.New(), ParentID: &root.ID, Name: "Moda"}
+	grandchild := &TaxonomyNode{ID: uuid.New(), ParentID: &child.ID, Name: "Calçados"}
+	other := &TaxonomyNode{ID: uuid.New(), Name: "Bancos"}
+	counts := map[uuid.UUID]int64{root.ID: 1, child.ID: 2, grandchild.ID: 3, other.ID: 4}
+
+	nodes := []*TaxonomyNode{grandchild, child, root, other}
+	roots := buildTree(nodes, counts)
+	require.Len(t, roots, 2)
+	require.Equal(t, "Bancos", roots[0].Name)
+	require.Equal(t, int64(6), roots[1].Companies)
+	require.Equal(t, int64(5), roots[1].Children[0].Companies)
+
+	require.ElementsMatch(t, []uuid.UUID{child.ID, grandchild.ID}, subtreeIDs(nodes, child.ID))
+}
+
+func TestUpdateIndustryMovesOnlyWhenAsked(t *testing.T) {
+	ctx := context.Background()
+	retail := &models.Industry{Name: "Varejo", Slug: "varejo"}
+	retail.ID = uuid.New()
+	fashion := &models.Industry{ParentID: &retail.ID, Name: "Moda", Slug: "moda"}
+	fashion.ID = uuid.New()
+	repo := &mockTaxonomyRepo{industries: map[uuid.UUID]*models.Industry{retail.ID: retail, fashion.ID: fashion}}
+	service := &DefaultDirectoryService{Taxonomy: repo}
+
+	renamed, err := service.UpdateIndustry(ctx, fashion.ID, IndustryUpdate{Name: "Moda e acessórios"})
+	require.NoError(t, err)
+	require.Equal(t, "Moda e acessórios", renamed.Name)
+	require.Equal(t, retail.ID, *renamed.ParentID, "a rename keeps the parent")
+
+	_, err = service.UpdateIndustry(ctx, retail.ID, IndustryUpdate{Move: true, ParentID: &fashion.ID})
+	require.Error(t, err, "an industry cannot move under its own subtree")
+
+	moved, err := service.UpdateIndustry(ctx, fashion.ID, IndustryUpdate{Move: true})
+	require.NoError(t, err)
+	require.Nil(t, moved.ParentID)
+
+	_, err = service.UpdateIndustry(ctx, uuid.New(), IndustryUpdate{Name: "Bancos"})
+	require.ErrorIs(t, err, ErrIndustryNotFound)
+}
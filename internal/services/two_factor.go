@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"crowdreview/internal/models"
+	"crowdreview/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	totpIssuer         = "CrowdReview"
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+	recoveryAlphabet   = "abcdefghijkmnpqrstuvwxyz23456789" // no 0/o or 1/l
+)
+
+// Two-factor errors.
+var (
+	ErrTwoFactorEnabled     = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled  = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorMandatory   = errors.New("two-factor authentication is mandatory for accounts with admin permissions")
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	ErrInvalidChallenge     = errors.New("invalid or expired login challenge")
+)
+
+// TwoFactorRequiredError is returned by Login when the password is correct
+// but the account has two-factor authentication: the login must be finished
+// with CompleteLogin and the challenge token.
+type TwoFactorRequiredError struct {
+	ChallengeToken string
+	ExpiresIn      time.Duration
+}
+
+func (e *TwoFactorRequiredError) Error() string {
+	return "two-factor authentication required"
+}
+
+// TOTPEnrollment is what an authenticator app needs; URI is usually shown
+// as a QR code.
+type TOTPEnrollment struct {
+	Secret string
+	URI    string
+}
+
+func (s *DefaultAuthService) EnrollTOTP(ctx context.Context, userID uuid.UUID) (TOTPEnrollment, error) {
+	user, err := s.Users.GetByID(ctx, userID)
+	if err != nil {
+		return TOTPEnrollment{}, err
+	}
+	if cred, err := s.confirmedTOTP(ctx, userID); err != nil {
+		return TOTPEnrollment{}, err
+	} else if cred != nil {
+		return TOTPEnrollment{}, ErrTwoFactorEnabled
+	}
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return TOTPEnrollment{}, err
+	}
+	if err := s.TwoFactor.Enroll(ctx, &models.TOTPCredential{UserID: userID, Secret: secret}); err != nil {
+		return TOTPEnrollment{}, err
+	}
+	return TOTPEnrollment{Secret: secret, URI: utils.TOTPProvisioningURI(totpIssuer, user.Email, secret)}, nil
+}
+
+// ConfirmTOTP activates a pending enrollment with a first code and returns
+// the recovery codes, which are never shown again.
+func (s *DefaultAuthService) ConfirmTOTP(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	cred, err := s.TwoFactor.GetByUser(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cred.ConfirmedAt != nil {
+		return nil, ErrTwoFactorEnabled
+	}
+	step, ok := utils.MatchTOTP(cred.Secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.TwoFactor.Confirm(ctx, userID, step, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTOTP turns two-factor authentication off after checking a current
+// code or recovery code.
+func (s *DefaultAuthService) DisableTOTP(ctx context.Context, userID uuid.UUID, code string) error {
+	user, err := s.Users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if s.Config.RequireAdmin2FA {
+		perms, err := userPermissions(ctx, s.Admins, user)
+		if err != nil {
+			return err
+		}
+		if len(perms) > 0 {
+			return ErrTwoFactorMandatory
+		}
+	}
+	if err := s.checkSecondFactor(ctx, userID, code); err != nil {
+		return err
+	}
+	return s.TwoFactor.Disable(ctx, userID)
+}
+
+// RegenerateRecoveryCodes replaces every recovery code after checking a
+// current code.
+func (s *DefaultAuthService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	if err := s.checkSecondFactor(ctx, userID, code); err != nil {
+		return nil, err
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.TwoFactor.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// CompleteLogin finishes a two-step login with a TOTP or recovery code.
+// Wrong codes count as failed logins for the login guard.
+func (s *DefaultAuthService) CompleteLogin(ctx context.Context, challenge, code, ip string) (*models.User, string, string, error) {
+	claims, err := utils.ParseToken(challenge, s.Keys, s.Config.JWTIssuer, utils.ChallengeAudience(s.Config))
+	if err != nil {
+		return nil, "", "", ErrInvalidChallenge
+	}
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, "", "", ErrInvalidChallenge
+	}
+	user, err := s.Users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if err := s.Guard.Check(ctx, user.Email, ip); err != nil {
+		return nil, "", "", err
+	}
+	if err := s.checkSecondFactor(ctx, userID, code); err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			if err := s.Guard.Fail(ctx, user.Email, ip, &user.ID); err != nil {
+				return nil, "", "", err
+			}
+		}
+		return nil, "", "", err
+	}
+	if err := s.Guard.Succeed(ctx, user, ip); err != nil {
+		return nil, "", "", err
+	}
+	access, refresh, err := s.issueTokens(ctx, user, uuid.New(), true)
+	return user, access, refresh, err
+}
+
+// confirmedTOTP returns the user's active credential, or nil without 2FA.
+func (s *DefaultAuthService) confirmedTOTP(ctx context.Context, userID uuid.UUID) (*models.TOTPCredential, error) {
+	cred, err := s.TwoFactor.GetByUser(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cred.ConfirmedAt == nil {
+		return nil, nil
+	}
+	return cred, nil
+}
+
+// checkSecondFactor accepts a TOTP code whose time step was not used yet,
+// or an unused recovery code.
+func (s *DefaultAuthService) checkSecondFactor(ctx context.Context, userID uuid.UUID, code string) error {
+	cred, err := s.confirmedTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if cred == nil {
+		return ErrTwoFactorNotEnabled
+	}
+	if step, ok := utils.MatchTOTP(cred.Secret, code, time.Now()); ok {
+		fresh, err := s.TwoFactor.UseStep(ctx, userID, step)
+		if err != nil {
+			return err
+		}
+		if fresh {
+			return nil
+		}
+		return ErrInvalidTwoFactorCode
+	}
+	normalized := normalizeRecoveryCode(code)
+	if len(normalized) != recoveryCodeLength {
+		return ErrInvalidTwoFactorCode
+	}
+	used, err := s.TwoFactor.UseRecoveryCode(ctx, userID, utils.HashToken(normalized))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// newRecoveryCodes returns codes formatted for display ("abcde-fghij") and
+// the hashes to store.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	buf := make([]byte, recoveryCodeLength)
+	for i := 0; i < recoveryCodeCount; i++ {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		raw := make([]byte, recoveryCodeLength)
+		for j, b := range buf {
+			raw[j] = recoveryAlphabet[int(b)%len(recoveryAlphabet)]
+		}
+		code := string(raw)
+		codes = append(codes, fmt.Sprintf("%s-%s", code[:5], code[5:]))
+		hashes = append(hashes, utils.HashToken(code))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"crowdreview/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Viewer identifies who is reading data so services can apply visibility
+// rules. The zero value is an anonymous visitor.
+type Viewer struct {
+	UserID      uuid.UUID
+	Role        string
+	Permissions []string // from the access token; nil means the role's defaults
+	MFA         bool     // the access token comes from a login that passed a second factor
+}
+
+// Anonymous reports whether the request carries no authenticated user.
+func (v Viewer) Anonymous() bool {
+	return v.UserID == uuid.Nil
+}
+
+// Can reports whether the viewer holds the admin permission perm.
+func (v Viewer) Can(perm string) bool {
+	perms := v.Permissions
+	if perms == nil {
+		perms = models.RolePermissions[v.Role]
+	}
+	for _, p := range perms {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
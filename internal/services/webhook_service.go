@@ -0,0 +1,427 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"crowdreview/config"
+	"crowdreview/internal/models"
+	"crowdreview/internal/repository"
+	"crowdreview/internal/webhooks"
+	"crowdreview/pkg/logging"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	// webhookBatchSize caps the deliveries one dispatch run sends.
+	webhookBatchSize = 50
+	// webhookLease is how long a claimed delivery is hidden from other
+	// dispatchers while it is being sent.
+	webhookLease = time.Minute
+	// webhookMaxAttempts is when a failing delivery is given up.
+	webhookMaxAttempts = 8
+	webhookTimeout     = 10 * time.Second
+	maxWebhookURL      = 2048
+	maxWebhookError    = 500
+)
+
+// Webhook errors.
+var (
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrInvalidWebhook   = errors.New("invalid webhook")
+	ErrWebhookForbidden = errors.New("not allowed to manage this webhook")
+)
+
+// WebhookInput creates or partially updates a subscription; nil fields are
+// left unchanged. CompanyID is only read on creation; nil subscribes to
+// every company.
+type WebhookInput struct {
+	CompanyID    *uuid.UUID
+	URL          *string
+	Events       []string
+	Active       *bool
+	RotateSecret bool
+}
+
+// WebhookDeliveries is a page of a subscription's delivery log.
+type WebhookDeliveries struct {
+	Total      int64
+	Deliveries []models.WebhookDelivery
+}
+
+// WebhookService manages webhook subscriptions and delivers review events
+// to them. Company owners manage their company's subscriptions; holders
+// of webhooks.manage manage all of them, including global ones.
+type WebhookService interface {
+	List(ctx context.Context, viewer Viewer) ([]models.WebhookSubscription, error)
+	// Create generates the subscription's signing secret; Update replaces
+	// it when RotateSecret is set.
+	Create(ctx context.Context, viewer Viewer, input WebhookInput) (*models.WebhookSubscription, error)
+	Update(ctx context.Context, viewer Viewer, id uuid.UUID, input WebhookInput) (*models.WebhookSubscription, error)
+	Delete(ctx context.Context, viewer Viewer, id uuid.UUID) error
+	Deliveries(ctx context.Context, viewer Viewer, id uuid.UUID, limit, offset int) (WebhookDeliveries, error)
+	// Redeliver queues a past delivery again with the same event ID.
+	Redeliver(ctx context.Context, viewer Viewer, id, deliveryID uuid.UUID) (*models.WebhookDelivery, error)
+
+	// ReviewCreated and ReviewStatusChanged queue the review event of an
+	// outbox event, whose ID becomes the webhook event ID. Each
+	// subscription gets an event once however often it is published.
+	ReviewCreated(ctx context.Context, eventID, reviewID uuid.UUID) error
+	ReviewStatusChanged(ctx context.Context, eventID, reviewID uuid.UUID, status string) error
+	// Dispatch sends the deliveries that are due, returning how many
+	// succeeded. Failures are retried with exponential backoff.
+	Dispatch(ctx context.Context) (int, error)
+	// Start runs Dispatch every WEBHOOK_POLL_SECONDS until ctx is done.
+	Start(ctx context.Context)
+}
+
+type DefaultWebhookService struct {
+	Webhooks  repository.WebhookRepository
+	Companies repository.CompanyRepository
+	Reviews   repository.ReviewRepository
+	Client    *http.Client
+	Config    config.Config
+}
+
+func (s *DefaultWebhookService) List(ctx context.Context, viewer Viewer) ([]models.WebhookSubscription, error) {
+	if s.managesAll(viewer) {
+		return s.Webhooks.ListSubscriptions(ctx, nil)
+	}
+	return s.Webhooks.ListSubscriptions(ctx, &viewer.UserID)
+}
+
+func (s *DefaultWebhookService) Create(ctx context.Context, viewer Viewer, input WebhookInput) (*models.WebhookSubscription, error) {
+	if input.URL == nil || len(input.Events) == 0 {
+		return nil, fmt.Errorf("%w: url and events are required", ErrInvalidWebhook)
+	}
+	if err := s.authorize(ctx, viewer, input.CompanyID); err != nil {
+		return nil, err
+	}
+	sub := &models.WebhookSubscription{CompanyID: input.CompanyID, CreatedByID: viewer.UserID, Active: true}
+	input.RotateSecret = true
+	if err := s.apply(sub, input); err != nil {
+		return nil, err
+	}
+	if err := s.Webhooks.CreateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *DefaultWebhookService) Update(ctx context.Context, viewer Viewer, id uuid.UUID, input WebhookInput) (*models.WebhookSubscription, error) {
+	sub, err := s.subscription(ctx, viewer, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(sub, input); err != nil {
+		return nil, err
+	}
+	if err := s.Webhooks.UpdateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *DefaultWebhookService) Delete(ctx context.Context, viewer Viewer, id uuid.UUID) error {
+	if _, err := s.subscription(ctx, viewer, id); err != nil {
+		return err
+	}
+	return s.Webhooks.DeleteSubscription(ctx, id)
+}
+
+func (s *DefaultWebhookService) Deliveries(ctx context.Context, viewer Viewer, id uuid.UUID, limit, offset int) (WebhookDeliveries, error) {
+	if _, err := s.subscription(ctx, viewer, id); err != nil {
+		return WebhookDeliveries{}, err
+	}
+	if limit <= 0 {
+		limit = defaultDirectoryLimit
+	}
+	if limit > maxDirectoryLimit {
+		limit = maxDirectoryLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	deliveries, total, err := s.Webhooks.ListDeliveries(ctx, id, limit, offset)
+	if err != nil {
+		return WebhookDeliveries{}, err
+	}
+	return WebhookDeliveries{Total: total, Deliveries: deliveries}, nil
+}
+
+func (s *DefaultWebhookService) Redeliver(ctx context.Context, viewer Viewer, id, deliveryID uuid.UUID) (*models.WebhookDelivery, error) {
+	if _, err := s.subscription(ctx, viewer, id); err != nil {
+		return nil, err
+	}
+	original, err := s.Webhooks.GetDelivery(ctx, id, deliveryID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	delivery := &models.WebhookDelivery{
+		SubscriptionID: id,
+		EventID:        original.EventID,
+		Event:          original.Event,
+		Payload:        original.Payload,
+		Status:         models.WebhookPending,
+		NextAttemptAt:  &now,
+		RedeliveryOf:   &original.ID,
+	}
+	if err := s.Webhooks.CreateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// subscription loads a subscription the viewer may manage.
+func (s *DefaultWebhookService) subscription(ctx context.Context, viewer Viewer, id uuid.UUID) (*models.WebhookSubscription, error) {
+	sub, err := s.Webhooks.GetSubscription(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, viewer, sub.CompanyID); err != nil {
+		// Do not reveal subscriptions of other companies.
+		return nil, ErrWebhookNotFound
+	}
+	return sub, nil
+}
+
+// managesAll reports whether the viewer acts under webhooks.manage, which
+// like the admin routes needs a second factor with RequireAdmin2FA.
+func (s *DefaultWebhookService) managesAll(viewer Viewer) bool {
+	if s.Config.RequireAdmin2FA && !viewer.MFA {
+		return false
+	}
+	return viewer.Can(models.PermWebhooksManage)
+}
+
+// authorize checks that the viewer may manage subscriptions of the company,
+// or global ones when companyID is nil.
+func (s *DefaultWebhookService) authorize(ctx context.Context, viewer Viewer, companyID *uuid.UUID) error {
+	if s.managesAll(viewer) {
+		if companyID == nil {
+			return nil
+		}
+		_, err := s.Companies.GetByID(ctx, *companyID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: company not found", ErrInvalidWebhook)
+		}
+		return err
+	}
+	if companyID == nil || viewer.Anonymous() {
+		return ErrWebhookForbidden
+	}
+	company, err := s.Companies.GetByID(ctx, *companyID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrWebhookForbidden
+	}
+	if err != nil {
+		return err
+	}
+	if company.OwnerID == nil || *company.OwnerID != viewer.UserID {
+		return ErrWebhookForbidden
+	}
+	return nil
+}
+
+// apply validates input and copies it onto sub.
+func (s *DefaultWebhookService) apply(sub *models.WebhookSubscription, input WebhookInput) error {
+	if input.URL != nil {
+		raw := strings.TrimSpace(*input.URL)
+		u, err := url.Parse(raw)
+		if err != nil || len(raw) > maxWebhookURL || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+			return fmt.Errorf("%w: url must be an http(s) URL", ErrInvalidWebhook)
+		}
+		if u.Scheme == "http" && !s.Config.WebhookAllowPrivate {
+			return fmt.Errorf("%w: url must use https", ErrInvalidWebhook)
+		}
+		sub.URL = raw
+	}
+	if input.Events != nil {
+		seen := map[string]bool{}
+		events := datatypes.JSONSlice[string]{}
+		for _, e := range input.Events {
+			if !webhooks.IsEvent(e) {
+				return fmt.Errorf("%w: unknown event %q", ErrInvalidWebhook, e)
+			}
+			if !seen[e] {
+				seen[e] = true
+				events = append(events, e)
+			}
+		}
+		if len(events) == 0 {
+			return fmt.Errorf("%w: at least one event is required", ErrInvalidWebhook)
+		}
+		sub.Events = events
+	}
+	if input.Active != nil {
+		sub.Active = *input.Active
+	}
+	if input.RotateSecret {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			return err
+		}
+		sub.Secret = secret
+	}
+	return nil
+}
+
+func newWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
+
+func (s *DefaultWebhookService) ReviewCreated(ctx context.Context, eventID, reviewID uuid.UUID) error {
+	review, err := s.Reviews.GetByID(ctx, reviewID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	review.Status = models.ReviewStatusPending
+	return s.publish(ctx, webhooks.EventReviewCreated, eventID, *review)
+}
+
+func (s *DefaultWebhookService) ReviewStatusChanged(ctx context.Context, eventID, reviewID uuid.UUID, status string) error {
+	review, err := s.Reviews.GetByID(ctx, reviewID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	review.Status = status
+	return s.publish(ctx, webhooks.EventReviewStatusChanged, eventID, *review)
+}
+
+func (s *DefaultWebhookService) publish(ctx context.Context, event string, eventID uuid.UUID, review models.Review) error {
+	data := webhooks.Review{
+		ID:          review.ID,
+		CompanyID:   review.CompanyID,
+		CompanyName: review.Company.Name,
+		Rating:      review.Rating,
+		Status:      review.Status,
+		Anonymous:   review.Anonymous,
+		CreatedAt:   review.CreatedAt,
+	}
+	// Text that moderation and the fraud checks have not cleared stays home.
+	if review.Status == models.ReviewStatusApproved {
+		data.Title = review.Title
+		data.Content = review.Content
+	}
+	if !review.Anonymous {
+		data.Author = review.User.Username
+	}
+	envelope := webhooks.Envelope{
+		ID:        eventID,
+		Type:      event,
+		CreatedAt: time.Now().UTC(),
+		Data:      webhooks.ReviewData{Review: data},
+	}
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+	_, err = s.Webhooks.Enqueue(ctx, review.CompanyID, event, eventID, payload)
+	return err
+}
+
+func (s *DefaultWebhookService) Dispatch(ctx context.Context) (int, error) {
+	deliveries, err := s.Webhooks.Claim(ctx, time.Now(), webhookLease, webhookBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	delivered := 0
+	for i := range deliveries {
+		d := &deliveries[i]
+		s.attempt(ctx, d)
+		if err := s.Webhooks.SaveAttempt(ctx, d); err != nil {
+			return delivered, err
+		}
+		if d.Status == models.WebhookDelivered {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// attempt sends one delivery and records the outcome on it.
+func (s *DefaultWebhookService) attempt(ctx context.Context, d *models.WebhookDelivery) {
+	sub := d.Subscription
+	if sub.DeletedAt.Valid || !sub.Active {
+		d.Status, d.LastError, d.NextAttemptAt = models.WebhookFailed, "subscription removed or paused", nil
+		return
+	}
+	client := s.Client
+	if client == nil {
+		client = webhooks.NewClient(webhookTimeout, s.Config.WebhookAllowPrivate)
+	}
+	d.Attempts++
+	code, err := webhooks.Send(ctx, client, webhooks.Request{
+		URL:        sub.URL,
+		Secret:     sub.Secret,
+		Event:      d.Event,
+		EventID:    d.EventID,
+		DeliveryID: d.ID,
+		Body:       d.Payload,
+	})
+	d.StatusCode = code
+	if err == nil {
+		now := time.Now()
+		d.Status, d.LastError, d.NextAttemptAt, d.DeliveredAt = models.WebhookDelivered, "", nil, &now
+		return
+	}
+	d.LastError = err.Error()
+	if len(d.LastError) > maxWebhookError {
+		d.LastError = d.LastError[:maxWebhookError]
+	}
+	if d.Attempts >= webhookMaxAttempts {
+		d.Status, d.NextAttemptAt = models.WebhookFailed, nil
+		return
+	}
+	next := time.Now().Add(webhooks.Backoff(d.Attempts))
+	d.NextAttemptAt = &next
+}
+
+func (s *DefaultWebhookService) Start(ctx context.Context) {
+	if s.Config.WebhookPollInterval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(s.Config.WebhookPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if _, err := s.Dispatch(ctx); err != nil {
+				slog.ErrorContext(ctx, "webhook dispatch failed", logging.Err(err))
+			}
+		}
+	}()
+}
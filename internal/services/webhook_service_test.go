@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"crowdreview/config"
+	"crowdreview/internal/models"
+	"crowdreview/internal/repository"
+	"crowdreview/internal/webhooks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type mockWebhookRepo struct {
+	subs       []models.WebhookSubscription
+	deliveries []models.WebhookDelivery
+}
+
+func (m *mockWebhookRepo) ListSubscriptions(ctx context.Context, ownerID *uuid.UUID) ([]models.WebhookSubscription, error) {
+	return m.subs, nil
+}
+func (m *mockWebhookRepo) GetSubscription(ctx context.Context, id uuid.UUID) (*models.WebhookSubscription, error) {
+	for i := range m.subs {
+		if m.subs[i].ID == id {
+			sub := m.subs[i]
+			return &sub, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+func (m *mockWebhookRepo) CreateSubscription(ctx context.Context, sub *models.WebhookSubscription) error {
+	sub.ID = uuid.New()
+	m.subs = append(m.subs, *sub)
+	return nil
+}
+func (m *mockWebhookRepo) UpdateSubscription(ctx context.Context, sub *models.WebhookSubscription) error {
+	for i := range m.subs {
+		if m.subs[i].ID == sub.ID {
+			m.subs[i] = *sub
+		}
+	}
+	return nil
+}
+func (m *mockWebhookRepo) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+func (m *mockWebhookRepo) Enqueue(ctx context.Context, companyID uuid.UUID, event string, eventID uuid.UUID, payload []byte) (int64, error) {
+	var n int64
+	now := time.Now()
+	for _, s := range m.subs {
+		if s.CompanyID != nil && *s.CompanyID != companyID {
+			continue
+		}
+		for _, e := range s.Events {
+			if e == event && !m.queued(s.ID, eventID) {
+				m.deliveries = append(m.deliveries, models.WebhookDelivery{
+					Base:           models.Base{ID: uuid.New()},
+					SubscriptionID: s.ID, EventID: eventID, Event: event, Payload: payload,
+					Status: models.WebhookPending, NextAttemptAt: &now,
+				})
+				n++
+			}
+		}
+	}
+	return n, nil
+}
+func (m *mockWebhookRepo) queued(subscriptionID, eventID uuid.UUID) bool {
+	for _, d := range m.deliveries {
+		if d.SubscriptionID == subscriptionID && d.EventID == eventID && d.RedeliveryOf == nil {
+			return true
+		}
+	}
+	return false
+}
+func (m *mockWebhookRepo) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	var due []models.WebhookDelivery
+	for _, d := range m.deliveries {
+		if d.Status == models.WebhookPending && !d.NextAttemptAt.After(now) {
+			sub, _ := m.GetSubscription(ctx, d.SubscriptionID)
+			d.Subscription = *sub
+			due = append(due, d)
+		}
+	}
+	return due, nil
+}
+func (m *mockWebhookRepo) SaveAttempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	for i := range m.deliveries {
+		if m.deliveries[i].ID == delivery.ID {
+			m.deliveries[i] = *delivery
+		}
+	}
+	return nil
+}
+func (m *mockWebhookRepo) ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit, offset int) ([]models.WebhookDelivery, int64, error) {
+	return m.deliveries, int64(len(m.deliveries)), nil
+}
+func (m *mockWebhookRepo) GetDelivery(ctx context.Context, subscriptionID, id uuid.UUID) (*models.WebhookDelivery, error) {
+	for i := range m.deliveries {
+		if m.deliveries[i].ID == id && m.deliveries[i].SubscriptionID == subscriptionID {
+			d := m.deliveries[i]
+			return &d, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+func (m *mockWebhookRepo) CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	delivery.ID = uuid.New()
+	m.deliveries = append(m.deliveries, *delivery)
+	return nil
+}
+
+type mockCompanyRepo struct {
+	repository.CompanyRepository
+	companies map[uuid.UUID]*models.Company
+}
+
+func (m *mockCompanyRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Company, error) {
+	if c, ok := m.companies[id]; ok {
+		return c, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func TestWebhookSubscriptionAccess(t *testing.T) {
+	ctx := context.Background()
+	owner := Viewer{UserID: uuid.New(), Role: models.RoleUser}
+	company := &models.Company{Name: "Acme", OwnerID: &owner.UserID}
+	company.ID = uuid.New()
+	repo := &mockWebhookRepo{}
+	service := &DefaultWebhookService{
+		Webhooks:  repo,
+		Companies: &mockCompanyRepo{companies: map[uuid.UUID]*models.Company{company.ID: company}},
+	}
+	hook := "https://partner.example/hooks"
+	input := WebhookInput{CompanyID: &company.ID, URL: &hook, Events: []string{webhooks.EventReviewCreated}}
+
+	sub, err := service.Create(ctx, owner, input)
+	require.NoError(t, err)
+	require.Contains(t, sub.Secret, "whsec_")
+
+	stranger := Viewer{UserID: uuid.New(), Role: models.RoleUser}
+	_, err = service.Create(ctx, stranger, input)
+	require.ErrorIs(t, err, ErrWebhookForbidden)
+	_, err = service.Deliveries(ctx, stranger, sub.ID, 0, 0)
+	require.ErrorIs(t, err, ErrWebhookNotFound, "other companies' webhooks are not revealed")
+
+	_, err = service.Create(ctx, owner, WebhookInput{URL: &hook, Events: input.Events})
+	require.ErrorIs(t, err, ErrWebhookForbidden, "only webhooks.manage subscribes to every company")
+	admin := Viewer{UserID: uuid.New(), Role: models.RoleAdmin}
+	_, err = service.Create(ctx, admin, WebhookInput{URL: &hook, Events: input.Events})
+	require.NoError(t, err)
+
+	service.Config.RequireAdmin2FA = true
+	_, err = service.Create(ctx, admin, WebhookInput{URL: &hook, Events: input.Events})
+	require.ErrorIs(t, err, ErrWebhookForbidden, "webhooks.manage needs the second factor")
+	_, err = service.Deliveries(ctx, admin, sub.ID, 0, 0)
+	require.ErrorIs(t, err, ErrWebhookNotFound)
+	admin.MFA = true
+	_, err = service.Create(ctx, admin, WebhookInput{URL: &hook, Events: input.Events})
+	require.NoError(t, err)
+	_, err = service.Create(ctx, owner, input)
+	require.NoError(t, err, "owners do not need the second factor")
+	service.Config.RequireAdmin2FA = false
+
+	insecure := "http://partner.example/hooks"
+	_, err = service.Create(ctx, owner, WebhookInput{CompanyID: &company.ID, URL: &insecure, Events: input.Events})
+	require.ErrorIs(t, err, ErrInvalidWebhook)
+	_, err = service.Update(ctx, owner, sub.ID, WebhookInput{Events: []string{"review.deleted"}})
+	require.ErrorIs(t, err, ErrInvalidWebhook)
+}
+
+func TestWebhookDeliveryRetriesAndRedelivery(t *testing.T) {
+	ctx := context.Background()
+	var secret string
+	var bodies [][]byte
+	var signatures []bool
+	status := http.StatusInternalServerError
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ts, _ := strconv.ParseInt(r.Header.Get(webhooks.HeaderTimestamp), 10, 64)
+		bodies = append(bodies, body)
+		signatures = append(signatures, webhooks.Verify(secret, ts, body, r.Header.Get(webhooks.HeaderSignature)))
+		w.WriteHeader(status)
+	}))
+	defer receiver.Close()
+
+	company := &models.Company{Name: "Acme"}
+	company.ID = uuid.New()
+	author := models.User{Username: "ana"}
+	author.ID = uuid.New()
+	review := &models.Review{UserID: author.ID, User: author, CompanyID: company.ID, Company: *company, Rating: 4, Status: models.ReviewStatusPending}
+	review.ID = uuid.New()
+	repo := &mockWebhookRepo{}
+	service := &DefaultWebhookService{
+		Webhooks:  repo,
+		Companies: &mockCompanyRepo{companies: map[uuid.UUID]*models.Company{company.ID: company}},
+		Reviews:   &mockReviewRepo{reviews: map[uuid.UUID]*models.Review{review.ID: review}},
+		Config:    config.Config{WebhookAllowPrivate: true},
+	}
+	admin := Viewer{UserID: uuid.New(), Role: models.RoleAdmin}
+	sub, err := service.Create(ctx, admin, WebhookInput{
+		CompanyID: &company.ID,
+		URL:       &receiver.URL,
+		Events:    []string{webhooks.EventReviewStatusChanged},
+	})
+	require.NoError(t, err)
+	secret = sub.Secret
+
+	eventID := uuid.New()
+	require.NoError(t, service.ReviewCreated(ctx, uuid.New(), review.ID)) // not subscribed
+	require.NoError(t, service.ReviewStatusChanged(ctx, eventID, review.ID, models.ReviewStatusApproved))
+	require.NoError(t, service.ReviewStatusChanged(ctx, eventID, review.ID, models.ReviewStatusApproved), "relayed twice")
+	require.Len(t, repo.deliveries, 1)
+
+	delivered, err := service.Dispatch(ctx)
+	require.NoError(t, err)
+	require.Zero(t, delivered)
+	failed := repo.deliveries[0]
+	require.Equal(t, models.WebhookPending, failed.Status)
+	require.Equal(t, 1, failed.Attempts)
+	require.Equal(t, http.StatusInternalServerError, failed.StatusCode)
+	require.WithinDuration(t, time.Now().Add(webhooks.Backoff(1)), *failed.NextAttemptAt, 5*time.Second)
+
+	// Not due yet.
+	delivered, err = service.Dispatch(ctx)
+	require.NoError(t, err)
+	require.Zero(t, delivered)
+	require.Len(t, bodies, 1)
+
+	status = http.StatusOK
+	past := time.Now().Add(-time.Second)
+	repo.deliveries[0].NextAttemptAt = &past
+	delivered, err = service.Dispatch(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, delivered)
+	require.Equal(t, models.WebhookDelivered, repo.deliveries[0].Status)
+	require.Equal(t, []bool{true, true}, signatures)
+
+	var envelope struct {
+		ID   uuid.UUID           `json:"id"`
+		Type string              `json:"type"`
+		Data webhooks.ReviewData `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(bodies[1], &envelope))
+	require.Equal(t, eventID, envelope.ID)
+	require.Equal(t, webhooks.EventReviewStatusChanged, envelope.Type)
+	require.Equal(t, models.ReviewStatusApproved, envelope.Data.Review.Status)
+	require.Equal(t, "ana", envelope.Data.Review.Author)
+
+	again, err := service.Redeliver(ctx, admin, sub.ID, repo.deliveries[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, envelope.ID, again.EventID)
+	delivered, err = service.Dispatch(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, delivered)
+	require.Equal(t, bodies[1], bodies[2])
+}
+
+func TestWebhookPayloadHoldsTextUntilApproved(t *testing.T) {
+	ctx := context.Background()
+	company := &models.Company{Name: "Acme"}
+	company.ID = uuid.New()
+	review := &models.Review{CompanyID: company.ID, Company: *company, Rating: 2, Title: "Atraso", Content: "Entrega atrasou", Status: models.ReviewStatusPending}
+	review.ID = uuid.New()
+	repo := &mockWebhookRepo{}
+	service := &DefaultWebhookService{
+		Webhooks:  repo,
+		Companies: &mockCompanyRepo{companies: map[uuid.UUID]*models.Company{company.ID: company}},
+		Reviews:   &mockReviewRepo{reviews: map[uuid.UUID]*models.Review{review.ID: review}},
+		Config:    config.Config{WebhookAllowPrivate: true},
+	}
+	admin := Viewer{UserID: uuid.New(), Role: models.RoleAdmin}
+	url := "https://partner.example/hooks"
+	_, err := service.Create(ctx, admin, WebhookInput{
+		CompanyID: &company.ID,
+		URL:       &url,
+		Events:    []string{webhooks.EventReviewCreated, webhooks.EventReviewStatusChanged},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, service.ReviewCreated(ctx, uuid.New(), review.ID))
+	require.NoError(t, service.ReviewStatusChanged(ctx, uuid.New(), review.ID, models.ReviewStatusFlagged))
+	require.NoError(t, service.ReviewStatusChanged(ctx, uuid.New(), review.ID, models.ReviewStatusApproved))
+	require.Len(t, repo.deliveries, 3)
+
+	var sent []webhooks.Review
+	for _, d := range repo.deliveries {
+		var envelope struct {
+			Data webhooks.ReviewData `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(d.Payload, &envelope))
+		sent = append(sent, envelope.Data.Review)
+	}
+	require.Empty(t, sent[0].Title)
+	require.Empty(t, sent[0].Content)
+	require.NotContains(t, string(repo.deliveries[0].Payload), "Entrega atrasou")
+	require.Empty(t, sent[1].Content)
+	require.Equal(t, "Atraso", sent[2].Title)
+	require.Equal(t, "Entrega atrasou", sent[2].Content)
+}
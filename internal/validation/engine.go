@@ -2,14 +2,18 @@ package validation
 
 import (
 	"crowdreview/internal/models"
+	"crowdreview/internal/reputation"
 	"crowdreview/internal/rules"
 )
 
 // FraudEngine aggregates rule scores into a final confidence metric.
-type FraudEngine struct{}
+// Reviews by authors in trusted reputation tiers get the tier's bonus.
+type FraudEngine struct {
+	Tiers reputation.Tiers
+}
 
-func NewFraudEngine() *FraudEngine {
-	return &FraudEngine{}
+func NewFraudEngine(tiers reputation.Tiers) *FraudEngine {
+	return &FraudEngine{Tiers: tiers}
 }
 
 // Evaluate runs all rules and returns a validation result populated with signals.
@@ -32,6 +36,14 @@ func (f *FraudEngine) Evaluate(review models.Review) (models.ReviewValidationRes
 		}
 	}
 
+	// review.User is only set when the author was loaded with the review.
+	tier := f.Tiers.For(review.User.GamificationScore)
+	score += tier.TrustBonus
+	checks["reputation"] = map[string]interface{}{
+		"tier":  tier.Name,
+		"bonus": tier.TrustBonus,
+	}
+
 	if score < 0 {
 		score = 0
 	}
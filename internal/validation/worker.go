@@ -2,16 +2,26 @@ package validation
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"time"
 
 	"crowdreview/internal/models"
 	"crowdreview/internal/repository"
+	"crowdreview/pkg/logging"
 )
 
+// FraudJob is a review waiting in the queue; Done receives the outcome of
+// its validation. Ctx is the submitter's context, whose log attributes,
+// such as the request ID, the validation logs carry.
+type FraudJob struct {
+	Ctx    context.Context
+	Review models.Review
+	Done   chan error
+}
+
 // FraudWorker consumes the fraud-validation-queue asynchronously.
 type FraudWorker struct {
-	Queue      chan models.Review
+	Queue      chan FraudJob
 	Engine     *FraudEngine
 	Validation repository.ValidationRepository
 }
@@ -21,45 +31,74 @@ const FraudQueueName = "fraud-validation-queue"
 
 func NewFraudWorker(engine *FraudEngine, validationRepo repository.ValidationRepository) *FraudWorker {
 	return &FraudWorker{
-		Queue:      make(chan models.Review, 100),
+		Queue:      make(chan FraudJob, 100),
 		Engine:     engine,
 		Validation: validationRepo,
 	}
 }
 
-// Enqueue pushes a review for validation without blocking the request lifecycle.
-func (w *FraudWorker) Enqueue(review models.Review) {
+// Submit queues a review and waits until it has been validated, so the
+// caller can retry on failure. Reviews that are no longer pending, for
+// instance because they were submitted twice, are left untouched.
+func (w *FraudWorker) Submit(ctx context.Context, review models.Review) error {
+	done := make(chan error, 1)
+	select {
+	case w.Queue <- FraudJob{Ctx: ctx, Review: review, Done: done}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 	select {
-	case w.Queue <- review:
-	default:
-		log.Println("fraud-validation-queue is full, dropping review", review.ID)
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
 // Start begins processing the queue. Should run in a goroutine.
 func (w *FraudWorker) Start() {
 	go func() {
-		for review := range w.Queue {
-			w.process(review)
+		for job := range w.Queue {
+			job.Done <- w.process(job.Ctx, job.Review)
 		}
 	}()
 }
 
-func (w *FraudWorker) process(review models.Review) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// process validates the review. It keeps the job context's values but not
+// its deadline, so a submitter giving up does not interrupt the writes.
+func (w *FraudWorker) process(jobCtx context.Context, review models.Review) error {
+	if jobCtx == nil {
+		jobCtx = context.Background()
+	}
+	if review.Status != models.ReviewStatusPending {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(jobCtx), 5*time.Second)
 	defer cancel()
+	ctx = logging.With(ctx, slog.String("queue", FraudQueueName))
 
 	result, suspicious := w.Engine.Evaluate(review)
 	if err := w.Validation.SaveResult(ctx, &result); err != nil {
-		log.Printf("failed to save validation result: %v", err)
-		return
+		slog.ErrorContext(ctx, "failed to save validation result", logging.Err(err))
+		return err
 	}
 
-	status := "approved"
+	status := models.ReviewStatusApproved
 	if suspicious {
-		status = "flagged"
+		status = models.ReviewStatusFlagged
+	}
+	marked, err := w.Validation.MarkReview(ctx, review.ID, result.ID, status, suspicious)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to mark review", logging.Err(err))
+		return err
 	}
-	if err := w.Validation.MarkReview(ctx, review.ID, result.ID, status, suspicious); err != nil {
-		log.Printf("failed to mark review: %v", err)
+	if !marked {
+		slog.InfoContext(ctx, "review was moderated before validation finished, keeping its status")
+		return nil
 	}
+	slog.InfoContext(ctx, "review validated",
+		slog.String("status", status),
+		slog.Float64("score", result.Score),
+		slog.String("outcome", result.Outcome))
+	return nil
 }
@@ -0,0 +1,62 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"crowdreview/internal/models"
+	"crowdreview/internal/reputation"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeValidationRepo keeps one result per review, like the review_id
+// unique index, and replaces a result no review points at.
+type fakeValidationRepo struct {
+	results  map[uuid.UUID]models.ReviewValidationResult // by review ID
+	marked   map[uuid.UUID]uuid.UUID                     // review ID to result ID
+	failMark bool
+}
+
+func (f *fakeValidationRepo) SaveResult(ctx context.Context, result *models.ReviewValidationResult) error {
+	if old, ok := f.results[result.ReviewID]; ok && f.marked[result.ReviewID] == old.ID {
+		return errors.New("duplicate key value violates unique constraint")
+	}
+	result.ID = uuid.New()
+	f.results[result.ReviewID] = *result
+	return nil
+}
+
+func (f *fakeValidationRepo) MarkReview(ctx context.Context, reviewID uuid.UUID, resultID uuid.UUID, status string, suspicious bool) (bool, error) {
+	if f.failMark {
+		return false, errors.New("connection reset")
+	}
+	f.marked[reviewID] = resultID
+	return true, nil
+}
+
+func TestRetriedValidationReplacesInterruptedResult(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeValidationRepo{
+		results:  map[uuid.UUID]models.ReviewValidationResult{},
+		marked:   map[uuid.UUID]uuid.UUID{},
+		failMark: true,
+	}
+	worker := NewFraudWorker(NewFraudEngine(reputation.MustParseTiers(reputation.DefaultTiers)), repo)
+	worker.Start()
+	review := models.Review{Status: models.ReviewStatusPending, Rating: 4, Title: "Bom atendimento", Content: "Resolveram meu problema no mesmo dia."}
+	review.ID = uuid.New()
+
+	// The run crashes after saving its result but before marking the review.
+	require.Error(t, worker.Submit(ctx, review))
+	interrupted := repo.results[review.ID].ID
+	require.NotEqual(t, uuid.Nil, interrupted)
+
+	repo.failMark = false
+	require.NoError(t, worker.Submit(ctx, review))
+	result := repo.results[review.ID]
+	require.NotEqual(t, interrupted, result.ID, "the retry replaces the interrupted result")
+	require.Equal(t, result.ID, repo.marked[review.ID])
+}
@@ -0,0 +1,190 @@
+// Package webhooks signs and sends the events CrowdReview publishes to
+// partner systems.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types.
+const (
+	EventReviewCreated       = "review.created"
+	EventReviewStatusChanged = "review.status_changed"
+)
+
+// Events lists every event a subscription can receive.
+var Events = []string{EventReviewCreated, EventReviewStatusChanged}
+
+// IsEvent reports whether name is a known event type.
+func IsEvent(name string) bool {
+	for _, e := range Events {
+		if e == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Request headers. The signature is "sha256=" followed by the hex
+// HMAC-SHA256 of "<timestamp>.<body>" keyed by the subscription secret.
+const (
+	HeaderEvent     = "X-CrowdReview-Event"
+	HeaderEventID   = "X-CrowdReview-Event-ID"
+	HeaderDelivery  = "X-CrowdReview-Delivery"
+	HeaderTimestamp = "X-CrowdReview-Timestamp"
+	HeaderSignature = "X-CrowdReview-Signature"
+)
+
+// Envelope is the JSON body of every webhook. Receivers should deduplicate
+// by ID: retries and redeliveries repeat it.
+type Envelope struct {
+	ID        uuid.UUID   `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// ReviewData is the data of review events; Review.Status is the status
+// after the change. Anonymous reviews carry no author, and only approved
+// reviews carry their title and content.
+type ReviewData struct {
+	Review Review `json:"review"`
+}
+
+// Review is a review as sent to partners, without moderation data.
+type Review struct {
+	ID          uuid.UUID `json:"id"`
+	CompanyID   uuid.UUID `json:"company_id"`
+	CompanyName string    `json:"company_name"`
+	Rating      int       `json:"rating"`
+	Title       string    `json:"title,omitempty"`
+	Content     string    `json:"content,omitempty"`
+	Status      string    `json:"status"`
+	Anonymous   bool      `json:"anonymous"`
+	Author      string    `json:"author,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Sign computes the signature header value for body sent at timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature header in constant time.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+const (
+	backoffBase = 30 * time.Second
+	backoffMax  = 6 * time.Hour
+)
+
+// Backoff is the wait before retrying after the given number of failed
+// attempts: 30s, 1m, 2m, ... capped at 6h.
+func Backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	d := backoffBase
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= backoffMax {
+			return backoffMax
+		}
+	}
+	return d
+}
+
+// Request is one attempt to deliver an event.
+type Request struct {
+	URL        string
+	Secret     string
+	Event      string
+	EventID    uuid.UUID
+	DeliveryID uuid.UUID
+	Body       []byte
+}
+
+// maxResponseBody bounds how much of a receiver's response is read.
+const maxResponseBody = 64 << 10
+
+// Send posts the signed request and returns the response status. Any
+// non-2xx status is an error.
+func Send(ctx context.Context, client *http.Client, r Request) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.URL, bytes.NewReader(r.Body))
+	if err != nil {
+		return 0, err
+	}
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "CrowdReview-Webhooks/1.0")
+	req.Header.Set(HeaderEvent, r.Event)
+	req.Header.Set(HeaderEventID, r.EventID.String())
+	req.Header.Set(HeaderDelivery, r.DeliveryID.String())
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, Sign(r.Secret, ts, r.Body))
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver answered %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// ErrPrivateAddress is returned when a webhook URL resolves to a loopback,
+// private or link-local address and those are not allowed.
+var ErrPrivateAddress = errors.New("webhook address is not public")
+
+// NewClient returns the HTTP client used for deliveries. Unless
+// allowPrivate is set it refuses to connect to non-public addresses, so
+// subscriptions cannot reach internal services.
+func NewClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+				ip.IsLinkLocalMulticast() || ip.IsUnspecified() || ip.IsMulticast() {
+				return ErrPrivateAddress
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,65 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendSignsRequests(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	req := Request{
+		URL:        server.URL,
+		Secret:     "s3cret",
+		Event:      EventReviewCreated,
+		EventID:    uuid.New(),
+		DeliveryID: uuid.New(),
+		Body:       []byte(`{"type":"review.created"}`),
+	}
+	code, err := Send(context.Background(), NewClient(time.Second, true), req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, code)
+	require.Equal(t, req.Body, body)
+	require.Equal(t, EventReviewCreated, received.Header.Get(HeaderEvent))
+	require.Equal(t, req.EventID.String(), received.Header.Get(HeaderEventID))
+	ts, err := strconv.ParseInt(received.Header.Get(HeaderTimestamp), 10, 64)
+	require.NoError(t, err)
+	require.True(t, Verify("s3cret", ts, body, received.Header.Get(HeaderSignature)))
+	require.False(t, Verify("other", ts, body, received.Header.Get(HeaderSignature)))
+
+	status = http.StatusBadGateway
+	code, err = Send(context.Background(), NewClient(time.Second, true), req)
+	require.Error(t, err)
+	require.Equal(t, http.StatusBadGateway, code)
+}
+
+func TestClientRefusesPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	_, err := Send(context.Background(), NewClient(time.Second, false), Request{URL: server.URL})
+	require.ErrorIs(t, err, ErrPrivateAddress)
+}
+
+func TestBackoff(t *testing.T) {
+	require.Equal(t, 30*time.Second, Backoff(1))
+	require.Equal(t, time.Minute, Backoff(2))
+	require.Equal(t, 4*time.Minute, Backoff(4))
+	require.Equal(t, 6*time.Hour, Backoff(20))
+}
@@ -0,0 +1,105 @@
+// Package logging configures structured JSON logging with log/slog and
+// carries request-scoped attributes, such as the request ID, in contexts,
+// so that every record logged with that context is correlated.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// RequestIDKey is the attribute holding the ID of the originating request.
+const RequestIDKey = "request_id"
+
+type ctxKey struct{}
+
+// fields are the request-scoped attributes stored in a context.
+type fields struct {
+	requestID string
+	attrs     []slog.Attr
+}
+
+func fromContext(ctx context.Context) fields {
+	if ctx == nil {
+		return fields{}
+	}
+	f, _ := ctx.Value(ctxKey{}).(fields)
+	return f
+}
+
+// With returns a copy of ctx whose log records also carry attrs.
+func With(ctx context.Context, attrs ...slog.Attr) context.Context {
+	f := fromContext(ctx)
+	f.attrs = append(append([]slog.Attr{}, f.attrs...), attrs...)
+	return context.WithValue(ctx, ctxKey{}, f)
+}
+
+// WithRequestID returns a copy of ctx tagged with the request ID. An empty
+// id leaves ctx unchanged.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	ctx = With(ctx, slog.String(RequestIDKey, id))
+	f := fromContext(ctx)
+	f.requestID = id
+	return context.WithValue(ctx, ctxKey{}, f)
+}
+
+// RequestID returns the request ID ctx was tagged with, if any.
+func RequestID(ctx context.Context) string {
+	return fromContext(ctx).requestID
+}
+
+// contextHandler adds the attributes stored in the record's context.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	r.AddAttrs(fromContext(ctx).attrs...)
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// New returns a JSON logger writing records at or above level to w.
+func New(w io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(contextHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})})
+}
+
+// Setup installs a JSON logger on stderr at the named level as the default
+// for both log/slog and the standard log package.
+func Setup(level string) *slog.Logger {
+	logger := New(os.Stderr, ParseLevel(level))
+	slog.SetDefault(logger)
+	return logger
+}
+
+// ParseLevel reads debug, info, warn or error, defaulting to info.
+func ParseLevel(s string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// Err is the conventional attribute for an error.
+func Err(err error) slog.Attr {
+	return slog.Any("error", err)
+}
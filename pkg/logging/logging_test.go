@@ -0,0 +1,37 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextAttributesAreLogged(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, slog.LevelInfo)
+	ctx := WithRequestID(context.Background(), "req-1")
+	ctx = With(ctx, slog.String("review_id", "r-1"))
+
+	logger.InfoContext(ctx, "review validated", "status", "approved")
+	logger.DebugContext(ctx, "hidden")
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	require.Equal(t, "review validated", record["msg"])
+	require.Equal(t, "req-1", record[RequestIDKey])
+	require.Equal(t, "r-1", record["review_id"])
+	require.Equal(t, "approved", record["status"])
+
+	require.Equal(t, "req-1", RequestID(ctx))
+	require.Empty(t, RequestID(context.Background()))
+	require.Equal(t, context.Background(), WithRequestID(context.Background(), ""))
+}
+
+func TestParseLevel(t *testing.T) {
+	require.Equal(t, slog.LevelDebug, ParseLevel("DEBUG"))
+	require.Equal(t, slog.LevelWarn, ParseLevel("warn"))
+	require.Equal(t, slog.LevelInfo, ParseLevel("verbose"))
+}
@@ -2,18 +2,60 @@ package middleware
 
 import (
 	"net/http"
+	"strings"
 
+	"crowdreview/config"
 	"crowdreview/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-// AdminRequired ensures user role is admin.
-func AdminRequired() gin.HandlerFunc {
+// RequirePermission ensures the access token grants every given admin
+// permission. Permissions are embedded in the token at login, so changes
+// apply once the client refreshes. With cfg.RequireAdmin2FA the token must
+// also come from a login that passed a second factor.
+func RequirePermission(cfg config.Config, perms ...string) gin.HandlerFunc {
+	return requireAdmin(cfg, func(tc *utils.TokenClaims) string {
+		for _, perm := range perms {
+			if !tc.HasPermission(perm) {
+				return "missing permission " + perm
+			}
+		}
+		return ""
+	})
+}
+
+// RequireAnyPermission is RequirePermission for routes open to holders of
+// any one of perms, such as feeds filtered per permission downstream.
+func RequireAnyPermission(cfg config.Config, perms ...string) gin.HandlerFunc {
+	return requireAdmin(cfg, func(tc *utils.TokenClaims) string {
+		for _, perm := range perms {
+			if tc.HasPermission(perm) {
+				return ""
+			}
+		}
+		return "missing permission " + strings.Join(perms, " or ")
+	})
+}
+
+// requireAdmin aborts with 403 unless the token passes check, which returns
+// the reason it does not, and the second factor rule.
+func requireAdmin(cfg config.Config, check func(*utils.TokenClaims) string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		role, exists := c.Get("role")
-		if !exists || role != "admin" {
-			utils.JSONError(c, http.StatusForbidden, "admin only")
+		claims, _ := c.Get("claims")
+		tc, ok := claims.(*utils.TokenClaims)
+		if !ok {
+			utils.JSONError(c, http.StatusForbidden, "permission denied")
+			c.Abort()
+			return
+		}
+		if reason := check(tc); reason != "" {
+			utils.JSONError(c, http.StatusForbidden, reason)
+			c.Abort()
+			return
+		}
+		if cfg.RequireAdmin2FA && !tc.MFA {
+			utils.JSONError(c, http.StatusForbidden, "two-factor authentication required")
 			c.Abort()
 			return
 		}
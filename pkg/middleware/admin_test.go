@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"crowdreview/config"
+	"crowdreview/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminPermissionChecks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	status := func(guard gin.HandlerFunc, claims *utils.TokenClaims) int {
+		r := gin.New()
+		r.GET("/", func(c *gin.Context) {
+			c.Set("claims", claims)
+			c.Next()
+		}, guard, func(c *gin.Context) {
+			c.Status(http.StatusNoContent)
+		})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		return w.Code
+	}
+	cfg := config.Config{RequireAdmin2FA: true}
+	moderator := &utils.TokenClaims{Permissions: []string{"reviews.moderate"}, MFA: true}
+
+	require.Equal(t, http.StatusNoContent, status(RequirePermission(cfg, "reviews.moderate"), moderator))
+	require.Equal(t, http.StatusForbidden, status(RequirePermission(cfg, "reviews.moderate", "users.manage"), moderator))
+	require.Equal(t, http.StatusNoContent, status(RequireAnyPermission(cfg, "reviews.moderate", "users.manage"), moderator))
+	require.Equal(t, http.StatusForbidden, status(RequireAnyPermission(cfg, "users.manage"), moderator))
+
+	noMFA := &utils.TokenClaims{Permissions: moderator.Permissions}
+	require.Equal(t, http.StatusForbidden, status(RequirePermission(cfg, "reviews.moderate"), noMFA))
+	require.Equal(t, http.StatusForbidden, status(RequireAnyPermission(cfg, "reviews.moderate", "users.manage"), noMFA))
+	require.Equal(t, http.StatusNoContent, status(RequireAnyPermission(config.Config{}, "reviews.moderate"), noMFA))
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
@@ -11,34 +12,73 @@ import (
 	"github.com/google/uuid"
 )
 
-// AuthRequired ensures a valid access token is present.
-func AuthRequired(cfg config.Config) gin.HandlerFunc {
+var (
+	errMissingToken        = errors.New("missing token")
+	errDenylistUnavailable = errors.New("token revocation check unavailable")
+)
+
+// AuthRequired ensures a valid, non-revoked access token is present.
+func AuthRequired(cfg config.Config, keys *utils.KeySet, denylist *utils.TokenDenylist) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
-			utils.JSONError(c, http.StatusUnauthorized, "missing token")
-			c.Abort()
-			return
-		}
-		token := strings.TrimPrefix(authHeader, "Bearer ")
-		claims, err := utils.ParseToken(token, cfg.JWTSecret)
-		if err != nil {
-			utils.JSONError(c, http.StatusUnauthorized, "invalid token")
-			c.Abort()
+		if err := authenticate(c, cfg, keys, denylist); err != nil {
+			abortAuth(c, err)
 			return
 		}
-		userID, err := uuid.Parse(claims.Subject)
-		if err != nil {
-			utils.JSONError(c, http.StatusUnauthorized, "invalid token subject")
-			c.Abort()
+		c.Next()
+	}
+}
+
+// OptionalAuth identifies the caller when a valid access token is present but
+// lets anonymous requests through, for endpoints whose output depends on who
+// is asking.
+func OptionalAuth(cfg config.Config, keys *utils.KeySet, denylist *utils.TokenDenylist) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := authenticate(c, cfg, keys, denylist); err != nil && !errors.Is(err, errMissingToken) {
+			abortAuth(c, err)
 			return
 		}
-		role := claims.Role
-		if role == "" {
-			role = "user"
-		}
-		c.Set("userID", userID)
-		c.Set("role", role)
 		c.Next()
 	}
 }
+
+func abortAuth(c *gin.Context, err error) {
+	status := http.StatusUnauthorized
+	if errors.Is(err, errDenylistUnavailable) {
+		status = http.StatusServiceUnavailable
+	}
+	utils.JSONError(c, status, err.Error())
+	c.Abort()
+}
+
+// authenticate parses the bearer token, rejects revoked tokens and stores
+// userID, role and the token claims on the context.
+func authenticate(c *gin.Context, cfg config.Config, keys *utils.KeySet, denylist *utils.TokenDenylist) error {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
+		return errMissingToken
+	}
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+	claims, err := utils.ParseToken(token, keys, cfg.JWTIssuer, cfg.JWTAudience)
+	if err != nil {
+		return errors.New("invalid token")
+	}
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return errors.New("invalid token subject")
+	}
+	revoked, err := denylist.IsRevoked(c.Request.Context(), claims)
+	if err != nil {
+		return errDenylistUnavailable
+	}
+	if revoked {
+		return errors.New("token revoked")
+	}
+	role := claims.Role
+	if role == "" {
+		role = "user"
+	}
+	c.Set("userID", userID)
+	c.Set("role", role)
+	c.Set("claims", claims)
+	return nil
+}
@@ -19,7 +19,8 @@ func CORSMiddleware() gin.HandlerFunc {
 		h := c.Writer.Header()
 		h.Set("Access-Control-Allow-Origin", allowedOrigin)
 		h.Set("Access-Control-Allow-Credentials", "true")
-		h.Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Accept, X-Requested-With")
+		h.Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Accept, X-Requested-With, X-Request-ID")
+		h.Set("Access-Control-Expose-Headers", "X-Request-ID")
 		h.Set("Access-Control-Allow-Methods", "GET,POST,PUT,PATCH,DELETE,OPTIONS")
 
 		if c.Request.Method == http.MethodOptions {
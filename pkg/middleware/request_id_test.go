@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"crowdreview/pkg/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var seen string
+	r := gin.New()
+	r.Use(RequestID())
+	r.GET("/", func(c *gin.Context) {
+		seen = logging.RequestID(c.Request.Context())
+		c.Status(http.StatusNoContent)
+	})
+	serve := func(id string) *httptest.ResponseRecorder {
+		seen = ""
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if id != "" {
+			req.Header.Set(HeaderRequestID, id)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("client-42.retry:1")
+	require.Equal(t, "client-42.retry:1", w.Header().Get(HeaderRequestID))
+	require.Equal(t, "client-42.retry:1", seen)
+
+	for _, id := range []string{"", "bad id\r\nX-Injected: 1", strings.Repeat("a", maxRequestID+1)} {
+		w = serve(id)
+		got := w.Header().Get(HeaderRequestID)
+		_, err := uuid.Parse(got)
+		require.NoError(t, err, "replaces %q", id)
+		require.Equal(t, got, seen)
+	}
+}
@@ -1,20 +1,46 @@
 package middleware
 
 import (
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
-// RequestLogger logs basic request metadata and latency.
+// RequestLogger logs one structured record per request with its status and
+// latency; server errors are logged at error level and client errors at
+// warn. Run it after RequestID so the record carries the request ID.
 func RequestLogger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
 		c.Next()
-		latency := time.Since(start)
 		status := c.Writer.Status()
-		log.Printf("%s %s %d %s", c.Request.Method, path, status, latency)
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("bytes", c.Writer.Size()),
+		}
+		if id, ok := c.Get("userID"); ok {
+			if userID, ok := id.(uuid.UUID); ok {
+				attrs = append(attrs, slog.String("user_id", userID.String()))
+			}
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+		slog.LogAttrs(c.Request.Context(), level, "request", attrs...)
 	}
 }
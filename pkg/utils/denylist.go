@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// TokenDenylist revokes access tokens before they expire. Entries live in
+// Redis only as long as the tokens they cover. A nil client disables
+// revocation checks, matching how the API runs without Redis.
+type TokenDenylist struct {
+	client *redis.Client
+}
+
+func NewTokenDenylist(client *redis.Client) *TokenDenylist {
+	return &TokenDenylist{client: client}
+}
+
+func denylistTokenKey(id string) string { return "auth:revoked:token:" + id }
+
+func denylistUserKey(id string) string { return "auth:revoked:user:" + id }
+
+// Revoke denies a single access token until it expires.
+func (d *TokenDenylist) Revoke(ctx context.Context, claims *TokenClaims) error {
+	if d == nil || d.client == nil || claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+	ttl := time.Until(claims.ExpiresAt.Time)
+	if ttl <= 0 {
+		return nil
+	}
+	return d.client.Set(ctx, denylistTokenKey(claims.ID), 1, ttl).Err()
+}
+
+// RevokeUser denies every access token of the user issued up to now, in
+// milliseconds. The marker outlives the longest possible access token by ttl.
+func (d *TokenDenylist) RevokeUser(ctx context.Context, userID uuid.UUID, ttl time.Duration) error {
+	if d == nil || d.client == nil {
+		return nil
+	}
+	return d.client.Set(ctx, denylistUserKey(userID.String()), time.Now().UnixMilli(), ttl).Err()
+}
+
+// legacyRevocation bounds markers written in seconds by earlier releases;
+// millisecond timestamps are far above it.
+const legacyRevocation = 1e11
+
+// IsRevoked reports whether the token was revoked on its own or by a
+// user-wide revocation after it was issued.
+func (d *TokenDenylist) IsRevoked(ctx context.Context, claims *TokenClaims) (bool, error) {
+	if d == nil || d.client == nil {
+		return false, nil
+	}
+	values, err := d.client.MGet(ctx, denylistTokenKey(claims.ID), denylistUserKey(claims.Subject)).Result()
+	if err != nil {
+		return false, err
+	}
+	if values[0] != nil {
+		return true, nil
+	}
+	if raw, ok := values[1].(string); ok && claims.IssuedAt != nil {
+		revokedAt, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return false, err
+		}
+		if revokedAt < legacyRevocation {
+			revokedAt *= 1000
+		}
+		return claims.IssuedAt.UnixMilli() <= revokedAt, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevokeUserCoversTokensOfTheSameSecond(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	denylist := NewTokenDenylist(redis.NewClient(&redis.Options{Addr: server.Addr()}))
+	userID := uuid.New()
+	claims := func(issued time.Time) *TokenClaims {
+		// Round-trip through JSON as a parsed token would.
+		raw, err := jwt.NewNumericDate(issued).MarshalJSON()
+		require.NoError(t, err)
+		var iat jwt.NumericDate
+		require.NoError(t, iat.UnmarshalJSON(raw))
+		return &TokenClaims{RegisteredClaims: jwt.RegisteredClaims{
+			ID: uuid.NewString(), Subject: userID.String(), IssuedAt: &iat,
+		}}
+	}
+
+	before := claims(time.Now())
+	require.NoError(t, denylist.RevokeUser(ctx, userID, time.Minute))
+	revoked, err := denylist.IsRevoked(ctx, before)
+	require.NoError(t, err)
+	require.True(t, revoked, "issued just before the revocation")
+
+	after := claims(time.Now().Add(5 * time.Millisecond))
+	revoked, err = denylist.IsRevoked(ctx, after)
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	// Markers written in seconds before the switch still apply.
+	legacy := time.Now().Add(-time.Second).Truncate(time.Second)
+	server.Set("auth:revoked:user:"+userID.String(), strconv.FormatInt(legacy.Unix(), 10))
+	revoked, err = denylist.IsRevoked(ctx, claims(legacy.Add(-time.Second)))
+	require.NoError(t, err)
+	require.True(t, revoked)
+	revoked, err = denylist.IsRevoked(ctx, claims(time.Now()))
+	require.NoError(t, err)
+	require.False(t, revoked)
+}
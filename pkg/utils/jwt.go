@@ -1,6 +1,10 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"time"
 
 	"crowdreview/config"
@@ -9,48 +13,134 @@ import (
 	"github.com/google/uuid"
 )
 
-// TokenClaims extends registered claims with a role.
+// ChallengeTTL bounds the time between the password step and the second
+// factor of a two-step login.
+const ChallengeTTL = 5 * time.Minute
+
+// Token times carry milliseconds so that a user-wide revocation also covers
+// tokens issued earlier within the same second.
+func init() {
+	jwt.TimePrecision = time.Millisecond
+}
+
+// TokenClaims extends registered claims with a role, the role's effective
+// admin permissions and whether the login passed a second factor.
 type TokenClaims struct {
-	Role string `json:"role"`
+	Role        string   `json:"role"`
+	Permissions []string `json:"perms,omitempty"`
+	MFA         bool     `json:"mfa,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenerateTokens issues access and refresh JWTs.
-func GenerateTokens(userID uuid.UUID, role string, cfg config.Config) (string, string, error) {
+// HasPermission reports whether the token grants perm.
+func (c *TokenClaims) HasPermission(perm string) bool {
+	for _, p := range c.Permissions {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateAccessToken issues a short-lived access JWT with a unique ID (jti)
+// so it can be revoked individually. Its audience is cfg.JWTAudience, which
+// other services verify against the published JWKS.
+func GenerateAccessToken(userID uuid.UUID, role string, perms []string, mfa bool, keys *KeySet, cfg config.Config) (string, error) {
 	now := time.Now()
 	claims := TokenClaims{
-		Role: role,
+		Role:        role,
+		Permissions: perms,
+		MFA:         mfa,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    cfg.JWTIssuer,
+			Audience:  jwt.ClaimStrings{cfg.JWTAudience},
 			Subject:   userID.String(),
 			ExpiresAt: jwt.NewNumericDate(now.Add(cfg.TokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
-	refreshClaims := jwt.RegisteredClaims{
-		Subject:   userID.String(),
-		ExpiresAt: jwt.NewNumericDate(now.Add(cfg.RefreshTTL)),
-		IssuedAt:  jwt.NewNumericDate(now),
+	return signToken(claims, keys, now)
+}
+
+// GenerateRefreshToken issues a refresh JWT whose ID is the server-side
+// token record ID, returning the token and its expiry. Refresh tokens are
+// addressed to the issuer itself so they are never accepted as access tokens.
+func GenerateRefreshToken(userID, tokenID uuid.UUID, keys *KeySet, cfg config.Config) (string, time.Time, error) {
+	now := time.Now()
+	expires := now.Add(cfg.RefreshTTL)
+	claims := TokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID.String(),
+			Issuer:    cfg.JWTIssuer,
+			Audience:  jwt.ClaimStrings{cfg.JWTIssuer},
+			Subject:   userID.String(),
+			ExpiresAt: jwt.NewNumericDate(expires),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
 	}
+	token, err := signToken(claims, keys, now)
+	return token, expires, err
+}
 
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
+// ChallengeAudience is the audience of two-step login challenge tokens.
+func ChallengeAudience(cfg config.Config) string {
+	return cfg.JWTIssuer + "/2fa"
+}
 
-	access, err := accessToken.SignedString([]byte(cfg.JWTSecret))
-	if err != nil {
-		return "", "", err
+// GenerateChallengeToken issues the token that proves the password step of
+// a two-step login. It is only accepted by the second-factor endpoint.
+func GenerateChallengeToken(userID uuid.UUID, keys *KeySet, cfg config.Config) (string, error) {
+	now := time.Now()
+	claims := TokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    cfg.JWTIssuer,
+			Audience:  jwt.ClaimStrings{ChallengeAudience(cfg)},
+			Subject:   userID.String(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ChallengeTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
 	}
-	refresh, err := refreshToken.SignedString([]byte(cfg.RefreshSecret))
-	if err != nil {
-		return "", "", err
+	return signToken(claims, keys, now)
+}
+
+func signToken(claims TokenClaims, keys *KeySet, now time.Time) (string, error) {
+	key, ok := keys.Signing(now)
+	if !ok {
+		return "", errors.New("no active signing key")
 	}
-	return access, refresh, nil
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
+}
+
+// HashToken returns the hex SHA-256 of a token, the form in which refresh
+// tokens are stored.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
-// ParseToken validates a JWT with the provided secret.
-func ParseToken(tokenString, secret string) (*TokenClaims, error) {
+// ParseToken validates a JWT against the key named by its kid header and
+// checks the issuer and audience.
+func ParseToken(tokenString string, keys *KeySet, issuer, audience string) (*TokenClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
-	})
+		kid, _ := token.Header["kid"].(string)
+		key, ok := keys.Lookup(kid, time.Now())
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		if token.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+		}
+		return key.Public(), nil
+	},
+		jwt.WithValidMethods([]string{AlgRS256, AlgEdDSA}),
+		jwt.WithIssuer(issuer),
+		jwt.WithAudience(audience),
+		jwt.WithExpirationRequired(),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"crowdreview/config"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func testKey(t *testing.T, alg string, activates time.Time) SigningKey {
+	t.Helper()
+	key, err := GenerateSigningKey(alg)
+	require.NoError(t, err)
+	key.ActivatesAt = activates
+	key.ExpiresAt = activates.Add(24 * time.Hour)
+	return key
+}
+
+func TestTokensSelectKeyByKid(t *testing.T) {
+	cfg := config.Config{JWTIssuer: "crowdreview", JWTAudience: "crowdreview-api", TokenTTL: time.Minute, RefreshTTL: time.Hour}
+	now := time.Now()
+	old := testKey(t, AlgRS256, now.Add(-2*time.Hour))
+	keys := NewKeySet(old)
+
+	userID := uuid.New()
+	oldToken, err := GenerateAccessToken(userID, "user", nil, false, keys, cfg)
+	require.NoError(t, err)
+
+	// After rotation tokens signed by the previous key still verify.
+	current := testKey(t, AlgEdDSA, now.Add(-time.Hour))
+	pending := testKey(t, AlgEdDSA, now.Add(time.Hour))
+	keys.Replace([]SigningKey{old, current, pending})
+	signing, ok := keys.Signing(now)
+	require.True(t, ok)
+	require.Equal(t, current.ID, signing.ID)
+
+	claims, err := ParseToken(oldToken, keys, cfg.JWTIssuer, cfg.JWTAudience)
+	require.NoError(t, err)
+	require.Equal(t, userID.String(), claims.Subject)
+
+	newToken, err := GenerateAccessToken(userID, "user", nil, false, keys, cfg)
+	require.NoError(t, err)
+	_, err = ParseToken(newToken, keys, cfg.JWTIssuer, cfg.JWTAudience)
+	require.NoError(t, err)
+
+	_, err = ParseToken(newToken, keys, "someone-else", cfg.JWTAudience)
+	require.Error(t, err)
+	_, err = ParseToken(newToken, NewKeySet(old), cfg.JWTIssuer, cfg.JWTAudience)
+	require.Error(t, err)
+
+	// Refresh tokens are not valid access tokens.
+	refresh, _, err := GenerateRefreshToken(userID, uuid.New(), keys, cfg)
+	require.NoError(t, err)
+	_, err = ParseToken(refresh, keys, cfg.JWTIssuer, cfg.JWTAudience)
+	require.Error(t, err)
+	_, err = ParseToken(refresh, keys, cfg.JWTIssuer, cfg.JWTIssuer)
+	require.NoError(t, err)
+
+	jwks := keys.JWKS(now)
+	require.Len(t, jwks.Keys, 3)
+	require.Equal(t, "RSA", jwks.Keys[2].KeyType)
+	require.Equal(t, "OKP", jwks.Keys[0].KeyType)
+}
+
+func TestPrivateKeyPEMRoundTrip(t *testing.T) {
+	for _, alg := range []string{AlgRS256, AlgEdDSA} {
+		key := testKey(t, alg, time.Now())
+		encoded, err := EncodePrivateKey(key.Private)
+		require.NoError(t, err)
+		decoded, err := DecodePrivateKey(encoded)
+		require.NoError(t, err)
+		require.Equal(t, key.Public(), decoded.Public())
+	}
+}
@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Supported JWT signing algorithms.
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+const rsaKeyBits = 2048
+
+// SigningKey is a JWT key pair identified by its kid. A key signs tokens
+// from ActivatesAt until a newer key activates, and verifies them until
+// ExpiresAt.
+type SigningKey struct {
+	ID          string
+	Algorithm   string
+	Private     crypto.Signer
+	ActivatesAt time.Time
+	ExpiresAt   time.Time
+}
+
+// Public returns the verification half of the key.
+func (k SigningKey) Public() crypto.PublicKey {
+	return k.Private.Public()
+}
+
+// GenerateSigningKey creates a fresh key pair for alg with a random kid.
+func GenerateSigningKey(alg string) (SigningKey, error) {
+	var private crypto.Signer
+	var err error
+	switch alg {
+	case AlgRS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return SigningKey{}, fmt.Errorf("unsupported signing algorithm %q", alg)
+	}
+	if err != nil {
+		return SigningKey{}, err
+	}
+	return SigningKey{ID: uuid.NewString(), Algorithm: alg, Private: private}, nil
+}
+
+// EncodePrivateKey serializes a private key as PKCS#8 PEM.
+func EncodePrivateKey(key crypto.Signer) (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// DecodePrivateKey parses a PKCS#8 PEM private key.
+func DecodePrivateKey(data string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("invalid private key PEM")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("private key cannot sign")
+	}
+	return signer, nil
+}
+
+// KeySet holds the keys in use. It is safe for concurrent use and is
+// swapped wholesale when keys are reloaded or rotated.
+type KeySet struct {
+	mu   sync.RWMutex
+	keys []SigningKey
+}
+
+func NewKeySet(keys ...SigningKey) *KeySet {
+	set := &KeySet{}
+	set.Replace(keys)
+	return set
+}
+
+// Replace swaps the set's keys, newest activation first.
+func (s *KeySet) Replace(keys []SigningKey) {
+	sorted := append([]SigningKey(nil), keys...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ActivatesAt.After(sorted[j].ActivatesAt) })
+	s.mu.Lock()
+	s.keys = sorted
+	s.mu.Unlock()
+}
+
+// Signing returns the most recently activated key that has not expired.
+func (s *KeySet) Signing(now time.Time) (SigningKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, k := range s.keys {
+		if !k.ActivatesAt.After(now) && k.ExpiresAt.After(now) {
+			return k, true
+		}
+	}
+	return SigningKey{}, false
+}
+
+// Lookup finds an unexpired key by kid, including keys not yet active.
+func (s *KeySet) Lookup(kid string, now time.Time) (SigningKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, k := range s.keys {
+		if k.ID == kid && k.ExpiresAt.After(now) {
+			return k, true
+		}
+	}
+	return SigningKey{}, false
+}
+
+// JWK is a public key in RFC 7517 form.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKS is the document served at /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS publishes every unexpired key, including keys scheduled to activate,
+// so verifiers learn new keys before tokens signed with them appear.
+func (s *KeySet) JWKS(now time.Time) JWKS {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := JWKS{Keys: make([]JWK, 0, len(s.keys))}
+	for _, k := range s.keys {
+		if !k.ExpiresAt.After(now) {
+			continue
+		}
+		jwk := JWK{Use: "sig", KeyID: k.ID, Algorithm: k.Algorithm}
+		switch pub := k.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		out.Keys = append(out.Keys, jwk)
+	}
+	return out
+}
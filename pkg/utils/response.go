@@ -7,6 +7,12 @@ func JSONError(c *gin.Context, code int, msg string) {
 	c.JSON(code, gin.H{"error": msg})
 }
 
+// JSONErrorData sends an error payload with data the client can act on,
+// such as the conflicting records of a 409.
+func JSONErrorData(c *gin.Context, code int, msg string, data interface{}) {
+	c.JSON(code, gin.H{"error": msg, "data": data})
+}
+
 // JSONSuccess sends a standardized success payload.
 func JSONSuccess(c *gin.Context, code int, data interface{}) {
 	c.JSON(code, gin.H{"data": data})
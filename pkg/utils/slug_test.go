@@ -0,0 +1,17 @@
+package utils
+
+import "testing"
+
+func TestSlugify(t *testing.T) {
+	cases := map[string]string{
+		"Serviços Financeiros": "servicos-financeiros",
+		"  São Paulo  ":        "sao-paulo",
+		"E-commerce & Varejo":  "e-commerce-varejo",
+		"":                     "",
+	}
+	for in, want := range cases {
+		if got := Slugify(in); got != want {
+			t.Errorf("Slugify(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOTPCodeRFC6238Vectors(t *testing.T) {
+	// SHA1 test vectors from RFC 6238 Appendix B, truncated to six digits.
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	cases := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, want := range cases {
+		code, err := TOTPCode(secret, TOTPStep(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		require.Equal(t, want, code, "t=%d", unix)
+	}
+}
+
+func TestMatchTOTPAcceptsAdjacentSteps(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	require.NoError(t, err)
+	now := time.Now()
+	previous, err := TOTPCode(secret, TOTPStep(now)-1)
+	require.NoError(t, err)
+
+	step, ok := MatchTOTP(secret, previous, now)
+	require.True(t, ok)
+	require.Equal(t, TOTPStep(now)-1, step)
+
+	stale, err := TOTPCode(secret, TOTPStep(now)-3)
+	require.NoError(t, err)
+	_, ok = MatchTOTP(secret, stale, now)
+	require.False(t, ok)
+	_, ok = MatchTOTP(secret, "12345", now)
+	require.False(t, ok)
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("CrowdReview", "a@b.com", "JBSWY3DPEHPK3PXP")
+	require.Equal(t, "otpauth://totp/CrowdReview:a@b.com?algorithm=SHA1&digits=6&issuer=CrowdReview&period=30&secret=JBSWY3DPEHPK3PXP", uri)
+}